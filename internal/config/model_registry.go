@@ -0,0 +1,95 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"socket_inference/internal/model"
+)
+
+// modelRegistryFile 推論モデル一覧ファイルの形式
+type modelRegistryFile struct {
+	Models []modelFileEntry `json:"models"`
+}
+
+// modelFileEntry 推論モデル一覧ファイルの1モデル
+// 前処理設定・推論サーバーとの通信設定は記載されていない項目にデフォルト値を使用するため、読み込み後に解析する
+type modelFileEntry struct {
+	model.ModelSpec
+	Preprocessing json.RawMessage `json:"preprocessing,omitempty"`
+	HTTP          json.RawMessage `json:"http,omitempty"`
+	WebSocket     json.RawMessage `json:"websocket,omitempty"`
+}
+
+// LoadModelRegistry JSONファイルから推論モデルの登録設定の一覧を読み込み
+// 各モデルの前処理設定・推論サーバーとの通信設定に記載されていない項目はデフォルト値を使用する
+func LoadModelRegistry(path string) ([]model.ModelSpec, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("推論モデル一覧ファイルを開けません: %w", err)
+	}
+	defer file.Close()
+
+	var registry modelRegistryFile
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&registry); err != nil {
+		return nil, fmt.Errorf("推論モデル一覧ファイル %s を解析できません: %w", path, err)
+	}
+	if len(registry.Models) == 0 {
+		return nil, fmt.Errorf("推論モデル一覧ファイルにモデルがありません: %s", path)
+	}
+
+	specs := make([]model.ModelSpec, 0, len(registry.Models))
+	for _, entry := range registry.Models {
+		spec := entry.ModelSpec
+		if len(entry.Preprocessing) > 0 {
+			preprocessing := model.DefaultPreprocessingConfig()
+			decoder := json.NewDecoder(bytes.NewReader(entry.Preprocessing))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&preprocessing); err != nil {
+				return nil, fmt.Errorf("推論モデル %s の前処理設定を解析できません: %w", spec.ID(), err)
+			}
+			spec.Preprocessing = &preprocessing
+		}
+		if len(entry.HTTP) > 0 {
+			httpBackend := model.DefaultHTTPBackendConfig()
+			decoder := json.NewDecoder(bytes.NewReader(entry.HTTP))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&httpBackend); err != nil {
+				return nil, fmt.Errorf("推論モデル %s のHTTP推論サーバーとの通信設定を解析できません: %w", spec.ID(), err)
+			}
+			spec.HTTP = &httpBackend
+		}
+		if len(entry.WebSocket) > 0 {
+			wsBackend := model.DefaultWebSocketBackendConfig()
+			decoder := json.NewDecoder(bytes.NewReader(entry.WebSocket))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&wsBackend); err != nil {
+				return nil, fmt.Errorf("推論モデル %s のWebSocket推論サーバーとの通信設定を解析できません: %w", spec.ID(), err)
+			}
+			spec.WebSocket = &wsBackend
+		}
+		if err := spec.Validate(); err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+
+	// カナリア・シャドーの振り分け先は同じ名前で登録されていること
+	registered := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		registered[spec.ID()] = true
+	}
+	for _, spec := range specs {
+		if spec.Canary != nil && !registered[spec.Name+"@"+spec.Canary.Version] {
+			return nil, fmt.Errorf("%w: %s の canary %s@%s が登録されていません", model.ErrInvalidModelSpec, spec.ID(), spec.Name, spec.Canary.Version)
+		}
+		if spec.Shadow != nil && !registered[spec.Name+"@"+spec.Shadow.Version] {
+			return nil, fmt.Errorf("%w: %s の shadow %s@%s が登録されていません", model.ErrInvalidModelSpec, spec.ID(), spec.Name, spec.Shadow.Version)
+		}
+	}
+	return specs, nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"socket_inference/internal/model"
+)
+
+// LoadPreprocessingConfig JSONファイルから前処理パイプライン設定を読み込み
+// ファイルに記載されていない項目はデフォルト値を使用する
+func LoadPreprocessingConfig(path string) (model.PreprocessingConfig, error) {
+	config := model.DefaultPreprocessingConfig()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return config, fmt.Errorf("前処理設定ファイルを開けません: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, fmt.Errorf("前処理設定ファイル %s を解析できません: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return config, err
+	}
+	return config, nil
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"socket_inference/internal/model"
 )
 
 // ServerConfig サーバー設定
@@ -13,12 +16,104 @@ type ServerConfig struct {
 	FlushTimeout time.Duration // バッチフラッシュタイムアウト
 	MaxClients   int           // 最大同時接続クライアント数
 	BufferSize   int           // チャネルバッファサイズ
-	GRPCServer   string        // gRPCサーバーアドレス
+	GRPCServer   string        // gRPCサーバーアドレス（カンマ区切りで複数指定可）
 	GRPCTimeout  time.Duration // gRPCタイムアウト
+
+	// 推論ワーカープール設定
+	InferenceWorkers     int // 並行してバッチを処理するワーカー数
+	InferenceMaxInFlight int // 推論サーバーへの同時リクエスト数の上限
+	InferenceQueueSize   int // 推論待ちのバッチ数の上限
+
+	// 推論リクエストのリトライ設定
+	RetryMaxAttempts    int           // 初回を含む最大試行回数（1でリトライなし）
+	RetryInitialBackoff time.Duration // 初回リトライまでの待機時間
+	RetryMaxBackoff     time.Duration // 待機時間の上限
+	RetryMultiplier     float64       // リトライ毎の待機時間の倍率
+	RetryJitter         float64       // 待機時間を揺らす割合（0〜1）
+	RetryCodes          []string      // リトライ対象のステータスコード
+	RetryDeadline       time.Duration // 初回の失敗以降のリトライの合計時間の上限
+
+	// 推論サーバーの負荷分散設定
+	GRPCServerFile         string        // 推論サーバーのアドレス一覧ファイル（設定時はGRPCServerより優先し、変更を監視）
+	GRPCServerFileInterval time.Duration // アドレス一覧ファイルの変更確認間隔
+	LBPolicy               string        // レプリカの選び方（round_robin / least_outstanding / consistent_hash）
+	HealthCheckInterval    time.Duration // レプリカのヘルスチェック間隔
+	EjectionThreshold      int           // レプリカを振り分け対象から外すまでの連続失敗回数
+	EjectionDuration       time.Duration // 連続失敗で外したレプリカを戻すまでの最短時間
+
+	// 推論リクエストのヘッジ設定
+	HedgingEnabled    bool          // 応答が遅いリクエストの複製を別のレプリカに送るか
+	HedgePercentile   float64       // 複製を送るまでの待機時間に使うレイテンシのパーセンタイル
+	HedgeInitialDelay time.Duration // レイテンシの計測数が足りない間の待機時間
+	HedgeMinDelay     time.Duration // 待機時間の下限
+	HedgeMaxRatio     float64       // リクエスト数に対する複製送信の割合の上限
+
+	// 推論サーバーのサーキットブレーカー設定
+	BreakerFailureThreshold int           // 開くまでの連続失敗回数
+	BreakerOpenDuration     time.Duration // 開いてから試行リクエストを許可するまでの時間
+	BreakerHalfOpenProbes   int           // 半開状態で同時に許可する試行リクエスト数
+	BreakerSuccessThreshold int           // 閉じるまでに必要な試行リクエストの成功回数
+
+	// HTTPサーバー設定
+	PathPrefix        string        // ルートのパスプレフィックス（例: /api）
+	ReadHeaderTimeout time.Duration // リクエストヘッダー読み取りタイムアウト
+	ReadTimeout       time.Duration // リクエスト全体の読み取りタイムアウト
+	IdleTimeout       time.Duration // Keep-Aliveアイドルタイムアウト
+	MaxHeaderBytes    int           // リクエストヘッダーの最大サイズ
+	ShutdownTimeout   time.Duration // グレースフルシャットダウンの待機時間
+	GRPCIngressPort   string        // 音声ストリーミング用gRPCサーバーのポート（空の場合は無効）
+
+	// ファイル推論設定
+	RecognizeChunkBytes int           // アップロード音声をパイプラインに流すチャンクのバイト数
+	RecognizeMaxBytes   int64         // アップロードを受け付ける最大バイト数
+	RecognizeTimeout    time.Duration // 同期ファイル推論のタイムアウト
+
+	RecognizeJobWorkers   int           // 同時に実行する非同期ファイル推論ジョブ数
+	RecognizeJobQueueSize int           // 実行を待てる非同期ファイル推論ジョブ数の上限
+	RecognizeJobTimeout   time.Duration // 非同期ファイル推論ジョブ毎のタイムアウト
+	RecognizeJobRetention time.Duration // 完了した非同期ファイル推論ジョブの保持期間
+
+	// 推論モデル設定
+	ModelRegistryFile     string // 推論モデル一覧のJSONファイル（空の場合は InferenceModel のみ）
+	InferenceModel        string // 推論モデル一覧ファイルがない場合の推論モデルの名前
+	InferenceModelVersion string // 推論モデル一覧ファイルがない場合の推論モデルのバージョン
+
+	// 推論サーバーとの通信方式（推論モデル一覧ファイルがない場合）
+	InferenceBackend                  string // grpc / http / websocket（http・websocket の場合 GRPC_SERVER は推論サーバーのURL）
+	InferenceHTTPEncoding             string // HTTP推論サーバーへのバッチの送り方（json / multipart）
+	InferenceHTTPResponseTemplateFile string // HTTP推論サーバーのレスポンスを変換するテンプレートのファイル
+	InferenceHTTPHealthPath           string // HTTP推論サーバーのヘルスチェックのパス
+	InferenceWSMaxConnections         int    // WebSocket推論サーバー毎の接続プールの最大接続数
+	InferenceWSReconnects             int    // WebSocket推論サーバーとの接続が切れた場合に再送する回数
+
+	// 前処理設定
+	PreprocessingConfigFile string // 前処理パイプライン設定のJSONファイル（空の場合はデフォルト設定）
+	VADBatching             bool   // 発話の終端でバッチを区切るか（無効の場合はチャンク数で区切る）
+	VADMaxBatchChunks       int    // 発話単位のバッチ化で終端が来ない場合の最大チャンク数
+
+	// TLS終端設定
+	TLSCertFile          string        // サーバー証明書ファイル（設定時はTLSで待ち受け）
+	TLSKeyFile           string        // サーバー秘密鍵ファイル
+	TLSClientCAFile      string        // クライアント証明書検証用CAバンドル
+	TLSRequireClientCert bool          // クライアント証明書を必須にするか（mTLS）
+	TLSReloadInterval    time.Duration // 証明書ファイルの変更確認間隔
+
+	// gRPC推論クライアントのTLS設定
+	GRPCTLSEnabled    bool   // 推論サーバーとの通信をTLSで暗号化するか
+	GRPCTLSCAFile     string // 推論サーバー証明書検証用CAバンドル
+	GRPCTLSCertFile   string // クライアント証明書ファイル（mTLS）
+	GRPCTLSKeyFile    string // クライアント秘密鍵ファイル（mTLS）
+	GRPCTLSServerName string // 証明書検証に用いるサーバー名
 }
 
 // LoadServerConfig 環境変数からサーバー設定を読み込み
 func LoadServerConfig() *ServerConfig {
+	retry := model.DefaultRetryPolicy()
+	breaker := model.DefaultCircuitBreakerConfig()
+	balancing := model.DefaultLoadBalancingConfig()
+	hedging := model.DefaultHedgingConfig()
+	httpBackend := model.DefaultHTTPBackendConfig()
+	wsBackend := model.DefaultWebSocketBackendConfig()
 	return &ServerConfig{
 		Port:         getEnv("SERVER_PORT", "8080"),
 		BatchSize:    getEnvInt("BATCH_SIZE", 10),
@@ -27,6 +122,166 @@ func LoadServerConfig() *ServerConfig {
 		BufferSize:   getEnvInt("BUFFER_SIZE", 100),
 		GRPCServer:   getEnv("GRPC_SERVER", "localhost:50051"),
 		GRPCTimeout:  getEnvDuration("GRPC_TIMEOUT", "30s"),
+
+		InferenceWorkers:     getEnvInt("INFERENCE_WORKERS", 4),
+		InferenceMaxInFlight: getEnvInt("INFERENCE_MAX_IN_FLIGHT", 8),
+		InferenceQueueSize:   getEnvInt("INFERENCE_QUEUE_SIZE", 256),
+
+		RetryMaxAttempts:    getEnvInt("INFERENCE_RETRY_MAX_ATTEMPTS", retry.MaxAttempts),
+		RetryInitialBackoff: getEnvDuration("INFERENCE_RETRY_INITIAL_BACKOFF", retry.InitialBackoff.String()),
+		RetryMaxBackoff:     getEnvDuration("INFERENCE_RETRY_MAX_BACKOFF", retry.MaxBackoff.String()),
+		RetryMultiplier:     getEnvFloat("INFERENCE_RETRY_MULTIPLIER", retry.BackoffMultiplier),
+		RetryJitter:         getEnvFloat("INFERENCE_RETRY_JITTER", retry.Jitter),
+		RetryCodes:          getEnvList("INFERENCE_RETRY_CODES", retry.RetryableCodes),
+		RetryDeadline:       getEnvDuration("INFERENCE_RETRY_DEADLINE", retry.Deadline.String()),
+
+		GRPCServerFile:         getEnv("GRPC_SERVER_FILE", ""),
+		GRPCServerFileInterval: getEnvDuration("GRPC_SERVER_FILE_INTERVAL", "10s"),
+		LBPolicy:               getEnv("INFERENCE_LB_POLICY", string(balancing.Policy)),
+		HealthCheckInterval:    getEnvDuration("INFERENCE_HEALTH_CHECK_INTERVAL", balancing.HealthCheckInterval.String()),
+		EjectionThreshold:      getEnvInt("INFERENCE_EJECTION_THRESHOLD", balancing.EjectionThreshold),
+		EjectionDuration:       getEnvDuration("INFERENCE_EJECTION_DURATION", balancing.EjectionDuration.String()),
+
+		HedgingEnabled:    getEnvBool("INFERENCE_HEDGING", hedging.Enabled),
+		HedgePercentile:   getEnvFloat("INFERENCE_HEDGE_PERCENTILE", hedging.Percentile),
+		HedgeInitialDelay: getEnvDuration("INFERENCE_HEDGE_INITIAL_DELAY", hedging.InitialDelay.String()),
+		HedgeMinDelay:     getEnvDuration("INFERENCE_HEDGE_MIN_DELAY", hedging.MinDelay.String()),
+		HedgeMaxRatio:     getEnvFloat("INFERENCE_HEDGE_MAX_RATIO", hedging.MaxHedgeRatio),
+
+		BreakerFailureThreshold: getEnvInt("INFERENCE_BREAKER_FAILURE_THRESHOLD", breaker.FailureThreshold),
+		BreakerOpenDuration:     getEnvDuration("INFERENCE_BREAKER_OPEN_DURATION", breaker.OpenDuration.String()),
+		BreakerHalfOpenProbes:   getEnvInt("INFERENCE_BREAKER_HALF_OPEN_PROBES", breaker.HalfOpenProbes),
+		BreakerSuccessThreshold: getEnvInt("INFERENCE_BREAKER_SUCCESS_THRESHOLD", breaker.SuccessThreshold),
+
+		PathPrefix:        getEnv("PATH_PREFIX", ""),
+		ReadHeaderTimeout: getEnvDuration("READ_HEADER_TIMEOUT", "10s"),
+		ReadTimeout:       getEnvDuration("READ_TIMEOUT", "60s"),
+		IdleTimeout:       getEnvDuration("IDLE_TIMEOUT", "120s"),
+		MaxHeaderBytes:    getEnvInt("MAX_HEADER_BYTES", 1<<20),
+		ShutdownTimeout:   getEnvDuration("SHUTDOWN_TIMEOUT", "10s"),
+		GRPCIngressPort:   getEnv("GRPC_INGRESS_PORT", ""),
+
+		RecognizeChunkBytes: getEnvInt("RECOGNIZE_CHUNK_BYTES", 3200),
+		RecognizeMaxBytes:   int64(getEnvInt("RECOGNIZE_MAX_BYTES", 50<<20)),
+		RecognizeTimeout:    getEnvDuration("RECOGNIZE_TIMEOUT", "5m"),
+
+		RecognizeJobWorkers:   getEnvInt("RECOGNIZE_JOB_WORKERS", 4),
+		RecognizeJobQueueSize: getEnvInt("RECOGNIZE_JOB_QUEUE_SIZE", 64),
+		RecognizeJobTimeout:   getEnvDuration("RECOGNIZE_JOB_TIMEOUT", "5m"),
+		RecognizeJobRetention: getEnvDuration("RECOGNIZE_JOB_RETENTION", "1h"),
+
+		ModelRegistryFile:     getEnv("MODEL_REGISTRY_FILE", ""),
+		InferenceModel:        getEnv("INFERENCE_MODEL", "default"),
+		InferenceModelVersion: getEnv("INFERENCE_MODEL_VERSION", "1"),
+
+		InferenceBackend:                  getEnv("INFERENCE_BACKEND", string(model.BackendGRPC)),
+		InferenceHTTPEncoding:             getEnv("INFERENCE_HTTP_ENCODING", string(httpBackend.Encoding)),
+		InferenceHTTPResponseTemplateFile: getEnv("INFERENCE_HTTP_RESPONSE_TEMPLATE_FILE", ""),
+		InferenceHTTPHealthPath:           getEnv("INFERENCE_HTTP_HEALTH_PATH", ""),
+		InferenceWSMaxConnections:         getEnvInt("INFERENCE_WS_MAX_CONNECTIONS", wsBackend.MaxConnections),
+		InferenceWSReconnects:             getEnvInt("INFERENCE_WS_RECONNECTS", wsBackend.Reconnects),
+
+		PreprocessingConfigFile: getEnv("PREPROCESSING_CONFIG_FILE", ""),
+		VADBatching:             getEnvBool("VAD_BATCHING", false),
+		VADMaxBatchChunks:       getEnvInt("VAD_MAX_BATCH_CHUNKS", 50),
+
+		TLSCertFile:          getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:           getEnv("TLS_KEY_FILE", ""),
+		TLSClientCAFile:      getEnv("TLS_CLIENT_CA_FILE", ""),
+		TLSRequireClientCert: getEnvBool("TLS_REQUIRE_CLIENT_CERT", false),
+		TLSReloadInterval:    getEnvDuration("TLS_RELOAD_INTERVAL", "30s"),
+
+		GRPCTLSEnabled:    getEnvBool("GRPC_TLS_ENABLED", false),
+		GRPCTLSCAFile:     getEnv("GRPC_TLS_CA_FILE", ""),
+		GRPCTLSCertFile:   getEnv("GRPC_TLS_CERT_FILE", ""),
+		GRPCTLSKeyFile:    getEnv("GRPC_TLS_KEY_FILE", ""),
+		GRPCTLSServerName: getEnv("GRPC_TLS_SERVER_NAME", ""),
+	}
+}
+
+// GetServerAddress 推論サーバーのアドレスを取得
+func (c *ServerConfig) GetServerAddress() string {
+	return c.GRPCServer
+}
+
+// GetTimeout 推論サーバーとの通信のタイムアウト（秒）を取得
+func (c *ServerConfig) GetTimeout() int {
+	return int(c.GRPCTimeout / time.Second)
+}
+
+// GetRetryPolicy 推論リクエストのリトライポリシーを取得
+func (c *ServerConfig) GetRetryPolicy() model.RetryPolicy {
+	return model.RetryPolicy{
+		MaxAttempts:       c.RetryMaxAttempts,
+		InitialBackoff:    c.RetryInitialBackoff,
+		MaxBackoff:        c.RetryMaxBackoff,
+		BackoffMultiplier: c.RetryMultiplier,
+		Jitter:            c.RetryJitter,
+		RetryableCodes:    c.RetryCodes,
+		Deadline:          c.RetryDeadline,
+	}
+}
+
+// InferenceEndpoints GRPCServerに指定された推論サーバーのアドレス一覧を取得
+func (c *ServerConfig) InferenceEndpoints() []string {
+	return getList(c.GRPCServer)
+}
+
+// DefaultModelSpec 推論モデル一覧ファイルがない場合の推論モデルの登録設定を取得
+// 推論サーバーは InferenceEndpoints（または GRPCServerFile）を使用する
+func (c *ServerConfig) DefaultModelSpec() model.ModelSpec {
+	spec := model.ModelSpec{
+		ModelInfo: model.ModelInfo{
+			Name:    c.InferenceModel,
+			Version: c.InferenceModelVersion,
+			Default: true,
+		},
+		Backend: model.InferenceBackend(c.InferenceBackend),
+	}
+	switch spec.Backend {
+	case model.BackendHTTP:
+		httpBackend := model.DefaultHTTPBackendConfig()
+		httpBackend.Encoding = model.HTTPRequestEncoding(c.InferenceHTTPEncoding)
+		httpBackend.ResponseTemplateFile = c.InferenceHTTPResponseTemplateFile
+		httpBackend.HealthPath = c.InferenceHTTPHealthPath
+		spec.HTTP = &httpBackend
+	case model.BackendWebSocket:
+		wsBackend := model.DefaultWebSocketBackendConfig()
+		wsBackend.MaxConnections = c.InferenceWSMaxConnections
+		wsBackend.Reconnects = c.InferenceWSReconnects
+		spec.WebSocket = &wsBackend
+	}
+	return spec
+}
+
+// LoadBalancingConfig 推論サーバーの負荷分散設定を取得
+func (c *ServerConfig) LoadBalancingConfig() model.LoadBalancingConfig {
+	return model.LoadBalancingConfig{
+		Policy:              model.LoadBalancingPolicy(c.LBPolicy),
+		HealthCheckInterval: c.HealthCheckInterval,
+		EjectionThreshold:   c.EjectionThreshold,
+		EjectionDuration:    c.EjectionDuration,
+	}
+}
+
+// HedgingConfig 推論リクエストのヘッジ設定を取得
+func (c *ServerConfig) HedgingConfig() model.HedgingConfig {
+	config := model.DefaultHedgingConfig()
+	config.Enabled = c.HedgingEnabled
+	config.Percentile = c.HedgePercentile
+	config.InitialDelay = c.HedgeInitialDelay
+	config.MinDelay = c.HedgeMinDelay
+	config.MaxHedgeRatio = c.HedgeMaxRatio
+	return config
+}
+
+// CircuitBreakerConfig 推論サーバーのサーキットブレーカー設定を取得
+func (c *ServerConfig) CircuitBreakerConfig() model.CircuitBreakerConfig {
+	return model.CircuitBreakerConfig{
+		FailureThreshold: c.BreakerFailureThreshold,
+		OpenDuration:     c.BreakerOpenDuration,
+		HalfOpenProbes:   c.BreakerHalfOpenProbes,
+		SuccessThreshold: c.BreakerSuccessThreshold,
 	}
 }
 
@@ -58,3 +313,40 @@ func getEnvDuration(key string, defaultValue string) time.Duration {
 	duration, _ := time.ParseDuration(defaultValue)
 	return duration
 }
+
+// getEnvBool 環境変数からbool値取得
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		return value == "true" || value == "1" || value == "yes"
+	}
+	return defaultValue
+}
+
+// getEnvFloat 環境変数から浮動小数点数取得
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList 環境変数からカンマ区切りのリスト取得
+func getEnvList(key string, defaultValue []string) []string {
+	if list := getList(os.Getenv(key)); len(list) > 0 {
+		return list
+	}
+	return defaultValue
+}
+
+// getList カンマ区切りの文字列を空白を除いたリストに分割
+func getList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -2,20 +2,29 @@ package grpc
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"socket_inference/internal/infrastructure/interfaces"
 	"socket_inference/internal/model"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // InferenceClient gRPC推論クライアントの実装
 type InferenceClient struct {
 	serverAddress string
 	timeout       time.Duration
-	connected     bool
-	// TODO: gRPC接続オブジェクトを追加
-	// conn   *grpc.ClientConn
+	tlsOptions    TLSOptions
+
+	mu        sync.Mutex
+	conn      *grpc.ClientConn // Connect時にtlsOptionsのトランスポート認証情報で作成
+	connected bool
+	// TODO: 推論サービスのクライアントを追加
 	// client pb.InferenceServiceClient
 }
 
@@ -24,13 +33,21 @@ func NewInferenceClient(serverAddress string, timeout time.Duration) interfaces.
 	return &InferenceClient{
 		serverAddress: serverAddress,
 		timeout:       timeout,
-		connected:     false,
+	}
+}
+
+// NewSecureInferenceClient TLSで推論サーバーと通信するgRPC推論クライアントを作成
+func NewSecureInferenceClient(serverAddress string, timeout time.Duration, tlsOptions TLSOptions) interfaces.InferenceClient {
+	return &InferenceClient{
+		serverAddress: serverAddress,
+		timeout:       timeout,
+		tlsOptions:    tlsOptions,
 	}
 }
 
 // SendInferenceRequest 推論リクエストをサーバーに送信
 func (ic *InferenceClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
-	log.Printf("gRPC推論リクエスト送信: クライアント=%s", request.ClientID)
+	log.Printf("gRPC推論リクエスト送信: クライアント=%s, タスク=%s", request.ClientID, request.Task)
 
 	// TODO: 実際のgRPC通信を実装
 	// ctx, cancel := context.WithTimeout(ctx, ic.timeout)
@@ -39,6 +56,14 @@ func (ic *InferenceClient) SendInferenceRequest(ctx context.Context, request *mo
 	// req := &pb.InferenceRequest{
 	//     ClientId: request.ClientID,
 	//     AudioData: request.AudioData,
+	//     Features:  toPBTensor(request.Features), // Shapeとデータを送信
+	//     Format:     toPBFormat(request.Format),
+	//     Task:       string(request.Task),
+	//     Keywords:   request.Keywords,
+	//     Sequence:   request.Sequence,
+	//     OffsetMs:   request.OffsetMs,
+	//     DurationMs: request.DurationMs,
+	//     IdempotencyKey: request.IdempotencyKey,
 	//     Timestamp: request.Timestamp.Unix(),
 	// }
 	//
@@ -47,12 +72,30 @@ func (ic *InferenceClient) SendInferenceRequest(ctx context.Context, request *mo
 	//     return nil, err
 	// }
 
-	// プレースホルダーレスポンス
+	// タスク毎のプレースホルダーレスポンス（時刻はリクエスト先頭からの位置）
 	response := &model.InferenceResponse{
 		ClientID:       request.ClientID,
-		Result:         "gRPC推論結果（プレースホルダー）",
-		Confidence:     0.95,
 		ProcessingTime: 50 * time.Millisecond,
+		IsFinal:        true,
+	}
+	switch request.Task {
+	case model.TaskClassification:
+		response.Labels = []model.LabelScore{{Label: "speech", Score: 0.9}, {Label: "music", Score: 0.1}}
+	case model.TaskSpeakerEmbedding:
+		response.Embedding = make([]float32, 192)
+	case model.TaskKeywordSpotting:
+		if len(request.Keywords) > 0 {
+			response.Detected = []model.KeywordDetection{{Keyword: request.Keywords[0], Score: 0.9, StartMs: 0, EndMs: request.DurationMs}}
+		}
+	default:
+		response.Result = "gRPC推論結果（プレースホルダー）"
+		response.Confidence = 0.95
+		response.Segments = []model.Segment{{
+			Text:       response.Result,
+			StartMs:    0,
+			EndMs:      request.DurationMs,
+			Confidence: 0.95,
+		}}
 	}
 
 	return response, nil
@@ -60,7 +103,8 @@ func (ic *InferenceClient) SendInferenceRequest(ctx context.Context, request *mo
 
 // SendBatchInferenceRequest バッチ推論リクエストを送信
 func (ic *InferenceClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
-	log.Printf("gRPCバッチ推論リクエスト送信: クライアント=%s, バッチサイズ=%d", batch.ClientID, batch.BatchSize)
+	log.Printf("gRPCバッチ推論リクエスト送信: クライアント=%s, バッチ番号=%d, バッチサイズ=%d, 位置=%dms, 長さ=%dms",
+		batch.ClientID, batch.Sequence, batch.BatchSize, batch.OffsetMs, batch.DurationMs)
 
 	// AudioBatchをInferenceRequestに変換
 	request := &model.InferenceRequest{
@@ -68,24 +112,48 @@ func (ic *InferenceClient) SendBatchInferenceRequest(ctx context.Context, batch
 		AudioData: batch.AudioData,
 		Timestamp: batch.Timestamp,
 		BatchSize: batch.BatchSize,
+		Features:  batch.Features,
+
+		Format:     batch.Format,
+		Sequence:   batch.Sequence,
+		OffsetMs:   batch.OffsetMs,
+		DurationMs: batch.DurationMs,
+
+		IdempotencyKey: batch.IdempotencyKey,
 	}
 
 	return ic.SendInferenceRequest(ctx, request)
 }
 
-// Connect 推論サーバーに接続
+// Connect 推論サーバーへの接続を作成
+// TLSが有効な場合は tlsOptions から生成した設定でサーバー証明書を検証し、クライアント証明書を提示する
+// 接続は最初のリクエストで確立される（推論サーバーが起動していなくても失敗しない）
 func (ic *InferenceClient) Connect(ctx context.Context) error {
-	log.Printf("gRPC推論サーバーに接続中: %s", ic.serverAddress)
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
 
-	// TODO: 実際のgRPC接続を実装
-	// conn, err := grpc.DialContext(ctx, ic.serverAddress, grpc.WithInsecure())
-	// if err != nil {
-	//     return fmt.Errorf("gRPC接続失敗: %w", err)
-	// }
-	//
-	// ic.conn = conn
+	if ic.connected {
+		return nil
+	}
+	log.Printf("gRPC推論サーバーに接続中: %s (TLS=%t)", ic.serverAddress, ic.tlsOptions.Enabled)
+
+	creds := insecure.NewCredentials()
+	if ic.tlsOptions.Enabled {
+		tlsConfig, err := ic.tlsOptions.buildTLSConfig()
+		if err != nil {
+			return fmt.Errorf("gRPC TLS設定失敗: %w", err)
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(ic.serverAddress, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("gRPC接続失敗: %w", err)
+	}
+	// TODO: 推論サービスのクライアントを作成
 	// ic.client = pb.NewInferenceServiceClient(conn)
 
+	ic.conn = conn
 	ic.connected = true
 	log.Printf("gRPC推論サーバー接続成功: %s", ic.serverAddress)
 	return nil
@@ -93,30 +161,36 @@ func (ic *InferenceClient) Connect(ctx context.Context) error {
 
 // Disconnect 推論サーバーから切断
 func (ic *InferenceClient) Disconnect() error {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
 	if !ic.connected {
 		return nil
 	}
 
 	log.Printf("gRPC推論サーバーから切断中: %s", ic.serverAddress)
 
-	// TODO: 実際のgRPC切断を実装
-	// if ic.conn != nil {
-	//     ic.conn.Close()
-	// }
-
+	err := ic.conn.Close()
+	ic.conn = nil
 	ic.connected = false
+	if err != nil {
+		return fmt.Errorf("gRPC切断失敗: %w", err)
+	}
 	log.Printf("gRPC推論サーバー切断完了: %s", ic.serverAddress)
 	return nil
 }
 
 // IsConnected 接続状態を確認
 func (ic *InferenceClient) IsConnected() bool {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
 	return ic.connected
 }
 
 // GetServerStatus サーバーの状態を取得
 func (ic *InferenceClient) GetServerStatus() (string, error) {
-	if !ic.connected {
+	if !ic.IsConnected() {
 		return "disconnected", nil
 	}
 
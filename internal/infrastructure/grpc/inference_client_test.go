@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+)
+
+// writeSelfSignedCert localhost用の自己署名証明書を生成し、証明書と秘密鍵のPEMファイルのパスを返す
+func writeSelfSignedCert(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+"-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// startTLSServer 証明書を提示するgRPCサーバーを起動し、アドレスを返す
+func startTLSServer(t *testing.T, certFile, keyFile string) string {
+	t.Helper()
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}})))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestInferenceClientConnectUsesTLSOptions(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := writeSelfSignedCert(t, dir, "server")
+	otherCA, _ := writeSelfSignedCert(t, dir, "other")
+	address := startTLSServer(t, serverCert, serverKey)
+
+	tests := []struct {
+		name       string
+		tlsOptions TLSOptions
+		wantErr    bool
+		wantReady  bool
+	}{
+		{name: "サーバー証明書を発行したCAで検証", tlsOptions: TLSOptions{Enabled: true, CAFile: serverCert, ServerName: "localhost"}, wantReady: true},
+		{name: "別のCAでは検証に失敗", tlsOptions: TLSOptions{Enabled: true, CAFile: otherCA, ServerName: "localhost"}},
+		{name: "CAバンドルを読み込めない", tlsOptions: TLSOptions{Enabled: true, CAFile: filepath.Join(dir, "missing.pem")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewSecureInferenceClient(address, time.Second, tt.tlsOptions).(*InferenceClient)
+			err := client.Connect(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("エラー = %v（エラーを期待: %v）", err, tt.wantErr)
+			}
+			if err != nil {
+				if client.IsConnected() {
+					t.Fatal("接続に失敗したのに接続済みです")
+				}
+				return
+			}
+			defer client.Disconnect()
+
+			// TLSハンドシェイクの結果で接続状態が決まること
+			client.conn.Connect()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for state := client.conn.GetState(); state != connectivity.Ready && state != connectivity.TransientFailure; state = client.conn.GetState() {
+				if !client.conn.WaitForStateChange(ctx, state) {
+					t.Fatalf("接続状態が変化しません: %s", state)
+				}
+			}
+			if ready := client.conn.GetState() == connectivity.Ready; ready != tt.wantReady {
+				t.Fatalf("接続状態 = %s（Readyを期待: %v）", client.conn.GetState(), tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestInferenceClientConnectionStateIsSafeForConcurrentUse(t *testing.T) {
+	client := NewInferenceClient("127.0.0.1:1", time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_ = client.Connect(context.Background())
+				_ = client.IsConnected()
+				_, _ = client.GetServerStatus()
+				_ = client.Disconnect()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if client.IsConnected() {
+		t.Fatal("切断後も接続済みです")
+	}
+}
@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSOptions 推論サーバーとの通信に用いるTLS設定
+type TLSOptions struct {
+	Enabled    bool   // TLSを使用するか
+	CAFile     string // サーバー証明書検証用CAバンドル（空の場合はシステムのルートCA）
+	CertFile   string // クライアント証明書ファイル（mTLS）
+	KeyFile    string // クライアント秘密鍵ファイル（mTLS）
+	ServerName string // 証明書検証に用いるサーバー名（空の場合は接続先ホスト名）
+}
+
+// buildTLSConfig TLSOptionsからtls.Configを生成
+func (o TLSOptions) buildTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: o.ServerName,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("CAバンドル読み込み失敗: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CAバンドルに有効な証明書がありません: %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("クライアント証明書読み込み失敗: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,345 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net"
+	nethttp "net/http"
+	"net/textproto"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/model"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxErrorBody エラーメッセージに含めるレスポンスボディの最大バイト数
+const maxErrorBody = 512
+
+// requestMetadata HTTP推論サーバーに送るバッチのメタデータ
+type requestMetadata struct {
+	ClientID       string               `json:"client_id"`
+	Sequence       uint64               `json:"sequence"`
+	OffsetMs       int64                `json:"offset_ms"`
+	DurationMs     int64                `json:"duration_ms"`
+	BatchSize      int                  `json:"batch_size"`
+	Timestamp      time.Time            `json:"timestamp"`
+	Format         model.AudioFormat    `json:"format"`
+	Task           model.TaskType       `json:"task,omitempty"`
+	Keywords       []string             `json:"keywords,omitempty"`
+	Model          string               `json:"model,omitempty"`
+	ModelVersion   string               `json:"model_version,omitempty"`
+	IdempotencyKey string               `json:"idempotency_key,omitempty"`
+	Features       *model.FeatureTensor `json:"features,omitempty"`
+}
+
+// requestBody JSONエンコーディングのリクエストボディ（音声はbase64）
+type requestBody struct {
+	requestMetadata
+	Audio []byte `json:"audio"`
+}
+
+// InferenceClient HTTP/JSONで推論サーバーにバッチを送信するInferenceClientの実装
+// エラーはgRPCクライアントと同じステータスコードで返し、リトライ・サーキットブレーカーの判定を共通にする
+type InferenceClient struct {
+	endpoint  string
+	timeout   time.Duration
+	config    model.HTTPBackendConfig
+	mapping   *ResponseMapping
+	client    *nethttp.Client
+	connected atomic.Bool
+}
+
+// NewInferenceClient エンドポイントのURLにバッチをPOSTするHTTP推論クライアントを作成
+// mapping がnilの場合はレスポンスを推論結果のJSONとしてそのまま読み込む
+func NewInferenceClient(endpoint string, timeout time.Duration, config model.HTTPBackendConfig, mapping *ResponseMapping) interfaces.InferenceClient {
+	transport := nethttp.DefaultTransport.(*nethttp.Transport).Clone()
+	transport.MaxIdleConns = max(config.MaxIdleConns, transport.MaxIdleConns)
+	transport.MaxIdleConnsPerHost = config.MaxIdleConns
+
+	return &InferenceClient{
+		endpoint: endpoint,
+		timeout:  timeout,
+		config:   config,
+		mapping:  mapping,
+		client:   &nethttp.Client{Transport: transport},
+	}
+}
+
+// SendInferenceRequest 推論リクエストをHTTP推論サーバーにPOST
+func (ic *InferenceClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	log.Printf("HTTP推論リクエスト送信: クライアント=%s, タスク=%s, 送信先=%s", request.ClientID, request.Task, ic.endpoint)
+
+	if ic.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ic.timeout)
+		defer cancel()
+	}
+
+	metadata := requestMetadata{
+		ClientID:       request.ClientID,
+		Sequence:       request.Sequence,
+		OffsetMs:       request.OffsetMs,
+		DurationMs:     request.DurationMs,
+		BatchSize:      request.BatchSize,
+		Timestamp:      request.Timestamp,
+		Format:         request.Format,
+		Task:           request.Task,
+		Keywords:       request.Keywords,
+		Model:          request.Model,
+		ModelVersion:   request.ModelVersion,
+		IdempotencyKey: request.IdempotencyKey,
+		Features:       request.Features,
+	}
+	body, contentType, err := ic.encode(metadata, bytes.Join(request.AudioData, nil))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "HTTP推論リクエストのエンコード失敗: %v", err)
+	}
+
+	httpRequest, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, ic.endpoint, body)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "HTTP推論リクエスト作成失敗: %v", err)
+	}
+	httpRequest.Header.Set("Content-Type", contentType)
+	httpRequest.Header.Set("Accept", "application/json")
+	if request.IdempotencyKey != "" {
+		httpRequest.Header.Set("Idempotency-Key", request.IdempotencyKey)
+	}
+	for key, value := range ic.config.Headers {
+		httpRequest.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	httpResponse, err := ic.client.Do(httpRequest)
+	if err != nil {
+		return nil, transportError(ctx, err)
+	}
+	defer closeBody(httpResponse.Body)
+
+	payload, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, transportError(ctx, err)
+	}
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300 {
+		return nil, responseError(httpResponse.StatusCode, payload)
+	}
+
+	response, err := ic.mapping.Map(payload)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "HTTP推論サーバーのレスポンスを解析できません: %v", err)
+	}
+	response.ClientID = request.ClientID
+	if response.ProcessingTime == 0 {
+		response.ProcessingTime = time.Since(start)
+	}
+	return response, nil
+}
+
+// SendBatchInferenceRequest バッチ推論リクエストをHTTP推論サーバーにPOST
+func (ic *InferenceClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	// AudioBatchをInferenceRequestに変換
+	request := &model.InferenceRequest{
+		ClientID:  batch.ClientID,
+		AudioData: batch.AudioData,
+		Timestamp: batch.Timestamp,
+		BatchSize: batch.BatchSize,
+		Features:  batch.Features,
+
+		Format:     batch.Format,
+		Sequence:   batch.Sequence,
+		OffsetMs:   batch.OffsetMs,
+		DurationMs: batch.DurationMs,
+
+		IdempotencyKey: batch.IdempotencyKey,
+	}
+
+	return ic.SendInferenceRequest(ctx, request)
+}
+
+// Connect 推論サーバーに接続（ヘルスチェックのパスが設定されている場合は応答を確認）
+// 接続はリクエスト毎にプールから再利用されるため、ここでは確立しない
+func (ic *InferenceClient) Connect(ctx context.Context) error {
+	log.Printf("HTTP推論サーバーに接続中: %s", ic.endpoint)
+
+	if err := ic.checkHealth(ctx); err != nil {
+		return fmt.Errorf("HTTP推論サーバー接続失敗: %w", err)
+	}
+	ic.connected.Store(true)
+	log.Printf("HTTP推論サーバー接続成功: %s", ic.endpoint)
+	return nil
+}
+
+// Disconnect 推論サーバーから切断（アイドル接続を閉じる）
+func (ic *InferenceClient) Disconnect() error {
+	if !ic.connected.Swap(false) {
+		return nil
+	}
+	ic.client.CloseIdleConnections()
+	log.Printf("HTTP推論サーバー切断完了: %s", ic.endpoint)
+	return nil
+}
+
+// IsConnected 接続状態を確認
+func (ic *InferenceClient) IsConnected() bool {
+	return ic.connected.Load()
+}
+
+// GetServerStatus サーバーの状態を取得（ヘルスチェックのパスが設定されている場合は応答を確認）
+func (ic *InferenceClient) GetServerStatus() (string, error) {
+	if !ic.connected.Load() {
+		return "disconnected", nil
+	}
+
+	ctx := context.Background()
+	if ic.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ic.timeout)
+		defer cancel()
+	}
+	if err := ic.checkHealth(ctx); err != nil {
+		return "unhealthy", err
+	}
+	return "connected", nil
+}
+
+// checkHealth ヘルスチェックのパスにGETし、2xxでなければエラーを返す
+func (ic *InferenceClient) checkHealth(ctx context.Context) error {
+	if ic.config.HealthPath == "" {
+		return nil
+	}
+
+	base, err := url.Parse(ic.endpoint)
+	if err != nil {
+		return fmt.Errorf("推論サーバーのURLが不正です: %w", err)
+	}
+	healthURL, err := base.Parse(ic.config.HealthPath)
+	if err != nil {
+		return fmt.Errorf("ヘルスチェックのパスが不正です: %w", err)
+	}
+
+	request, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, healthURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range ic.config.Headers {
+		request.Header.Set(key, value)
+	}
+	response, err := ic.client.Do(request)
+	if err != nil {
+		return transportError(ctx, err)
+	}
+	defer closeBody(response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		payload, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+		return responseError(response.StatusCode, payload)
+	}
+	return nil
+}
+
+// encode 設定されたエンコーディングでリクエストボディを作成
+func (ic *InferenceClient) encode(metadata requestMetadata, audio []byte) (io.Reader, string, error) {
+	if ic.config.Encoding != model.HTTPEncodingMultipart {
+		payload, err := json.Marshal(requestBody{requestMetadata: metadata, Audio: audio})
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(payload), "application/json", nil
+	}
+
+	// メタデータのJSONと生の音声をmultipart/form-dataで送信
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="metadata"`)
+	header.Set("Content-Type", "application/json")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := json.NewEncoder(part).Encode(metadata); err != nil {
+		return nil, "", err
+	}
+
+	header = textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="audio"; filename="audio.raw"`)
+	header.Set("Content-Type", "application/octet-stream")
+	part, err = writer.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(audio); err != nil {
+		return nil, "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, writer.FormDataContentType(), nil
+}
+
+// closeBody 接続を再利用できるよう残りのボディを読み捨ててから閉じる
+func closeBody(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 64<<10))
+	_ = body.Close()
+}
+
+// transportError 通信エラーをgRPCクライアントと同じステータスに変換
+// 取り消し・タイムアウトはgRPCと同様に Canceled / DeadlineExceeded になる
+func transportError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.Errorf(status.FromContextError(ctxErr).Code(), "HTTP推論リクエスト中断: %v", ctxErr)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return status.Errorf(codes.DeadlineExceeded, "HTTP推論サーバーの応答がタイムアウトしました: %v", err)
+	}
+	return status.Errorf(codes.Unavailable, "HTTP推論サーバーと通信できません: %v", err)
+}
+
+// responseError HTTPステータスをgRPCのステータスコードに対応付けたエラーを作成
+func responseError(statusCode int, body []byte) error {
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return status.Errorf(httpStatusCode(statusCode), "HTTP推論サーバーがエラーを返しました: %d %s: %s",
+		statusCode, nethttp.StatusText(statusCode), bytes.TrimSpace(body))
+}
+
+// httpStatusCode HTTPステータスに対応するgRPCのステータスコード
+func httpStatusCode(statusCode int) codes.Code {
+	switch statusCode {
+	case nethttp.StatusBadRequest, nethttp.StatusUnprocessableEntity, nethttp.StatusRequestEntityTooLarge, nethttp.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case nethttp.StatusUnauthorized:
+		return codes.Unauthenticated
+	case nethttp.StatusForbidden:
+		return codes.PermissionDenied
+	case nethttp.StatusNotFound:
+		return codes.NotFound
+	case nethttp.StatusConflict:
+		return codes.Aborted
+	case nethttp.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case nethttp.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case nethttp.StatusRequestTimeout, nethttp.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case nethttp.StatusNotImplemented:
+		return codes.Unimplemented
+	case nethttp.StatusBadGateway, nethttp.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
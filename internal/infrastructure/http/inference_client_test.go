@@ -0,0 +1,364 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestBackend handler で応答するHTTP推論サーバーの代役と、それに接続したクライアントを作成
+func newTestBackend(t *testing.T, config model.HTTPBackendConfig, mapping *ResponseMapping, handler nethttp.HandlerFunc) *InferenceClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewInferenceClient(server.URL+"/v1/infer", 5*time.Second, config, mapping).(*InferenceClient)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Disconnect() })
+	return client
+}
+
+// testRequest テスト用の推論リクエスト
+func testRequest() *model.InferenceRequest {
+	return &model.InferenceRequest{
+		ClientID:  "client-1",
+		AudioData: [][]byte{{1, 2}, {3, 4}},
+		BatchSize: 2,
+		Sequence:  7,
+		Format:    model.AudioFormat{Encoding: model.EncodingF32LE, SampleRate: 16000, Channels: 1},
+		Task:      model.TaskTranscription,
+
+		IdempotencyKey: "client-1-7",
+	}
+}
+
+// hangingHandler 応答せずにリクエストの終了まで待機する推論サーバー
+// ボディを読み切らないとクライアントの切断が検知されず、リクエストのコンテキストが終了しない
+func hangingHandler(w nethttp.ResponseWriter, r *nethttp.Request) {
+	io.Copy(io.Discard, r.Body)
+	<-r.Context().Done()
+}
+
+func TestInferenceClientMapsHTTPStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   codes.Code
+	}{
+		{nethttp.StatusBadRequest, codes.InvalidArgument},
+		{nethttp.StatusUnauthorized, codes.Unauthenticated},
+		{nethttp.StatusForbidden, codes.PermissionDenied},
+		{nethttp.StatusNotFound, codes.NotFound},
+		{nethttp.StatusRequestTimeout, codes.DeadlineExceeded},
+		{nethttp.StatusConflict, codes.Aborted},
+		{nethttp.StatusPreconditionFailed, codes.FailedPrecondition},
+		{nethttp.StatusRequestEntityTooLarge, codes.InvalidArgument},
+		{nethttp.StatusUnsupportedMediaType, codes.InvalidArgument},
+		{nethttp.StatusUnprocessableEntity, codes.InvalidArgument},
+		{nethttp.StatusTooManyRequests, codes.ResourceExhausted},
+		{nethttp.StatusInternalServerError, codes.Internal},
+		{nethttp.StatusNotImplemented, codes.Unimplemented},
+		{nethttp.StatusBadGateway, codes.Unavailable},
+		{nethttp.StatusServiceUnavailable, codes.Unavailable},
+		{nethttp.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{nethttp.StatusTeapot, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(nethttp.StatusText(tt.status), func(t *testing.T) {
+			client := newTestBackend(t, model.DefaultHTTPBackendConfig(), nil, func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				nethttp.Error(w, "推論サーバーのエラー", tt.status)
+			})
+
+			_, err := client.SendInferenceRequest(context.Background(), testRequest())
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("ステータスコード = %s（期待値 %s）: %v", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestInferenceClientTransportErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T) error
+		want codes.Code
+	}{
+		{
+			name: "タイムアウト",
+			run: func(t *testing.T) error {
+				client := newTestBackend(t, model.DefaultHTTPBackendConfig(), nil, hangingHandler)
+				client.timeout = 20 * time.Millisecond
+				_, err := client.SendInferenceRequest(context.Background(), testRequest())
+				return err
+			},
+			want: codes.DeadlineExceeded,
+		},
+		{
+			name: "呼び出し元の取り消し",
+			run: func(t *testing.T) error {
+				client := newTestBackend(t, model.DefaultHTTPBackendConfig(), nil, hangingHandler)
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(20*time.Millisecond, cancel)
+				_, err := client.SendInferenceRequest(ctx, testRequest())
+				return err
+			},
+			want: codes.Canceled,
+		},
+		{
+			name: "接続できない",
+			run: func(t *testing.T) error {
+				server := httptest.NewServer(nethttp.NotFoundHandler())
+				server.Close()
+				client := NewInferenceClient(server.URL, time.Second, model.DefaultHTTPBackendConfig(), nil)
+				_, err := client.SendInferenceRequest(context.Background(), testRequest())
+				return err
+			},
+			want: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.run(t)); got != tt.want {
+				t.Fatalf("ステータスコード = %s（期待値 %s）", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferenceClientResponseMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		body       string
+		want       model.InferenceResponse
+		wantStatus codes.Code
+	}{
+		{
+			name: "テンプレートなしはそのまま読み込む",
+			body: `{"result": "こんにちは", "confidence": 0.9, "is_final": true}`,
+			want: model.InferenceResponse{Result: "こんにちは", Confidence: 0.9, IsFinal: true},
+		},
+		{
+			name:     "独自形式のレスポンスを変換",
+			template: `{"result": {{json .output.text}}, "confidence": {{json .output.score}}, "language": {{json .lang}}, "is_final": true}`,
+			body:     `{"output": {"text": "おはよう", "score": 0.75}, "lang": "ja"}`,
+			want:     model.InferenceResponse{Result: "おはよう", Confidence: 0.75, Language: "ja", IsFinal: true},
+		},
+		{
+			name:     "存在しないキーにはdefaultを使用",
+			template: `{"result": {{json .text}}, "confidence": {{json (default 1 .score)}}}`,
+			body:     `{"text": "はい"}`,
+			want:     model.InferenceResponse{Result: "はい", Confidence: 1},
+		},
+		{
+			name:     "セグメントの配列を変換",
+			template: `{"result": {{json .text}}, "segments": [{{range $i, $s := .chunks}}{{if $i}},{{end}}{{json $s}}{{end}}]}`,
+			body:     `{"text": "あ い", "chunks": [{"text": "あ"}, {"text": "い"}]}`,
+			want:     model.InferenceResponse{Result: "あ い", Segments: []model.Segment{{Text: "あ"}, {Text: "い"}}},
+		},
+		{
+			name:       "JSONではないレスポンス",
+			template:   `{"result": {{json .text}}}`,
+			body:       `<html>error</html>`,
+			wantStatus: codes.Internal,
+		},
+		{
+			name:       "テンプレートの出力がJSONではない",
+			template:   `result={{.text}}`,
+			body:       `{"text": "はい"}`,
+			wantStatus: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping, err := NewResponseMapping(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := newTestBackend(t, model.DefaultHTTPBackendConfig(), mapping, func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				io.WriteString(w, tt.body)
+			})
+
+			response, err := client.SendInferenceRequest(context.Background(), testRequest())
+			if tt.wantStatus != codes.OK {
+				if got := status.Code(err); got != tt.wantStatus {
+					t.Fatalf("ステータスコード = %s（期待値 %s）: %v", got, tt.wantStatus, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if response.ClientID != "client-1" {
+				t.Errorf("クライアントID = %q（期待値 client-1）", response.ClientID)
+			}
+			if response.ProcessingTime <= 0 {
+				t.Error("処理時間が設定されていません")
+			}
+			if response.Result != tt.want.Result || response.Confidence != tt.want.Confidence ||
+				response.Language != tt.want.Language || response.IsFinal != tt.want.IsFinal {
+				t.Errorf("推論結果 = %+v（期待値 %+v）", response, tt.want)
+			}
+			if len(response.Segments) != len(tt.want.Segments) {
+				t.Fatalf("セグメント = %+v（期待値 %+v）", response.Segments, tt.want.Segments)
+			}
+			for i := range tt.want.Segments {
+				if response.Segments[i].Text != tt.want.Segments[i].Text {
+					t.Errorf("セグメント %d = %q（期待値 %q）", i, response.Segments[i].Text, tt.want.Segments[i].Text)
+				}
+			}
+		})
+	}
+}
+
+func TestNewResponseMappingRejectsInvalidTemplate(t *testing.T) {
+	if _, err := NewResponseMapping(`{"result": {{json .text}`); err == nil {
+		t.Fatal("不正なテンプレートがエラーになりません")
+	}
+}
+
+func TestInferenceClientEncodings(t *testing.T) {
+	tests := []struct {
+		name            string
+		encoding        model.HTTPRequestEncoding
+		wantContentType string
+		decode          func(r *nethttp.Request) (requestMetadata, []byte, error)
+	}{
+		{
+			name:            "json",
+			encoding:        model.HTTPEncodingJSON,
+			wantContentType: "application/json",
+			decode: func(r *nethttp.Request) (requestMetadata, []byte, error) {
+				var body requestBody
+				err := json.NewDecoder(r.Body).Decode(&body)
+				return body.requestMetadata, body.Audio, err
+			},
+		},
+		{
+			name:            "multipart",
+			encoding:        model.HTTPEncodingMultipart,
+			wantContentType: "multipart/form-data",
+			decode: func(r *nethttp.Request) (requestMetadata, []byte, error) {
+				var metadata requestMetadata
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					return metadata, nil, err
+				}
+				if err := json.Unmarshal([]byte(r.FormValue("metadata")), &metadata); err != nil {
+					return metadata, nil, err
+				}
+				file, _, err := r.FormFile("audio")
+				if err != nil {
+					return metadata, nil, err
+				}
+				defer file.Close()
+				audio, err := io.ReadAll(file)
+				return metadata, audio, err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := model.DefaultHTTPBackendConfig()
+			config.Encoding = tt.encoding
+			config.Headers = map[string]string{"Authorization": "Bearer token"}
+
+			type received struct {
+				contentType, authorization, idempotencyKey string
+				metadata                                   requestMetadata
+				audio                                      []byte
+				err                                        error
+			}
+			requests := make(chan received, 1)
+			client := newTestBackend(t, config, nil, func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				metadata, audio, err := tt.decode(r)
+				requests <- received{
+					contentType:    r.Header.Get("Content-Type"),
+					authorization:  r.Header.Get("Authorization"),
+					idempotencyKey: r.Header.Get("Idempotency-Key"),
+					metadata:       metadata,
+					audio:          audio,
+					err:            err,
+				}
+				io.WriteString(w, `{"result": "ok"}`)
+			})
+
+			if _, err := client.SendInferenceRequest(context.Background(), testRequest()); err != nil {
+				t.Fatal(err)
+			}
+			got := <-requests
+			if got.err != nil {
+				t.Fatalf("リクエストボディを読み込めません: %v", got.err)
+			}
+			if mediaType := got.contentType[:min(len(got.contentType), len(tt.wantContentType))]; mediaType != tt.wantContentType {
+				t.Errorf("Content-Type = %q（期待値 %s）", got.contentType, tt.wantContentType)
+			}
+			if got.authorization != "Bearer token" {
+				t.Errorf("設定したヘッダーが付与されていません: %q", got.authorization)
+			}
+			if got.idempotencyKey != "client-1-7" {
+				t.Errorf("Idempotency-Key = %q（期待値 client-1-7）", got.idempotencyKey)
+			}
+			if got.metadata.ClientID != "client-1" || got.metadata.Sequence != 7 || got.metadata.BatchSize != 2 ||
+				got.metadata.Format.SampleRate != 16000 || got.metadata.Task != model.TaskTranscription {
+				t.Errorf("メタデータ = %+v", got.metadata)
+			}
+			if string(got.audio) != string([]byte{1, 2, 3, 4}) {
+				t.Errorf("音声 = %v（期待値 [1 2 3 4]）", got.audio)
+			}
+		})
+	}
+}
+
+func TestInferenceClientHealthCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		healthPath  string
+		status      int
+		wantConnect bool
+	}{
+		{name: "ヘルスチェックなし", healthPath: "", status: nethttp.StatusServiceUnavailable, wantConnect: true},
+		{name: "正常", healthPath: "/healthz", status: nethttp.StatusOK, wantConnect: true},
+		{name: "異常", healthPath: "/healthz", status: nethttp.StatusServiceUnavailable, wantConnect: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+				if r.Method != nethttp.MethodGet || r.URL.Path != "/healthz" {
+					nethttp.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			config := model.DefaultHTTPBackendConfig()
+			config.HealthPath = tt.healthPath
+			client := NewInferenceClient(server.URL+"/v1/infer", time.Second, config, nil)
+			err := client.Connect(context.Background())
+			if connected := err == nil; connected != tt.wantConnect {
+				t.Fatalf("接続できたか = %t（期待値 %t）: %v", connected, tt.wantConnect, err)
+			}
+			if client.IsConnected() != tt.wantConnect {
+				t.Fatalf("IsConnected = %t（期待値 %t）", client.IsConnected(), tt.wantConnect)
+			}
+		})
+	}
+}
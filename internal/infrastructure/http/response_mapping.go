@@ -0,0 +1,84 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"socket_inference/internal/model"
+)
+
+// ResponseMapping 推論サーバーのレスポンスJSONを推論結果に変換する対応付け
+// テンプレートにはデコードしたレスポンスJSONが渡され、推論結果のJSON（InferenceResponse）を出力する
+//
+//	{"result": {{json .text}}, "confidence": {{json .score}}}
+type ResponseMapping struct {
+	template *template.Template
+}
+
+// templateFuncs レスポンスのテンプレートで使用できる関数
+var templateFuncs = template.FuncMap{
+	// json 値をJSONとして出力
+	"json": func(v any) (string, error) {
+		payload, err := json.Marshal(v)
+		return string(payload), err
+	},
+	// default 値がない場合に代わりの値を使用
+	"default": func(fallback, v any) any {
+		if v == nil {
+			return fallback
+		}
+		return v
+	},
+}
+
+// NewResponseMapping テンプレートからレスポンスの対応付けを作成（空の場合はnil）
+func NewResponseMapping(text string) (*ResponseMapping, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("response").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: レスポンスのテンプレートを解析できません: %v", model.ErrInvalidBackendConfig, err)
+	}
+	return &ResponseMapping{template: tmpl}, nil
+}
+
+// LoadResponseMapping 通信設定のテンプレート（またはテンプレートのファイル）からレスポンスの対応付けを作成
+func LoadResponseMapping(config model.HTTPBackendConfig) (*ResponseMapping, error) {
+	if config.ResponseTemplateFile == "" {
+		return NewResponseMapping(config.ResponseTemplate)
+	}
+	text, err := os.ReadFile(config.ResponseTemplateFile)
+	if err != nil {
+		return nil, fmt.Errorf("レスポンスのテンプレートファイルを読み込めません: %w", err)
+	}
+	return NewResponseMapping(string(text))
+}
+
+// Map レスポンスボディを推論結果に変換
+// 対応付けがnilの場合はボディを推論結果のJSONとしてそのまま読み込む
+func (m *ResponseMapping) Map(body []byte) (*model.InferenceResponse, error) {
+	if m != nil {
+		var data any
+		if err := json.Unmarshal(body, &data); err != nil {
+			return nil, fmt.Errorf("レスポンスがJSONではありません: %w", err)
+		}
+
+		var rendered bytes.Buffer
+		if err := m.template.Execute(&rendered, data); err != nil {
+			return nil, fmt.Errorf("レスポンスのテンプレートの適用失敗: %w", err)
+		}
+		body = rendered.Bytes()
+	}
+
+	var response model.InferenceResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("推論結果のJSONを読み込めません: %w", err)
+	}
+	return &response, nil
+}
@@ -36,5 +36,19 @@ type ConnectionConfig interface {
 	GetTimeout() int
 
 	// GetRetryPolicy リトライポリシーを取得
-	GetRetryPolicy() map[string]interface{}
+	GetRetryPolicy() model.RetryPolicy
+}
+
+// ModelRegistry 推論モデルの名前・バージョンと推論クライアントの対応表のインターフェース
+type ModelRegistry interface {
+	// Resolve セッションが宣言したモデルの情報と推論クライアントを取得
+	// 登録されていない場合は *model.UnknownModelError を返す
+	Resolve(selection model.ModelSelection) (model.ModelInfo, InferenceClient, error)
+
+	// Route セッションの推論モデルを決定
+	// バージョンを指定しないセッションは、カナリア設定に従いセッションID毎に固定で新しいバージョンに振り分ける
+	Route(sessionID string, selection model.ModelSelection) (model.ModelInfo, InferenceClient, error)
+
+	// Models 登録されている推論モデルの一覧を取得（登録順）
+	Models() []model.ModelInfo
 }
@@ -0,0 +1,172 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/model"
+)
+
+// modelEntry 登録された推論モデルと送信先の推論クライアント
+type modelEntry struct {
+	info   model.ModelInfo
+	client interfaces.InferenceClient
+}
+
+// ModelRegistry 推論モデルの名前・バージョン毎に推論クライアントを保持する対応表
+// 名前を省略したセッションは既定のモデル、バージョンを省略したセッションはそのモデルの既定のバージョンを使用する
+type ModelRegistry struct {
+	mu      sync.RWMutex
+	entries []*modelEntry // 登録順
+}
+
+// NewModelRegistry 空の推論モデルの対応表を作成
+func NewModelRegistry() *ModelRegistry {
+	return &ModelRegistry{}
+}
+
+// Register 推論モデルと送信先の推論クライアントを登録
+// 同じ名前・バージョンの重複登録と、既定のモデル（Default）の複数指定はエラー
+func (r *ModelRegistry) Register(info model.ModelInfo, client interfaces.InferenceClient) error {
+	if err := (model.ModelSpec{ModelInfo: info}).Validate(); err != nil {
+		return err
+	}
+	if client == nil {
+		return fmt.Errorf("%w: %s の推論クライアントがありません", model.ErrInvalidModelSpec, info.ID())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, entry := range r.entries {
+		if entry.info.ID() == info.ID() {
+			return fmt.Errorf("%w: %s が重複しています", model.ErrInvalidModelSpec, info.ID())
+		}
+		if info.Default && entry.info.Default {
+			return fmt.Errorf("%w: 既定のモデルが複数あります: %s, %s", model.ErrInvalidModelSpec, entry.info.ID(), info.ID())
+		}
+	}
+	r.entries = append(r.entries, &modelEntry{info: info, client: client})
+
+	log.Printf("推論モデルを登録しました: %s", info.ID())
+	return nil
+}
+
+// Resolve セッションが宣言したモデルの情報と推論クライアントを取得
+// 既定のモデル・バージョンが指定されていない場合は最初に登録したものを使用する
+func (r *ModelRegistry) Resolve(selection model.ModelSelection) (model.ModelInfo, interfaces.InferenceClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found *modelEntry
+	for _, entry := range r.entries {
+		if selection.Name != "" && entry.info.Name != selection.Name {
+			continue
+		}
+		if selection.Version != "" && entry.info.Version != selection.Version {
+			continue
+		}
+		if found == nil || entry.info.Default && !found.info.Default {
+			found = entry
+		}
+	}
+	if found == nil {
+		return model.ModelInfo{}, nil, &model.UnknownModelError{Requested: selection, Available: r.ids()}
+	}
+	return found.info, found.client, nil
+}
+
+// Route セッションの推論モデルを決定
+// バージョンを指定しないセッションは、カナリア設定の割合に従いセッションID毎に固定でカナリアのバージョンに振り分ける
+// sessionID には接続毎に一意のIDを渡すこと（クライアントIDは匿名や証明書の共有で重複し、振り分けが偏る）
+// カナリアのバージョンが登録されていない場合は元のバージョンを使用する
+func (r *ModelRegistry) Route(sessionID string, selection model.ModelSelection) (model.ModelInfo, interfaces.InferenceClient, error) {
+	info, client, err := r.Resolve(selection)
+	if err != nil || selection.Version != "" || info.Canary == nil {
+		return info, client, err
+	}
+	if canaryBucket(sessionID, info.Name) >= info.Canary.Fraction {
+		return info, client, nil
+	}
+
+	canary := model.ModelSelection{Name: info.Name, Version: info.Canary.Version}
+	canaryInfo, canaryClient, err := r.Resolve(canary)
+	if err != nil {
+		log.Printf("カナリアの推論モデルを解決できないため %s を使用: %v", info.ID(), err)
+		return info, client, nil
+	}
+	log.Printf("セッション %s をカナリアの推論モデル %s に振り分けました", sessionID, canaryInfo.ID())
+	return canaryInfo, canaryClient, nil
+}
+
+// Models 登録されている推論モデルの一覧を取得（登録順）
+func (r *ModelRegistry) Models() []model.ModelInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	models := make([]model.ModelInfo, len(r.entries))
+	for i, entry := range r.entries {
+		models[i] = entry.info
+	}
+	return models
+}
+
+// Connect 全ての推論モデルの推論サーバーに接続
+func (r *ModelRegistry) Connect(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.entries) == 0 {
+		return fmt.Errorf("%w: 推論モデルが登録されていません", model.ErrInvalidModelSpec)
+	}
+	for _, entry := range r.entries {
+		if err := entry.client.Connect(ctx); err != nil {
+			return fmt.Errorf("推論モデル %s の推論サーバー接続失敗: %w", entry.info.ID(), err)
+		}
+	}
+	return nil
+}
+
+// Disconnect 全ての推論モデルの推論サーバーから切断
+func (r *ModelRegistry) Disconnect() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs []error
+	for _, entry := range r.entries {
+		if err := entry.client.Disconnect(); err != nil {
+			errs = append(errs, fmt.Errorf("推論モデル %s の切断失敗: %w", entry.info.ID(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// canaryBucket セッションIDとモデル名から 0〜1 の値を決定（同じセッションは常に同じ値）
+func canaryBucket(sessionID, name string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(sessionID))
+
+	// 似たセッションIDでも偏らないよう上位ビットを撹拌（MurmurHash3の最終処理）
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return float64(x>>11) / (1 << 53)
+}
+
+// ids 登録されている推論モデルの識別子の一覧（r.mu を保持して呼び出す）
+func (r *ModelRegistry) ids() []string {
+	ids := make([]string, len(r.entries))
+	for i, entry := range r.entries {
+		ids[i] = entry.info.ID()
+	}
+	return ids
+}
@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"socket_inference/internal/model"
+)
+
+// stubClient 推論しないテスト用の推論クライアント
+type stubClient struct{}
+
+func (stubClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	return &model.InferenceResponse{}, nil
+}
+
+func (stubClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	return &model.InferenceResponse{}, nil
+}
+
+func (stubClient) Connect(ctx context.Context) error { return nil }
+func (stubClient) Disconnect() error                 { return nil }
+func (stubClient) IsConnected() bool                 { return true }
+func (stubClient) GetServerStatus() (string, error)  { return "connected", nil }
+
+func TestModelRegistryRouteCanary(t *testing.T) {
+	const sessions = 5000
+
+	tests := []struct {
+		name     string
+		fraction float64
+	}{
+		{name: "振り分けない", fraction: 0},
+		{name: "2割を振り分け", fraction: 0.2},
+		{name: "全て振り分け", fraction: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewModelRegistry()
+			stable := model.ModelInfo{Name: "ja", Version: "2", Default: true, Canary: &model.CanaryConfig{Version: "3", Fraction: tt.fraction}}
+			if err := r.Register(stable, stubClient{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Register(model.ModelInfo{Name: "ja", Version: "3"}, stubClient{}); err != nil {
+				t.Fatal(err)
+			}
+
+			canary := 0
+			for i := 0; i < sessions; i++ {
+				sessionID := fmt.Sprintf("session-%d", i)
+				info, _, err := r.Route(sessionID, model.ModelSelection{Name: "ja"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Version == "3" {
+					canary++
+				}
+
+				// 同じセッションIDは常に同じバージョンに振り分けられること
+				again, _, err := r.Route(sessionID, model.ModelSelection{Name: "ja"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if again.Version != info.Version {
+					t.Fatalf("セッション %s の振り分け先が変わりました: %s → %s", sessionID, info.Version, again.Version)
+				}
+			}
+
+			if got := float64(canary) / sessions; math.Abs(got-tt.fraction) > 0.03 {
+				t.Fatalf("カナリアに振り分けた割合 = %.3f（期待値 %.2f）", got, tt.fraction)
+			}
+		})
+	}
+}
+
+func TestModelRegistryRouteExplicitVersion(t *testing.T) {
+	r := NewModelRegistry()
+	stable := model.ModelInfo{Name: "ja", Version: "2", Default: true, Canary: &model.CanaryConfig{Version: "3", Fraction: 1}}
+	if err := r.Register(stable, stubClient{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(model.ModelInfo{Name: "ja", Version: "3"}, stubClient{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// バージョンを指定したセッションはカナリアに振り分けないこと
+	info, _, err := r.Route("session", model.ModelSelection{Name: "ja", Version: "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "2" {
+		t.Fatalf("バージョン = %s（期待値 2）", info.Version)
+	}
+}
@@ -0,0 +1,459 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/model"
+)
+
+// ringReplicas コンシステントハッシュのリング上に配置するレプリカ毎の仮想ノード数
+const ringReplicas = 100
+
+// maxEjectionMultiplier 連続失敗で外れる度に延長する除外時間の上限（EjectionDuration の倍数）
+const maxEjectionMultiplier = 10
+
+// BackendFactory 推論サーバーのアドレスからInferenceClientを作成する関数
+type BackendFactory func(address string) interfaces.InferenceClient
+
+// backend 負荷分散の対象となる推論サーバーのレプリカ
+type backend struct {
+	address     string
+	client      interfaces.InferenceClient
+	outstanding atomic.Int64 // 送信中のリクエスト数
+	failures    int          // 連続失敗回数（BalancingInferenceClient.mu で保護）
+	healthy     bool         // 振り分け対象か（BalancingInferenceClient.mu で保護）
+
+	// 連続失敗による除外（BalancingInferenceClient.mu で保護）
+	ejections    int       // 復帰後に成功しないまま外れた回数
+	ejectedUntil time.Time // この時刻まではヘルスチェックに成功しても戻さない
+}
+
+// ringPoint コンシステントハッシュのリング上の仮想ノード
+type ringPoint struct {
+	hash    uint64
+	backend *backend
+}
+
+// BalancingInferenceClient 複数の推論サーバーにリクエストを振り分けるInferenceClient
+// 失敗が続くレプリカを振り分け対象から外し、ヘルスチェックで回復を確認したら戻す
+type BalancingInferenceClient struct {
+	config  model.LoadBalancingConfig
+	factory BackendFactory
+
+	mu        sync.RWMutex
+	backends  []*backend  // アドレス順
+	ring      []ringPoint // ハッシュ順（全レプリカ）
+	connected bool
+	next      atomic.Uint64 // ラウンドロビンの位置
+	cancel    context.CancelFunc
+	now       func() time.Time
+}
+
+// NewBalancingInferenceClient 推論サーバーのアドレス一覧から負荷分散クライアントを作成
+func NewBalancingInferenceClient(addresses []string, factory BackendFactory, config model.LoadBalancingConfig) (*BalancingInferenceClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("%w: 推論サーバーのアドレスがありません", model.ErrInvalidLoadBalancingConfig)
+	}
+
+	bc := &BalancingInferenceClient{
+		config:  config,
+		factory: factory,
+		now:     time.Now,
+	}
+	bc.backends, _ = bc.buildBackends(addresses, nil)
+	bc.ring = buildRing(bc.backends)
+	return bc, nil
+}
+
+// SendInferenceRequest レプリカを選んで推論リクエストを送信
+func (bc *BalancingInferenceClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	return bc.do(ctx, request.ClientID, func(ctx context.Context, client interfaces.InferenceClient) (*model.InferenceResponse, error) {
+		return client.SendInferenceRequest(ctx, request)
+	})
+}
+
+// SendBatchInferenceRequest レプリカを選んでバッチ推論リクエストを送信
+func (bc *BalancingInferenceClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	return bc.do(ctx, batch.ClientID, func(ctx context.Context, client interfaces.InferenceClient) (*model.InferenceResponse, error) {
+		return client.SendBatchInferenceRequest(ctx, batch)
+	})
+}
+
+// Connect 全てのレプリカに接続し、ヘルスチェックを開始
+// 1つも接続できない場合はエラーを返す
+func (bc *BalancingInferenceClient) Connect(ctx context.Context) error {
+	bc.mu.Lock()
+	backends := slices.Clone(bc.backends)
+	bc.connected = true
+	bc.mu.Unlock()
+
+	healthy := 0
+	for _, b := range backends {
+		if err := b.client.Connect(ctx); err != nil {
+			log.Printf("推論サーバーのレプリカに接続失敗: %s, エラー=%v", b.address, err)
+			continue
+		}
+		bc.setHealthy(b, true)
+		healthy++
+	}
+	if healthy == 0 {
+		return fmt.Errorf("推論サーバーのレプリカに1つも接続できません (%d件)", len(backends))
+	}
+
+	checkCtx, cancel := context.WithCancel(context.Background())
+	bc.mu.Lock()
+	bc.cancel = cancel
+	bc.mu.Unlock()
+	go bc.runHealthChecks(checkCtx)
+
+	log.Printf("推論サーバーの負荷分散を開始: 方式=%s, レプリカ=%d/%d", bc.config.Policy, healthy, len(backends))
+	return nil
+}
+
+// Disconnect ヘルスチェックを停止し、全てのレプリカから切断
+func (bc *BalancingInferenceClient) Disconnect() error {
+	bc.mu.Lock()
+	backends := slices.Clone(bc.backends)
+	bc.connected = false
+	if bc.cancel != nil {
+		bc.cancel()
+		bc.cancel = nil
+	}
+	bc.mu.Unlock()
+
+	for _, b := range backends {
+		if err := b.client.Disconnect(); err != nil {
+			log.Printf("推論サーバーのレプリカから切断失敗: %s, エラー=%v", b.address, err)
+		}
+	}
+	return nil
+}
+
+// IsConnected 振り分け可能なレプリカがあるか
+func (bc *BalancingInferenceClient) IsConnected() bool {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	for _, b := range bc.backends {
+		if b.healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// GetServerStatus 振り分け可能なレプリカがあれば connected
+func (bc *BalancingInferenceClient) GetServerStatus() (string, error) {
+	if bc.IsConnected() {
+		return "connected", nil
+	}
+	return "disconnected", nil
+}
+
+// SetEndpoints 推論サーバーのアドレス一覧を差し替え
+// 追加されたレプリカは接続中であれば接続し、削除されたレプリカからは切断する
+func (bc *BalancingInferenceClient) SetEndpoints(ctx context.Context, addresses []string) error {
+	if len(addresses) == 0 {
+		return fmt.Errorf("%w: 推論サーバーのアドレスがありません", model.ErrInvalidLoadBalancingConfig)
+	}
+
+	bc.mu.Lock()
+	current := make(map[string]*backend, len(bc.backends))
+	for _, b := range bc.backends {
+		current[b.address] = b
+	}
+	backends, added := bc.buildBackends(addresses, current)
+	var removed []*backend
+	for _, b := range current {
+		if !slices.Contains(backends, b) {
+			removed = append(removed, b)
+		}
+	}
+	bc.backends = backends
+	bc.ring = buildRing(backends)
+	connected := bc.connected
+	bc.mu.Unlock()
+
+	for _, b := range removed {
+		log.Printf("推論サーバーのレプリカを削除: %s", b.address)
+		if err := b.client.Disconnect(); err != nil {
+			log.Printf("推論サーバーのレプリカから切断失敗: %s, エラー=%v", b.address, err)
+		}
+	}
+	for _, b := range added {
+		log.Printf("推論サーバーのレプリカを追加: %s", b.address)
+		if connected {
+			bc.check(ctx, b)
+		}
+	}
+	return nil
+}
+
+// do レプリカを選んで送信し、結果をレプリカの状態に反映
+func (bc *BalancingInferenceClient) do(ctx context.Context, clientID string, send func(context.Context, interfaces.InferenceClient) (*model.InferenceResponse, error)) (*model.InferenceResponse, error) {
+	b, err := bc.pick(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	b.outstanding.Add(1)
+	response, err := send(ctx, b.client)
+	b.outstanding.Add(-1)
+
+	bc.report(ctx, b, err)
+	return response, err
+}
+
+// pick 負荷分散の方式に従って振り分け先のレプリカを選択
+// 同じリクエストの複製は、正常なレプリカが他にあれば送信済みのレプリカ以外を選ぶ
+func (bc *BalancingInferenceClient) pick(ctx context.Context, clientID string) (*backend, error) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	r := routeFrom(ctx)
+	var used []*backend
+	if r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		used = r.used
+	}
+
+	b := bc.pickExcluding(clientID, used)
+	if b == nil && len(used) > 0 {
+		b = bc.pickExcluding(clientID, nil)
+	}
+	if b == nil {
+		return nil, bc.unavailable()
+	}
+	if r != nil {
+		r.used = append(r.used, b)
+	}
+	return b, nil
+}
+
+// pickExcluding excluded 以外の正常なレプリカから選択（見つからない場合はnil）
+func (bc *BalancingInferenceClient) pickExcluding(clientID string, excluded []*backend) *backend {
+	if bc.config.Policy == model.LoadBalancingConsistentHash {
+		return bc.pickByHash(clientID, excluded)
+	}
+
+	healthy := make([]*backend, 0, len(bc.backends))
+	for _, b := range bc.backends {
+		if b.healthy && !slices.Contains(excluded, b) {
+			healthy = append(healthy, b)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+
+	start := int(bc.next.Add(1) % uint64(len(healthy)))
+	if bc.config.Policy == model.LoadBalancingRoundRobin {
+		return healthy[start]
+	}
+
+	// 送信中のリクエストが同数の場合はラウンドロビンの順で選ぶ
+	picked := healthy[start]
+	for i := 1; i < len(healthy); i++ {
+		b := healthy[(start+i)%len(healthy)]
+		if b.outstanding.Load() < picked.outstanding.Load() {
+			picked = b
+		}
+	}
+	return picked
+}
+
+// pickByHash セッションIDのハッシュからリング上で最初に見つかった正常なレプリカを選択
+// レプリカが外れた場合も、そのレプリカに割り当てられていたセッションだけが移動する
+func (bc *BalancingInferenceClient) pickByHash(clientID string, excluded []*backend) *backend {
+	if len(bc.ring) == 0 {
+		return nil
+	}
+	hash := hashKey(clientID)
+	start := sort.Search(len(bc.ring), func(i int) bool { return bc.ring[i].hash >= hash })
+	for i := 0; i < len(bc.ring); i++ {
+		point := bc.ring[(start+i)%len(bc.ring)]
+		if point.backend.healthy && !slices.Contains(excluded, point.backend) {
+			return point.backend
+		}
+	}
+	return nil
+}
+
+// unavailable 振り分け可能なレプリカがない場合のエラー
+func (bc *BalancingInferenceClient) unavailable() error {
+	return &model.InferenceUnavailableError{
+		Reason:     "正常な推論サーバーのレプリカがありません",
+		RetryAfter: bc.config.HealthCheckInterval,
+	}
+}
+
+// report 送信結果をレプリカの状態に反映
+// 連続失敗が閾値に達したレプリカを振り分け対象から外す（応答がなく期限切れになった送信も失敗として数える）
+// 外したレプリカは除外時間が経過するまでヘルスチェックに成功しても戻さず、成功しないまま外れる度に除外時間を延長する
+func (bc *BalancingInferenceClient) report(ctx context.Context, b *backend, err error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		b.ejections = 0
+		return
+	}
+	if canceledByCaller(ctx) || !isBackendFailure(err) {
+		return
+	}
+	b.failures++
+	if b.healthy && b.failures >= bc.config.EjectionThreshold {
+		b.healthy = false
+		b.ejections++
+		duration := bc.config.EjectionDuration * time.Duration(min(b.ejections, maxEjectionMultiplier))
+		b.ejectedUntil = bc.now().Add(duration)
+		log.Printf("推論サーバーのレプリカを振り分け対象から除外: %s, 連続失敗=%d, 除外時間=%s, エラー=%v", b.address, b.failures, duration, err)
+	}
+}
+
+// runHealthChecks 定期的に全てのレプリカのヘルスチェックを行う
+func (bc *BalancingInferenceClient) runHealthChecks(ctx context.Context) {
+	ticker := time.NewTicker(bc.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			bc.mu.RLock()
+			backends := slices.Clone(bc.backends)
+			bc.mu.RUnlock()
+			for _, b := range backends {
+				bc.check(ctx, b)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check レプリカのヘルスチェックを行い、結果を振り分け対象に反映
+// 切断されている場合は再接続を試みる。連続失敗で外したレプリカは除外時間が経過するまで確認しない
+func (bc *BalancingInferenceClient) check(ctx context.Context, b *backend) {
+	bc.mu.RLock()
+	ejected := !b.healthy && bc.now().Before(b.ejectedUntil)
+	bc.mu.RUnlock()
+	if ejected {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bc.config.HealthCheckInterval)
+	defer cancel()
+
+	if !b.client.IsConnected() {
+		if err := b.client.Connect(ctx); err != nil {
+			bc.setHealthy(b, false)
+			return
+		}
+	}
+	status, err := b.client.GetServerStatus()
+	bc.setHealthy(b, err == nil && status != "disconnected")
+}
+
+// setHealthy レプリカを振り分け対象に戻す、または外す
+func (bc *BalancingInferenceClient) setHealthy(b *backend, healthy bool) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if b.healthy == healthy {
+		return
+	}
+	b.healthy = healthy
+	b.failures = 0
+	if healthy {
+		log.Printf("推論サーバーのレプリカを振り分け対象に復帰: %s", b.address)
+	} else {
+		log.Printf("推論サーバーのレプリカがヘルスチェックに失敗: %s", b.address)
+	}
+}
+
+// buildBackends アドレス一覧から重複を除いたレプリカをアドレス順に作成
+// current にあるアドレスは既存のレプリカを使い、新たに作成したレプリカを added として返す
+func (bc *BalancingInferenceClient) buildBackends(addresses []string, current map[string]*backend) (backends, added []*backend) {
+	addresses = slices.Clone(addresses)
+	slices.Sort(addresses)
+	addresses = slices.Compact(addresses)
+
+	backends = make([]*backend, len(addresses))
+	for i, address := range addresses {
+		if existing, ok := current[address]; ok {
+			backends[i] = existing
+			continue
+		}
+		backends[i] = &backend{
+			address: address,
+			client:  bc.factory(address),
+		}
+		added = append(added, backends[i])
+	}
+	return backends, added
+}
+
+// buildRing レプリカ毎に仮想ノードを配置したコンシステントハッシュのリングを作成
+func buildRing(backends []*backend) []ringPoint {
+	ring := make([]ringPoint, 0, len(backends)*ringReplicas)
+	for _, b := range backends {
+		for i := 0; i < ringReplicas; i++ {
+			ring = append(ring, ringPoint{
+				hash:    hashKey(b.address + "#" + strconv.Itoa(i)),
+				backend: b,
+			})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+	return ring
+}
+
+// hashKey FNV-1aによる64ビットハッシュ
+// 末尾だけが異なるキー（仮想ノード）がリング上で偏らないよう、最後にビットを撹拌する
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// routeKey 1つのリクエストの送信先を記録するコンテキストのキー
+type routeKey struct{}
+
+// route 1つのリクエスト（複製を含む）の送信先のレプリカ
+// 複製を別のレプリカに送るため、送信済みのレプリカを振り分け先から外す
+type route struct {
+	mu   sync.Mutex
+	used []*backend
+}
+
+// withRoute 送信先を記録するコンテキストを作成
+func withRoute(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeKey{}, &route{})
+}
+
+// routeFrom コンテキストから送信先の記録を取得（記録しない場合はnil）
+func routeFrom(ctx context.Context) *route {
+	r, _ := ctx.Value(routeKey{}).(*route)
+	return r
+}
@@ -0,0 +1,141 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/model"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestBalancingClientEjectsFailingReplica(t *testing.T) {
+	tests := []struct {
+		name      string
+		send      func(context.Context) (*model.InferenceResponse, error)
+		ctx       func() (context.Context, context.CancelFunc)
+		wantEject bool
+	}{
+		{
+			name: "応答しない推論サーバーの期限切れ",
+			send: blockingSend,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 5*time.Millisecond)
+			},
+			wantEject: true,
+		},
+		{
+			name:      "推論サーバーの利用不可",
+			send:      failingSend(codes.Unavailable),
+			ctx:       func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wantEject: true,
+		},
+		{
+			name: "呼び出し元の取り消し",
+			send: blockingSend,
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(5*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantEject: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := model.DefaultLoadBalancingConfig()
+			config.HealthCheckInterval = time.Hour // ヘルスチェックはテストから呼び出す
+			bc, err := NewBalancingInferenceClient([]string{"replica-1"}, func(string) interfaces.InferenceClient {
+				return &fakeClient{send: tt.send}
+			}, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := bc.Connect(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer bc.Disconnect()
+
+			for i := 0; i < config.EjectionThreshold; i++ {
+				ctx, cancel := tt.ctx()
+				bc.SendInferenceRequest(ctx, &model.InferenceRequest{ClientID: "client"})
+				cancel()
+			}
+
+			if ejected := !bc.IsConnected(); ejected != tt.wantEject {
+				t.Fatalf("除外されたか = %t（期待値 %t）", ejected, tt.wantEject)
+			}
+			if tt.wantEject {
+				_, err := bc.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "client"})
+				if !errors.Is(err, model.ErrInferenceUnavailable) {
+					t.Fatalf("除外後のエラー = %v, ErrInferenceUnavailable を期待", err)
+				}
+			}
+		})
+	}
+}
+
+func TestBalancingClientReadmitsAfterEjectionDuration(t *testing.T) {
+	config := model.DefaultLoadBalancingConfig()
+	config.HealthCheckInterval = time.Hour
+	replica := &fakeClient{send: failingSend(codes.Unavailable)}
+	bc, err := NewBalancingInferenceClient([]string{"replica-1"}, func(string) interfaces.InferenceClient {
+		return replica
+	}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	bc.now = func() time.Time { return now }
+	if err := bc.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Disconnect()
+
+	eject := func() {
+		for i := 0; i < config.EjectionThreshold; i++ {
+			bc.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "client"})
+		}
+		if bc.IsConnected() {
+			t.Fatal("連続失敗したレプリカが除外されていません")
+		}
+	}
+
+	steps := []struct {
+		name    string
+		elapsed time.Duration // 除外からの経過時間
+		want    bool          // ヘルスチェック後に振り分け対象か
+	}{
+		{"除外直後はヘルスチェックに成功しても戻さない", 0, false},
+		{"除外時間の経過前は戻さない", config.EjectionDuration - time.Second, false},
+		{"除外時間の経過後は戻す", config.EjectionDuration, true},
+	}
+
+	eject()
+	ejectedAt := now
+	for _, step := range steps {
+		now = ejectedAt.Add(step.elapsed)
+		bc.check(context.Background(), bc.backends[0])
+		if got := bc.IsConnected(); got != step.want {
+			t.Fatalf("%s: 振り分け対象か = %t（期待値 %t）", step.name, got, step.want)
+		}
+	}
+
+	// 成功しないまま再び外れた場合は除外時間を延長する
+	eject()
+	ejectedAt = now
+	now = ejectedAt.Add(config.EjectionDuration)
+	bc.check(context.Background(), bc.backends[0])
+	if bc.IsConnected() {
+		t.Fatal("2回目の除外が延長されていません")
+	}
+	now = ejectedAt.Add(2 * config.EjectionDuration)
+	bc.check(context.Background(), bc.backends[0])
+	if !bc.IsConnected() {
+		t.Fatal("延長した除外時間の経過後に戻されていません")
+	}
+}
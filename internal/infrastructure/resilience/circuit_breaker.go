@@ -0,0 +1,182 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/model"
+
+	"google.golang.org/grpc/codes"
+)
+
+// CircuitBreakerClient 推論サーバーの障害時に送信を打ち切るInferenceClientのデコレーター
+// 連続失敗で開き、OpenDuration 経過後は半開状態で少数の試行リクエストを通して回復を確認する
+type CircuitBreakerClient struct {
+	interfaces.InferenceClient
+	config model.CircuitBreakerConfig
+
+	mu        sync.Mutex
+	state     model.CircuitState
+	failures  int       // 閉状態での連続失敗回数
+	successes int       // 半開状態での試行リクエストの成功回数
+	probes    int       // 半開状態で送信中の試行リクエスト数
+	openedAt  time.Time // 開いた時刻
+	now       func() time.Time
+}
+
+// NewCircuitBreakerClient サーキットブレーカー設定を検証してInferenceClientをラップ
+func NewCircuitBreakerClient(inner interfaces.InferenceClient, config model.CircuitBreakerConfig) (interfaces.InferenceClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &CircuitBreakerClient{
+		InferenceClient: inner,
+		config:          config,
+		state:           model.CircuitClosed,
+		now:             time.Now,
+	}, nil
+}
+
+// SendInferenceRequest ブレーカーが許可する場合のみ推論リクエストを送信
+func (cb *CircuitBreakerClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	return cb.do(ctx, func(ctx context.Context) (*model.InferenceResponse, error) {
+		return cb.InferenceClient.SendInferenceRequest(ctx, request)
+	})
+}
+
+// SendBatchInferenceRequest ブレーカーが許可する場合のみバッチ推論リクエストを送信
+func (cb *CircuitBreakerClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	return cb.do(ctx, func(ctx context.Context) (*model.InferenceResponse, error) {
+		return cb.InferenceClient.SendBatchInferenceRequest(ctx, batch)
+	})
+}
+
+// State サーキットブレーカーの現在の状態を取得
+func (cb *CircuitBreakerClient) State() model.CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.currentState()
+}
+
+// do 送信の可否を判定し、結果をブレーカーの状態に反映
+func (cb *CircuitBreakerClient) do(ctx context.Context, send func(context.Context) (*model.InferenceResponse, error)) (*model.InferenceResponse, error) {
+	probe, err := cb.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := send(ctx)
+	cb.record(ctx, probe, err)
+	return response, err
+}
+
+// acquire 送信を許可するか判定（半開状態の試行リクエストの場合は probe=true）
+func (cb *CircuitBreakerClient) acquire() (probe bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState() {
+	case model.CircuitOpen:
+		return false, &model.InferenceUnavailableError{
+			Reason:     "サーキットブレーカーが開いています",
+			RetryAfter: cb.openedAt.Add(cb.config.OpenDuration).Sub(cb.now()),
+		}
+	case model.CircuitHalfOpen:
+		if cb.probes >= cb.config.HalfOpenProbes {
+			return false, &model.InferenceUnavailableError{
+				Reason:     "回復を確認中です",
+				RetryAfter: cb.config.OpenDuration,
+			}
+		}
+		cb.probes++
+		return true, nil
+	}
+	return false, nil
+}
+
+// record 送信結果をブレーカーの状態に反映
+// 呼び出し元の取り消しやリクエスト自体の誤りは推論サーバーの障害として数えない
+// 期限切れは推論サーバーが応答しない障害として数える
+func (cb *CircuitBreakerClient) record(ctx context.Context, probe bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	failed := err != nil && !canceledByCaller(ctx) && isBackendFailure(err)
+	if probe {
+		cb.probes--
+		if cb.state != model.CircuitHalfOpen {
+			return
+		}
+		if failed {
+			cb.trip("試行リクエストが失敗")
+			return
+		}
+		if err == nil {
+			cb.successes++
+			if cb.successes >= cb.config.SuccessThreshold {
+				cb.transition(model.CircuitClosed)
+			}
+		}
+		return
+	}
+
+	if cb.state != model.CircuitClosed {
+		return
+	}
+	if !failed {
+		if err == nil {
+			cb.failures = 0
+		}
+		return
+	}
+	cb.failures++
+	if cb.failures >= cb.config.FailureThreshold {
+		cb.trip("連続失敗が閾値に到達")
+	}
+}
+
+// currentState 開いてから OpenDuration が経過していれば半開状態に移行して状態を返す
+func (cb *CircuitBreakerClient) currentState() model.CircuitState {
+	if cb.state == model.CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.config.OpenDuration {
+		cb.transition(model.CircuitHalfOpen)
+	}
+	return cb.state
+}
+
+// trip ブレーカーを開く
+func (cb *CircuitBreakerClient) trip(reason string) {
+	cb.openedAt = cb.now()
+	log.Printf("推論サーバーのサーキットブレーカーを開きます: %s, %s後に試行リクエストを許可", reason, cb.config.OpenDuration)
+	cb.transition(model.CircuitOpen)
+}
+
+// transition 状態を変更してカウンターをリセット
+func (cb *CircuitBreakerClient) transition(state model.CircuitState) {
+	log.Printf("推論サーバーのサーキットブレーカー: %s → %s", cb.state, state)
+	cb.state = state
+	cb.failures = 0
+	cb.successes = 0
+}
+
+// canceledByCaller 呼び出し元が送信を取り消したか（期限切れは含まない）
+func canceledByCaller(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.Canceled)
+}
+
+// isBackendFailure 推論サーバーの障害とみなすエラーか判定
+// リクエスト自体の誤りを示すステータスコードは障害として数えない
+func isBackendFailure(err error) bool {
+	if errors.Is(err, model.ErrInferenceUnavailable) {
+		return false
+	}
+	switch statusCode(err) {
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.FailedPrecondition, codes.OutOfRange, codes.Unimplemented, codes.Unauthenticated:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeClient 送信毎に send の結果を返すテスト用の推論クライアント
+type fakeClient struct {
+	send      func(ctx context.Context) (*model.InferenceResponse, error)
+	connected bool
+	status    string
+}
+
+func (f *fakeClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	return f.send(ctx)
+}
+
+func (f *fakeClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	return f.send(ctx)
+}
+
+func (f *fakeClient) Connect(ctx context.Context) error { f.connected = true; return nil }
+func (f *fakeClient) Disconnect() error                 { f.connected = false; return nil }
+func (f *fakeClient) IsConnected() bool                 { return f.connected }
+
+func (f *fakeClient) GetServerStatus() (string, error) {
+	if f.status == "" {
+		return "connected", nil
+	}
+	return f.status, nil
+}
+
+// blockingSend 応答せずにコンテキストの終了まで待機する推論サーバー
+func blockingSend(ctx context.Context) (*model.InferenceResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// failingSend 常に指定したステータスコードで失敗する推論サーバー
+func failingSend(code codes.Code) func(context.Context) (*model.InferenceResponse, error) {
+	return func(context.Context) (*model.InferenceResponse, error) {
+		return nil, status.Error(code, "失敗")
+	}
+}
+
+func TestCircuitBreakerOpensOnBackendFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		send     func(context.Context) (*model.InferenceResponse, error)
+		ctx      func() (context.Context, context.CancelFunc)
+		wantOpen bool
+	}{
+		{
+			name: "応答しない推論サーバーの期限切れ",
+			send: blockingSend,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 5*time.Millisecond)
+			},
+			wantOpen: true,
+		},
+		{
+			name:     "推論サーバーの利用不可",
+			send:     failingSend(codes.Unavailable),
+			ctx:      func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wantOpen: true,
+		},
+		{
+			name: "呼び出し元の取り消し",
+			send: blockingSend,
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(5*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantOpen: false,
+		},
+		{
+			name:     "リクエスト自体の誤り",
+			send:     failingSend(codes.InvalidArgument),
+			ctx:      func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wantOpen: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := model.DefaultCircuitBreakerConfig()
+			client, err := NewCircuitBreakerClient(&fakeClient{send: tt.send}, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cb := client.(*CircuitBreakerClient)
+
+			for i := 0; i < config.FailureThreshold; i++ {
+				ctx, cancel := tt.ctx()
+				if _, err := cb.SendInferenceRequest(ctx, &model.InferenceRequest{ClientID: "client"}); err == nil {
+					t.Fatalf("送信 %d: エラーになるはずが成功しました", i)
+				}
+				cancel()
+			}
+
+			if got := cb.State() == model.CircuitOpen; got != tt.wantOpen {
+				t.Fatalf("状態 = %s, 開いているか = %t（期待値 %t）", cb.State(), got, tt.wantOpen)
+			}
+			if tt.wantOpen {
+				_, err := cb.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "client"})
+				if !errors.Is(err, model.ErrInferenceUnavailable) {
+					t.Fatalf("開いたブレーカーのエラー = %v, ErrInferenceUnavailable を期待", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package resilience
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// LoadEndpointsFile 推論サーバーのアドレス一覧をファイルから読み込み
+// 1行に1アドレス（カンマ区切りも可）、空行と # 以降はコメントとして無視する
+func LoadEndpointsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("推論サーバー一覧ファイル読み込み失敗: %w", err)
+	}
+	defer file.Close()
+
+	var addresses []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		for _, address := range strings.Split(line, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("推論サーバー一覧ファイル読み込み失敗: %w", err)
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("推論サーバー一覧ファイルにアドレスがありません: %s", path)
+	}
+	return addresses, nil
+}
+
+// WatchEndpointsFile 推論サーバー一覧ファイルの変更を定期的に確認して振り分け先を差し替えるgoroutineを開始
+// 読み込みに失敗した場合は現在の振り分け先を使い続ける
+func (bc *BalancingInferenceClient) WatchEndpointsFile(ctx context.Context, path string, interval time.Duration) {
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil || info.ModTime().Equal(modTime) {
+					continue
+				}
+				modTime = info.ModTime()
+
+				addresses, err := LoadEndpointsFile(path)
+				if err == nil {
+					err = bc.SetEndpoints(ctx, addresses)
+				}
+				if err != nil {
+					log.Printf("推論サーバー一覧の再読み込み失敗: %v", err)
+					continue
+				}
+				log.Printf("推論サーバー一覧を再読み込みしました: %s (%d件)", path, len(addresses))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,216 @@
+package resilience
+
+import (
+	"context"
+	"log"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/model"
+)
+
+// ヘッジの定数
+const (
+	hedgeMinSamples   = 20 // パーセンタイルから待機時間を求めるのに必要なレイテンシの計測数
+	hedgeDelayRefresh = 50 // 待機時間を再計算するレイテンシの計測間隔
+	hedgeBudgetBurst  = 10 // 複製送信の予算の上限（連続して複製を送れる数）
+)
+
+// hedgeResult 1回の送信の結果
+type hedgeResult struct {
+	response *model.InferenceResponse
+	err      error
+	elapsed  time.Duration
+	hedge    bool
+}
+
+// HedgingClient 遅いリクエストの複製を別のレプリカに送るInferenceClientのデコレーター
+// 直近のレイテンシのパーセンタイルを過ぎても応答がない場合に複製を送り、先に成功した結果を返して残りを取り消す
+// 複製の数はリクエスト毎に MaxHedgeRatio ずつ貯まる予算の範囲に抑える
+type HedgingClient struct {
+	interfaces.InferenceClient
+	config model.HedgingConfig
+
+	mu        sync.Mutex
+	latencies []time.Duration // 直近の成功したリクエストのレイテンシ（リングバッファ）
+	next      int             // 次に書き込む位置
+	observed  int             // 計測したレイテンシの総数
+	delay     time.Duration   // 複製を送るまでの待機時間
+	budget    float64         // 複製送信の予算
+
+	requests        atomic.Uint64
+	hedged          atomic.Uint64
+	hedgeWins       atomic.Uint64
+	budgetExhausted atomic.Uint64
+}
+
+// NewHedgingClient ヘッジ設定を検証してInferenceClientをラップ
+func NewHedgingClient(inner interfaces.InferenceClient, config model.HedgingConfig) (*HedgingClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &HedgingClient{
+		InferenceClient: inner,
+		config:          config,
+		latencies:       make([]time.Duration, 0, config.WindowSize),
+		delay:           config.InitialDelay,
+		budget:          hedgeBudgetBurst,
+	}, nil
+}
+
+// SendInferenceRequest 応答が遅い場合は複製を送信し、先に成功した推論結果を返す
+func (hc *HedgingClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	return hc.do(ctx, request.ClientID, func(ctx context.Context) (*model.InferenceResponse, error) {
+		return hc.InferenceClient.SendInferenceRequest(ctx, request)
+	})
+}
+
+// SendBatchInferenceRequest 応答が遅い場合は複製を送信し、先に成功したバッチ推論結果を返す
+func (hc *HedgingClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	return hc.do(ctx, batch.ClientID, func(ctx context.Context) (*model.InferenceResponse, error) {
+		return hc.InferenceClient.SendBatchInferenceRequest(ctx, batch)
+	})
+}
+
+// Stats ヘッジの統計を取得
+func (hc *HedgingClient) Stats() model.HedgingStats {
+	hc.mu.Lock()
+	delay := hc.delay
+	hc.mu.Unlock()
+
+	stats := model.HedgingStats{
+		Requests:        hc.requests.Load(),
+		Hedged:          hc.hedged.Load(),
+		HedgeWins:       hc.hedgeWins.Load(),
+		BudgetExhausted: hc.budgetExhausted.Load(),
+		DelayMs:         delay.Milliseconds(),
+	}
+	if stats.Requests > 0 {
+		stats.HedgeRatio = float64(stats.Hedged) / float64(stats.Requests)
+	}
+	return stats
+}
+
+// do 最初の送信を行い、待機時間を過ぎても応答がなければ複製を送信
+// 複製前に最初の送信が失敗した場合は複製せずにエラーを返す（再送はリトライポリシーに任せる）
+func (hc *HedgingClient) do(ctx context.Context, clientID string, send func(context.Context) (*model.InferenceResponse, error)) (*model.InferenceResponse, error) {
+	hc.requests.Add(1)
+	delay := hc.deposit()
+
+	// 先に成功した時点で残りの送信を取り消す
+	ctx, cancel := context.WithCancel(withRoute(ctx))
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	launch := func(hedge bool) {
+		go func() {
+			start := time.Now()
+			response, err := send(ctx)
+			results <- hedgeResult{response: response, err: err, elapsed: time.Since(start), hedge: hedge}
+		}()
+	}
+	launch(false)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	pending := 1
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			if !hc.withdraw() {
+				hc.budgetExhausted.Add(1)
+				continue
+			}
+			pending++
+			hc.hedged.Add(1)
+			log.Printf("推論リクエストの複製を送信: クライアント=%s, 待機=%s", clientID, delay)
+			launch(true)
+
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				hc.observe(result.elapsed)
+				if result.hedge {
+					hc.hedgeWins.Add(1)
+				}
+				return result.response, nil
+			}
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			// 複製前の失敗、または全ての送信が失敗した場合
+			if pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
+
+// deposit リクエスト1件分の予算を積み立て、現在の待機時間を返す
+func (hc *HedgingClient) deposit() time.Duration {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	hc.budget = math.Min(hc.budget+hc.config.MaxHedgeRatio, hedgeBudgetBurst)
+	return hc.delay
+}
+
+// withdraw 予算があれば複製1件分を引き出す
+func (hc *HedgingClient) withdraw() bool {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	if hc.budget < 1 {
+		return false
+	}
+	hc.budget--
+	return true
+}
+
+// observe 成功したリクエストのレイテンシを記録し、定期的に待機時間を再計算
+func (hc *HedgingClient) observe(elapsed time.Duration) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	if len(hc.latencies) < hc.config.WindowSize {
+		hc.latencies = append(hc.latencies, elapsed)
+	} else {
+		hc.latencies[hc.next] = elapsed
+	}
+	hc.next = (hc.next + 1) % hc.config.WindowSize
+	hc.observed++
+
+	if len(hc.latencies) < hedgeMinSamples || hc.observed%hedgeDelayRefresh != 0 && hc.observed != hedgeMinSamples {
+		return
+	}
+	sorted := slices.Clone(hc.latencies)
+	slices.Sort(sorted)
+	index := int(math.Ceil(hc.config.Percentile/100*float64(len(sorted)))) - 1
+	hc.delay = max(sorted[max(index, 0)], hc.config.MinDelay)
+}
+
+// HedgingGroup 推論モデル毎のHedgingClientの統計をまとめて取得するための集合
+type HedgingGroup []*HedgingClient
+
+// Stats 全てのHedgingClientの統計を合算（待機時間は最も長いもの）
+func (g HedgingGroup) Stats() model.HedgingStats {
+	var total model.HedgingStats
+	for _, hc := range g {
+		stats := hc.Stats()
+		total.Requests += stats.Requests
+		total.Hedged += stats.Hedged
+		total.HedgeWins += stats.HedgeWins
+		total.BudgetExhausted += stats.BudgetExhausted
+		total.DelayMs = max(total.DelayMs, stats.DelayMs)
+	}
+	if total.Requests > 0 {
+		total.HedgeRatio = float64(total.Hedged) / float64(total.Requests)
+	}
+	return total
+}
@@ -0,0 +1,179 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+)
+
+// hedgingConfig テスト用のヘッジ設定
+func hedgingConfig(initialDelay, minDelay time.Duration, maxHedgeRatio float64) model.HedgingConfig {
+	config := model.DefaultHedgingConfig()
+	config.Enabled = true
+	config.InitialDelay = initialDelay
+	config.MinDelay = minDelay
+	config.MaxHedgeRatio = maxHedgeRatio
+	config.WindowSize = hedgeMinSamples
+	return config
+}
+
+// sleepSend latency 後に応答する推論サーバー
+func sleepSend(latency time.Duration) func(context.Context) (*model.InferenceResponse, error) {
+	return func(ctx context.Context) (*model.InferenceResponse, error) {
+		select {
+		case <-time.After(latency):
+			return &model.InferenceResponse{Result: "ok"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func TestHedgingClientHedgesAfterPercentileDelay(t *testing.T) {
+	const latency = 20 * time.Millisecond
+
+	var mu sync.Mutex
+	var starts []time.Time
+	var send func(context.Context) (*model.InferenceResponse, error)
+	inner := &fakeClient{send: func(ctx context.Context) (*model.InferenceResponse, error) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		return send(ctx)
+	}}
+	hc, err := NewHedgingClient(inner, hedgingConfig(2*time.Second, time.Millisecond, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 計測数が揃うまでは InitialDelay で待機し、揃った時点でパーセンタイルから待機時間を求める
+	send = sleepSend(latency)
+	for i := 0; i < hedgeMinSamples; i++ {
+		if _, err := hc.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "session"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := hc.Stats()
+	if stats.Hedged != 0 {
+		t.Fatalf("InitialDelay 内に応答したリクエストを複製しました: %d 件", stats.Hedged)
+	}
+	if delay := time.Duration(stats.DelayMs) * time.Millisecond; delay < latency || delay >= 5*latency {
+		t.Fatalf("待機時間 = %s（計測したレイテンシ %s 程度を期待）", delay, latency)
+	}
+
+	tests := []struct {
+		name      string
+		send      func(context.Context) (*model.InferenceResponse, error)
+		wantHedge bool
+	}{
+		{name: "待機時間内に応答", send: sleepSend(latency / 4)},
+		{name: "待機時間を過ぎても応答しない", send: blockingSend, wantHedge: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			starts = nil
+			mu.Unlock()
+
+			// 最初の送信は tt.send、複製は即座に応答する
+			var calls atomic.Int32
+			send = func(ctx context.Context) (*model.InferenceResponse, error) {
+				if calls.Add(1) == 1 {
+					return tt.send(ctx)
+				}
+				return &model.InferenceResponse{Result: "hedge"}, nil
+			}
+			start := time.Now()
+			if _, err := hc.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "session"}); err != nil {
+				t.Fatal(err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if hedged := len(starts) == 2; hedged != tt.wantHedge {
+				t.Fatalf("送信回数 = %d（複製を期待: %v）", len(starts), tt.wantHedge)
+			}
+			if tt.wantHedge {
+				if offset := starts[1].Sub(start); offset < latency || offset >= 5*latency {
+					t.Fatalf("複製を送るまでの時間 = %s（待機時間 %s 程度を期待）", offset, latency)
+				}
+			}
+		})
+	}
+}
+
+func TestHedgingClientCancelsLosingRequest(t *testing.T) {
+	loserErr := make(chan error, 1)
+	var calls atomic.Int32
+	inner := &fakeClient{send: func(ctx context.Context) (*model.InferenceResponse, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			loserErr <- ctx.Err()
+			return nil, ctx.Err()
+		}
+		return &model.InferenceResponse{Result: "hedge"}, nil
+	}}
+	hc, err := NewHedgingClient(inner, hedgingConfig(10*time.Millisecond, time.Millisecond, 0.1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := hc.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "session"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Result != "hedge" {
+		t.Fatalf("推論結果 = %q（複製の結果を期待）", response.Result)
+	}
+
+	// 先に成功した時点で最初の送信が取り消されること
+	select {
+	case err := <-loserErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("最初の送信のエラー = %v（%v を期待）", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("複製が成功した後も最初の送信が取り消されません")
+	}
+	if stats := hc.Stats(); stats.Hedged != 1 || stats.HedgeWins != 1 {
+		t.Fatalf("統計 = %+v（複製1件・複製の成功1件を期待）", stats)
+	}
+}
+
+func TestHedgingClientBudgetLimitsHedges(t *testing.T) {
+	const (
+		requests = 50
+		ratio    = 0.1
+	)
+
+	// 全ての送信が応答しないため、全てのリクエストが待機時間を過ぎて複製を試みる
+	inner := &fakeClient{send: blockingSend}
+	hc, err := NewHedgingClient(inner, hedgingConfig(time.Millisecond, time.Millisecond, ratio))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < requests; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := hc.SendInferenceRequest(ctx, &model.InferenceRequest{ClientID: "session"})
+		cancel()
+		if err == nil {
+			t.Fatal("応答しない推論サーバーへのリクエストが成功しました")
+		}
+	}
+
+	stats := hc.Stats()
+	maxHedged := uint64(hedgeBudgetBurst + ratio*requests)
+	if stats.Hedged < hedgeBudgetBurst || stats.Hedged > maxHedged {
+		t.Fatalf("複製数 = %d（%d〜%d を期待）", stats.Hedged, hedgeBudgetBurst, maxHedged)
+	}
+	if stats.BudgetExhausted < requests-maxHedged {
+		t.Fatalf("予算切れで複製しなかったリクエスト = %d（%d 以上を期待）", stats.BudgetExhausted, requests-maxHedged)
+	}
+}
@@ -0,0 +1,134 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/model"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryingClient リトライポリシーを適用するInferenceClientのデコレーター
+// 送信前にバッチの冪等キーを付与し、再送でも同じキーを送ることで推論サーバーが重複を排除できるようにする
+type RetryingClient struct {
+	interfaces.InferenceClient
+	policy    model.RetryPolicy
+	retryable map[codes.Code]bool
+}
+
+// NewRetryingClient リトライポリシーを検証してInferenceClientをラップ
+func NewRetryingClient(inner interfaces.InferenceClient, policy model.RetryPolicy) (interfaces.InferenceClient, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	retryable := make(map[codes.Code]bool, len(policy.RetryableCodes))
+	for _, name := range policy.RetryableCodes {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(`"` + name + `"`)); err != nil {
+			return nil, fmt.Errorf("%w: %v", model.ErrInvalidRetryPolicy, err)
+		}
+		retryable[code] = true
+	}
+
+	return &RetryingClient{
+		InferenceClient: inner,
+		policy:          policy,
+		retryable:       retryable,
+	}, nil
+}
+
+// SendInferenceRequest 冪等キーを付与し、リトライポリシーに従って推論リクエストを送信
+func (rc *RetryingClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	if request.IdempotencyKey == "" {
+		request.IdempotencyKey = uuid.New().String()
+	}
+	return rc.do(ctx, request.ClientID, request.IdempotencyKey, func(ctx context.Context) (*model.InferenceResponse, error) {
+		return rc.InferenceClient.SendInferenceRequest(ctx, request)
+	})
+}
+
+// SendBatchInferenceRequest 冪等キーを付与し、リトライポリシーに従ってバッチ推論リクエストを送信
+func (rc *RetryingClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	if batch.IdempotencyKey == "" {
+		batch.IdempotencyKey = uuid.New().String()
+	}
+	return rc.do(ctx, batch.ClientID, batch.IdempotencyKey, func(ctx context.Context) (*model.InferenceResponse, error) {
+		return rc.InferenceClient.SendBatchInferenceRequest(ctx, batch)
+	})
+}
+
+// do リトライ対象のエラーの間、バックオフを挟んで送信を繰り返す
+// 初回は呼び出し元のタイムアウトのみで送信し、ポリシーの Deadline は初回の失敗以降のリトライ（待機を含む）に適用する
+// 呼び出し元の取り消し・タイムアウトでは直ちに中断する
+func (rc *RetryingClient) do(ctx context.Context, clientID, key string, send func(context.Context) (*model.InferenceResponse, error)) (*model.InferenceResponse, error) {
+	attemptCtx := ctx
+	for attempt := 1; ; attempt++ {
+		response, err := send(attemptCtx)
+		if err == nil {
+			return response, nil
+		}
+
+		// 呼び出し元の取り消し、Deadline 超過後、サーキットブレーカーによる打ち切りは再送しない
+		if attemptCtx.Err() != nil || errors.Is(err, model.ErrInferenceUnavailable) {
+			return nil, err
+		}
+		code := statusCode(err)
+		if !rc.retryable[code] {
+			return nil, err
+		}
+		if attempt >= rc.policy.MaxAttempts {
+			return nil, fmt.Errorf("推論リクエストが %d 回失敗しました: %w", attempt, err)
+		}
+
+		if attempt == 1 && rc.policy.Deadline > 0 {
+			var cancel context.CancelFunc
+			attemptCtx, cancel = context.WithTimeout(ctx, rc.policy.Deadline)
+			defer cancel()
+		}
+
+		backoff := rc.jitter(rc.policy.Backoff(attempt))
+		log.Printf("推論リクエストをリトライ: クライアント=%s, キー=%s, 試行=%d/%d, コード=%s, 待機=%s",
+			clientID, key, attempt+1, rc.policy.MaxAttempts, code, backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-attemptCtx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("推論リクエストのリトライ待機中に中断 (試行=%d): %w", attempt, err)
+		}
+	}
+}
+
+// jitter 待機時間を ±Jitter の割合でランダムに揺らす
+func (rc *RetryingClient) jitter(backoff time.Duration) time.Duration {
+	if rc.policy.Jitter == 0 || backoff <= 0 {
+		return backoff
+	}
+	factor := 1 + rc.policy.Jitter*(2*rand.Float64()-1)
+	return time.Duration(float64(backoff) * factor)
+}
+
+// statusCode エラーのステータスコードを判定
+// gRPCのステータスを持たないタイムアウトは DEADLINE_EXCEEDED、取り消しは CANCELLED、その他は UNKNOWN として扱う
+func statusCode(err error) codes.Code {
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return codes.DeadlineExceeded
+	}
+	if errors.Is(err, context.Canceled) {
+		return codes.Canceled
+	}
+	return codes.Unknown
+}
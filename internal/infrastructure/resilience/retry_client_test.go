@@ -0,0 +1,96 @@
+package resilience
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryingClientDeadline(t *testing.T) {
+	tests := []struct {
+		name          string
+		callerTimeout time.Duration
+		send          func(ctx context.Context, attempt int) (*model.InferenceResponse, error)
+		wantErr       bool
+		wantAttempts  int
+		maxElapsed    time.Duration
+	}{
+		{
+			name:          "初回はDeadlineより長い呼び出し元のタイムアウトで送信",
+			callerTimeout: time.Second,
+			send: func(ctx context.Context, attempt int) (*model.InferenceResponse, error) {
+				select {
+				case <-time.After(150 * time.Millisecond):
+					return &model.InferenceResponse{Result: "ok"}, nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			},
+			wantAttempts: 1,
+			maxElapsed:   time.Second,
+		},
+		{
+			name:          "初回の失敗以降のリトライはDeadlineで打ち切り",
+			callerTimeout: 5 * time.Second,
+			send: func(ctx context.Context, attempt int) (*model.InferenceResponse, error) {
+				if attempt == 1 {
+					return nil, status.Error(codes.Unavailable, "失敗")
+				}
+				return blockingSend(ctx)
+			},
+			wantErr:      true,
+			wantAttempts: 2,
+			maxElapsed:   time.Second,
+		},
+		{
+			name:          "呼び出し元のタイムアウトでは再送しない",
+			callerTimeout: 50 * time.Millisecond,
+			send: func(ctx context.Context, attempt int) (*model.InferenceResponse, error) {
+				return blockingSend(ctx)
+			},
+			wantErr:      true,
+			wantAttempts: 1,
+			maxElapsed:   time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			inner := &fakeClient{send: func(ctx context.Context) (*model.InferenceResponse, error) {
+				attempts++
+				return tt.send(ctx, attempts)
+			}}
+			policy := model.DefaultRetryPolicy()
+			policy.MaxAttempts = 5
+			policy.InitialBackoff = time.Millisecond
+			policy.Jitter = 0
+			policy.Deadline = 50 * time.Millisecond
+			client, err := NewRetryingClient(inner, policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.callerTimeout)
+			defer cancel()
+			start := time.Now()
+			_, err = client.SendBatchInferenceRequest(ctx, &model.AudioBatch{ClientID: "session"})
+			elapsed := time.Since(start)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("エラー = %v（エラーを期待: %v）", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Fatalf("試行回数 = %d（期待値 %d）", attempts, tt.wantAttempts)
+			}
+			if elapsed > tt.maxElapsed {
+				t.Fatalf("所要時間 = %s（上限 %s）", elapsed, tt.maxElapsed)
+			}
+		})
+	}
+}
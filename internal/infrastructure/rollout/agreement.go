@@ -0,0 +1,133 @@
+package rollout
+
+import (
+	"math"
+	"slices"
+
+	"socket_inference/internal/model"
+)
+
+// shadowOutput 比較と記録に使う推論結果の出力
+// 呼び出し元が推論結果を加工する前に取り出す
+type shadowOutput struct {
+	Result     string    `json:"result,omitempty"`
+	Confidence float64   `json:"confidence,omitempty"`
+	TopLabel   string    `json:"top_label,omitempty"`  // classification の最上位ラベル
+	Keywords   []string  `json:"keywords,omitempty"`   // keyword_spotting で検出したキーワード
+	Embedding  []float32 `json:"-"`                    // speaker_embedding の埋め込み（記録には含めない）
+	Dimensions int       `json:"dimensions,omitempty"` // 埋め込みの次元数
+}
+
+// summarize 推論結果から比較用の出力を取り出し（nilの場合はnil）
+func summarize(response *model.InferenceResponse) *shadowOutput {
+	if response == nil {
+		return nil
+	}
+
+	output := &shadowOutput{
+		Result:     response.Result,
+		Confidence: response.Confidence,
+		Embedding:  slices.Clone(response.Embedding),
+		Dimensions: len(response.Embedding),
+	}
+	if len(response.Labels) > 0 {
+		top := response.Labels[0]
+		for _, label := range response.Labels[1:] {
+			if label.Score > top.Score {
+				top = label
+			}
+		}
+		output.TopLabel = top.Label
+	}
+	for _, detection := range response.Detected {
+		output.Keywords = append(output.Keywords, detection.Keyword)
+	}
+	return output
+}
+
+// agreement 2つの出力の一致率（0〜1、1は完全一致）
+// 分類は最上位ラベル、話者埋め込みはコサイン類似度、キーワード検出は検出したキーワードの集合、
+// それ以外は認識テキストの文字単位の編集距離で比較する
+func agreement(a, b *shadowOutput) float64 {
+	switch {
+	case a.TopLabel != "" || b.TopLabel != "":
+		if a.TopLabel == b.TopLabel {
+			return 1
+		}
+		return 0
+	case a.Dimensions > 0 || b.Dimensions > 0:
+		return cosineSimilarity(a.Embedding, b.Embedding)
+	case len(a.Keywords) > 0 || len(b.Keywords) > 0:
+		return jaccard(a.Keywords, b.Keywords)
+	default:
+		return textAgreement(a.Result, b.Result)
+	}
+}
+
+// textAgreement 文字単位の編集距離から求めたテキストの一致率
+func textAgreement(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	// 1行分の編集距離を更新しながら計算
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+// cosineSimilarity 埋め込みのコサイン類似度（次元が異なる場合は0、負の値は0に丸める）
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		if normA == normB {
+			return 1
+		}
+		return 0
+	}
+	return math.Max(dot/math.Sqrt(normA*normB), 0)
+}
+
+// jaccard キーワードの集合のジャカード係数
+func jaccard(a, b []string) float64 {
+	set := make(map[string]int)
+	for _, keyword := range a {
+		set[keyword] |= 1
+	}
+	for _, keyword := range b {
+		set[keyword] |= 2
+	}
+
+	both := 0
+	for _, flags := range set {
+		if flags == 3 {
+			both++
+		}
+	}
+	return float64(both) / float64(len(set))
+}
@@ -0,0 +1,248 @@
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/model"
+)
+
+// exactMatchTolerance 完全一致とみなす一致率の誤差（埋め込みのコサイン類似度の丸め誤差を許容）
+const exactMatchTolerance = 1e-9
+
+// shadowOutcome 1回の推論の結果（比較用に送信直後の出力を保持）
+type shadowOutcome struct {
+	output  *shadowOutput
+	err     error
+	elapsed time.Duration
+}
+
+// shadowRecord 複製元と複製先の出力を並べたオフライン比較用の記録
+type shadowRecord struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Model     string         `json:"model"`
+	Shadow    string         `json:"shadow"`
+	ClientID  string         `json:"client_id"`
+	Sequence  uint64         `json:"sequence"`
+	Task      model.TaskType `json:"task,omitempty"`
+	Primary   recordEntry    `json:"primary"`
+	Candidate recordEntry    `json:"candidate"`
+	Agreement *float64       `json:"agreement,omitempty"` // いずれかが失敗した場合は省略
+}
+
+// recordEntry 記録する片方の出力とレイテンシ
+type recordEntry struct {
+	Output    *shadowOutput `json:"output,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	LatencyMs float64       `json:"latency_ms"`
+}
+
+// ShadowClient 推論リクエストの一部を別の推論モデルに複製するInferenceClientのデコレーター
+// 複製はクライアントへの推論結果に影響せず（待機もしない）、両方の出力と一致率・レイテンシを記録する
+type ShadowClient struct {
+	interfaces.InferenceClient
+	shadow      interfaces.InferenceClient
+	model       string          // 複製元の推論モデル（"名前@バージョン"）
+	shadowModel model.ModelInfo // 複製先の推論モデル（複製したリクエストのモデル・バージョンに設定）
+	config      model.ShadowConfig
+
+	inFlight chan struct{} // 送信中の複製のセマフォ
+	writerMu sync.Mutex
+	writer   io.Writer // 比較用の記録の書き出し先（nilの場合はログ）
+
+	mirrored     atomic.Uint64
+	dropped      atomic.Uint64
+	failed       atomic.Uint64
+	mu           sync.Mutex
+	compared     uint64
+	exactMatches uint64
+	agreementSum float64
+	primarySum   time.Duration
+	shadowSum    time.Duration
+}
+
+// NewShadowClient 推論クライアントをラップし、config.Fraction の割合のリクエストを shadowModel として shadow に複製
+// writer に両方の出力をJSON Linesで書き出す（nilの場合はログに出力）
+func NewShadowClient(primary, shadow interfaces.InferenceClient, modelID string, shadowModel model.ModelInfo, config model.ShadowConfig, writer io.Writer) (*ShadowClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &ShadowClient{
+		InferenceClient: primary,
+		shadow:          shadow,
+		model:           modelID,
+		shadowModel:     shadowModel,
+		config:          config,
+		inFlight:        make(chan struct{}, config.InFlightLimit()),
+		writer:          writer,
+	}, nil
+}
+
+// SendInferenceRequest 推論リクエストを送信し、選ばれた場合は複製先にも送信
+func (sc *ShadowClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	// 下位のクライアントがリクエストに書き込む値（冪等キー等）を共有しないよう複製を先に作る
+	// 推論サーバーを共有する場合もシャドーのバージョンで推論するよう、モデル・バージョンを置き換える
+	mirror := *request
+	mirror.Model = sc.shadowModel.Name
+	mirror.ModelVersion = sc.shadowModel.Version
+	return sc.do(ctx, request.ClientID, request.Sequence, request.Task,
+		func(ctx context.Context) (*model.InferenceResponse, error) {
+			return sc.InferenceClient.SendInferenceRequest(ctx, request)
+		},
+		func(ctx context.Context) (*model.InferenceResponse, error) {
+			return sc.shadow.SendInferenceRequest(ctx, &mirror)
+		})
+}
+
+// SendBatchInferenceRequest バッチ推論リクエストを複製せずに送信
+// バッチは推論モデルを指定できず、推論サーバーを共有するシャドーに複製しても同じバージョンの比較になるため
+func (sc *ShadowClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	return sc.InferenceClient.SendBatchInferenceRequest(ctx, batch)
+}
+
+// Stats シャドーへの複製の統計を取得
+func (sc *ShadowClient) Stats() model.ShadowStats {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	stats := model.ShadowStats{
+		Model:        sc.model,
+		Shadow:       sc.shadowModel.ID(),
+		Mirrored:     sc.mirrored.Load(),
+		Dropped:      sc.dropped.Load(),
+		Failed:       sc.failed.Load(),
+		Compared:     sc.compared,
+		ExactMatches: sc.exactMatches,
+	}
+	if sc.compared > 0 {
+		n := float64(sc.compared)
+		stats.MeanAgreement = sc.agreementSum / n
+		stats.PrimaryLatencyMs = float64(sc.primarySum.Microseconds()) / 1000 / n
+		stats.ShadowLatencyMs = float64(sc.shadowSum.Microseconds()) / 1000 / n
+	}
+	return stats
+}
+
+// do 推論リクエストを送信し、選ばれた場合は同時に複製を送信
+// 複製は送信元の取り消しの影響を受けず、複製先のタイムアウトで打ち切る
+func (sc *ShadowClient) do(ctx context.Context, clientID string, sequence uint64, task model.TaskType, send, sendShadow func(context.Context) (*model.InferenceResponse, error)) (*model.InferenceResponse, error) {
+	if !sc.sample() {
+		return send(ctx)
+	}
+
+	primaryDone := make(chan shadowOutcome, 1)
+	shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sc.config.Timeout())
+	go func() {
+		defer cancel()
+		defer func() { <-sc.inFlight }()
+
+		start := time.Now()
+		response, err := sendShadow(shadowCtx)
+		candidate := shadowOutcome{output: summarize(response), err: err, elapsed: time.Since(start)}
+		sc.compare(clientID, sequence, task, <-primaryDone, candidate)
+	}()
+
+	start := time.Now()
+	response, err := send(ctx)
+	// 推論結果は呼び出し元で加工されるため、返す前に比較用の出力を取り出す
+	primaryDone <- shadowOutcome{output: summarize(response), err: err, elapsed: time.Since(start)}
+	return response, err
+}
+
+// sample 複製するか決定し、複製する場合は送信枠を確保
+func (sc *ShadowClient) sample() bool {
+	if sc.config.Fraction <= 0 || rand.Float64() >= sc.config.Fraction {
+		return false
+	}
+	select {
+	case sc.inFlight <- struct{}{}:
+		sc.mirrored.Add(1)
+		return true
+	default:
+		sc.dropped.Add(1)
+		return false
+	}
+}
+
+// compare 複製元と複製先の出力を比較して統計に加え、両方の出力を記録
+func (sc *ShadowClient) compare(clientID string, sequence uint64, task model.TaskType, primary, candidate shadowOutcome) {
+	record := shadowRecord{
+		Timestamp: time.Now(),
+		Model:     sc.model,
+		Shadow:    sc.shadowModel.ID(),
+		ClientID:  clientID,
+		Sequence:  sequence,
+		Task:      task,
+		Primary:   newRecordEntry(primary),
+		Candidate: newRecordEntry(candidate),
+	}
+
+	if primary.err != nil || candidate.err != nil || primary.output == nil || candidate.output == nil {
+		sc.failed.Add(1)
+	} else {
+		score := agreement(primary.output, candidate.output)
+		record.Agreement = &score
+
+		sc.mu.Lock()
+		sc.compared++
+		sc.agreementSum += score
+		if score >= 1-exactMatchTolerance {
+			sc.exactMatches++
+		}
+		sc.primarySum += primary.elapsed
+		sc.shadowSum += candidate.elapsed
+		sc.mu.Unlock()
+	}
+
+	sc.write(record)
+}
+
+// write 比較用の記録をJSON Linesで書き出し
+func (sc *ShadowClient) write(record shadowRecord) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("シャドー推論の記録のシリアライズ失敗: %v", err)
+		return
+	}
+	if sc.writer == nil {
+		log.Printf("シャドー推論結果: %s", payload)
+		return
+	}
+
+	sc.writerMu.Lock()
+	defer sc.writerMu.Unlock()
+	if _, err := sc.writer.Write(append(payload, '\n')); err != nil {
+		log.Printf("シャドー推論の記録の書き込み失敗: %v", err)
+	}
+}
+
+// newRecordEntry 推論の結果から記録する片方の出力を作成
+func newRecordEntry(outcome shadowOutcome) recordEntry {
+	entry := recordEntry{
+		Output:    outcome.output,
+		LatencyMs: float64(outcome.elapsed.Microseconds()) / 1000,
+	}
+	if outcome.err != nil {
+		entry.Error = outcome.err.Error()
+	}
+	return entry
+}
+
+// ShadowGroup 推論モデル毎のShadowClientの統計をまとめて取得するための集合
+type ShadowGroup []*ShadowClient
+
+// Stats 全てのShadowClientの統計を取得
+func (g ShadowGroup) Stats() []model.ShadowStats {
+	stats := make([]model.ShadowStats, len(g))
+	for i, sc := range g {
+		stats[i] = sc.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,239 @@
+package rollout
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+)
+
+// fakeClient 送信毎に send の結果を返すテスト用の推論クライアント
+type fakeClient struct {
+	send func(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error)
+}
+
+func (f *fakeClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	return f.send(ctx, request)
+}
+
+func (f *fakeClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	return f.send(ctx, &model.InferenceRequest{ClientID: batch.ClientID})
+}
+
+func (f *fakeClient) Connect(ctx context.Context) error { return nil }
+func (f *fakeClient) Disconnect() error                 { return nil }
+func (f *fakeClient) IsConnected() bool                 { return true }
+func (f *fakeClient) GetServerStatus() (string, error)  { return "connected", nil }
+
+// respond 常に result を認識テキストとして返す推論サーバー
+func respond(result string) func(context.Context, *model.InferenceRequest) (*model.InferenceResponse, error) {
+	return func(context.Context, *model.InferenceRequest) (*model.InferenceResponse, error) {
+		return &model.InferenceResponse{Result: result}, nil
+	}
+}
+
+// recordWriter 比較用の記録の書き出しを通知するio.Writer
+type recordWriter chan []byte
+
+func (w recordWriter) Write(p []byte) (int, error) {
+	w <- append([]byte(nil), p...)
+	return len(p), nil
+}
+
+// wait 比較用の記録が1件書き出されるまで待機
+func (w recordWriter) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-w:
+	case <-time.After(5 * time.Second):
+		t.Fatal("シャドー推論の記録が書き出されません")
+	}
+}
+
+var (
+	primaryModel = model.ModelInfo{Name: "ja", Version: "2"}
+	shadowModel  = model.ModelInfo{Name: "ja", Version: "3"}
+)
+
+func TestShadowClientReturnsPrimaryResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		shadow func(context.Context, *model.InferenceRequest) (*model.InferenceResponse, error)
+	}{
+		{
+			name: "シャドーの失敗",
+			shadow: func(context.Context, *model.InferenceRequest) (*model.InferenceResponse, error) {
+				return nil, errors.New("失敗")
+			},
+		},
+		{
+			name: "シャドーの応答が遅い",
+			shadow: func(ctx context.Context, _ *model.InferenceRequest) (*model.InferenceResponse, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := &model.InferenceResponse{Result: "こんにちは", Confidence: 0.9}
+			primary := &fakeClient{send: func(context.Context, *model.InferenceRequest) (*model.InferenceResponse, error) {
+				return want, nil
+			}}
+			writer := make(recordWriter, 1)
+			sc, err := NewShadowClient(primary, &fakeClient{send: tt.shadow}, primaryModel.ID(), shadowModel,
+				model.ShadowConfig{Version: "3", Fraction: 1, TimeoutMs: 200}, writer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			got, err := sc.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "session"})
+			if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+				t.Fatalf("シャドーの応答を待ちました: %s", elapsed)
+			}
+			if err != nil || got != want || got.Result != "こんにちは" {
+				t.Fatalf("推論結果 = %+v, エラー = %v（複製元の推論結果をそのまま返すことを期待）", got, err)
+			}
+
+			writer.wait(t)
+			if stats := sc.Stats(); stats.Failed != 1 || stats.Compared != 0 {
+				t.Fatalf("統計 = %+v（比較できなかった1件を期待）", stats)
+			}
+		})
+	}
+}
+
+func TestShadowClientFraction(t *testing.T) {
+	const requests = 2000
+
+	tests := []struct {
+		name     string
+		fraction float64
+	}{
+		{name: "複製しない", fraction: 0},
+		{name: "3割を複製", fraction: 0.3},
+		{name: "全て複製", fraction: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shadowCalls atomic.Int64
+			shadow := &fakeClient{send: func(context.Context, *model.InferenceRequest) (*model.InferenceResponse, error) {
+				shadowCalls.Add(1)
+				return &model.InferenceResponse{}, nil
+			}}
+			sc, err := NewShadowClient(&fakeClient{send: respond("")}, shadow, primaryModel.ID(), shadowModel,
+				model.ShadowConfig{Version: "3", Fraction: tt.fraction, MaxInFlight: requests}, make(recordWriter, requests))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < requests; i++ {
+				if _, err := sc.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "session"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stats := sc.Stats()
+			if stats.Dropped != 0 {
+				t.Fatalf("同時送信の上限で複製しなかったリクエスト = %d", stats.Dropped)
+			}
+			if got := float64(stats.Mirrored) / requests; math.Abs(got-tt.fraction) > 0.05 {
+				t.Fatalf("複製した割合 = %.3f（期待値 %.2f）", got, tt.fraction)
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for shadowCalls.Load() != int64(stats.Mirrored) && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			if got := shadowCalls.Load(); got != int64(stats.Mirrored) {
+				t.Fatalf("シャドーへの送信 = %d（複製した %d 件を期待）", got, stats.Mirrored)
+			}
+		})
+	}
+}
+
+func TestShadowClientSendsShadowModel(t *testing.T) {
+	received := make(chan model.InferenceRequest, 1)
+	shadow := &fakeClient{send: func(_ context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+		received <- *request
+		return &model.InferenceResponse{}, nil
+	}}
+	var primaryRequest model.InferenceRequest
+	primary := &fakeClient{send: func(_ context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+		primaryRequest = *request
+		return &model.InferenceResponse{}, nil
+	}}
+	sc, err := NewShadowClient(primary, shadow, primaryModel.ID(), shadowModel,
+		model.ShadowConfig{Version: "3", Fraction: 1}, make(recordWriter, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// InferenceManager が設定するセッションの推論モデル
+	request := &model.InferenceRequest{ClientID: "session", Model: primaryModel.Name, ModelVersion: primaryModel.Version}
+	if _, err := sc.SendInferenceRequest(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case mirror := <-received:
+		if mirror.Model != shadowModel.Name || mirror.ModelVersion != shadowModel.Version {
+			t.Fatalf("複製の推論モデル = %s@%s（期待値 %s）", mirror.Model, mirror.ModelVersion, shadowModel.ID())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("シャドーに複製されません")
+	}
+	if primaryRequest.ModelVersion != primaryModel.Version || request.ModelVersion != primaryModel.Version {
+		t.Fatalf("複製元の推論モデルのバージョン = %s（期待値 %s）", primaryRequest.ModelVersion, primaryModel.Version)
+	}
+}
+
+func TestShadowClientAgreementStats(t *testing.T) {
+	tests := []struct {
+		name             string
+		primary          string
+		shadow           string
+		wantExactMatches uint64
+		wantAgreement    float64
+	}{
+		{name: "同じ認識テキスト", primary: "こんにちは", shadow: "こんにちは", wantExactMatches: 1, wantAgreement: 1},
+		{name: "1文字異なる認識テキスト", primary: "こんにちは", shadow: "こんにちわ", wantAgreement: 0.8},
+		{name: "全て異なる認識テキスト", primary: "はい", shadow: "いえ", wantAgreement: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := make(recordWriter, 1)
+			sc, err := NewShadowClient(&fakeClient{send: respond(tt.primary)}, &fakeClient{send: respond(tt.shadow)},
+				primaryModel.ID(), shadowModel, model.ShadowConfig{Version: "3", Fraction: 1}, writer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := sc.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "session"}); err != nil {
+				t.Fatal(err)
+			}
+			writer.wait(t)
+
+			stats := sc.Stats()
+			if stats.Model != primaryModel.ID() || stats.Shadow != shadowModel.ID() {
+				t.Fatalf("統計の推論モデル = %s, %s", stats.Model, stats.Shadow)
+			}
+			if stats.Mirrored != 1 || stats.Compared != 1 || stats.Failed != 0 {
+				t.Fatalf("統計 = %+v（比較した1件を期待）", stats)
+			}
+			if stats.ExactMatches != tt.wantExactMatches {
+				t.Fatalf("完全一致 = %d（期待値 %d）", stats.ExactMatches, tt.wantExactMatches)
+			}
+			if math.Abs(stats.MeanAgreement-tt.wantAgreement) > 1e-9 {
+				t.Fatalf("一致率の平均 = %g（期待値 %g）", stats.MeanAgreement, tt.wantAgreement)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/model"
+	"socket_inference/pkg/connection_pool"
+	"socket_inference/pkg/connection_pool/core"
+
+	"github.com/coder/websocket"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errConnectionLost 送信に使った接続が応答を受け取る前に切れた（新しい接続で再送できる）
+var errConnectionLost = errors.New("推論サーバーとの接続が切れました")
+
+// requestMessage 推論サーバーに送る推論リクエストのメッセージ
+// 同じ接続で複数のリクエストを送信するため、応答と対応付けるIDを付与する
+type requestMessage struct {
+	ID   string `json:"id"`
+	Type string `json:"type"` // "inference"
+	*model.InferenceRequest
+}
+
+// responseMessage 推論サーバーから受け取る応答のメッセージ
+type responseMessage struct {
+	ID       string                   `json:"id"`
+	Response *model.InferenceResponse `json:"response,omitempty"`
+	Error    *errorMessage            `json:"error,omitempty"`
+}
+
+// errorMessage 推論サーバーが返したエラー（code はgRPCのステータスコード名または番号）
+type errorMessage struct {
+	Code    json.RawMessage `json:"code"`
+	Message string          `json:"message"`
+}
+
+// Err gRPCクライアントと同じステータスのエラーに変換（未知のコードは Unknown）
+func (e *errorMessage) Err() error {
+	var code codes.Code
+	if err := code.UnmarshalJSON(e.Code); err != nil {
+		code = codes.Unknown
+	}
+	return status.Errorf(code, "WebSocket推論サーバーがエラーを返しました: %s", e.Message)
+}
+
+// InferenceClient 接続プールのWebSocket接続を共有して推論サーバーにバッチを送信するInferenceClientの実装
+// 接続はメッセージの書き込み中だけプールから借り、応答はIDで対応付けて受信側のgoroutineから受け取る
+type InferenceClient struct {
+	endpoint string
+	timeout  time.Duration
+	config   model.WebSocketBackendConfig
+
+	mu     sync.RWMutex // 送信中（プールから借りている間）は読み取りロック、接続・切断は書き込みロック
+	pool   *connection_pool.ConnectionPool
+	ctx    context.Context // 切断時に取り消し、受信中の接続を閉じる
+	cancel context.CancelFunc
+
+	connsMu sync.Mutex
+	conns   map[string]*upstreamConn // プールの接続ID毎の受信状態
+}
+
+// NewInferenceClient エンドポイントのURLのWebSocket推論サーバーにバッチを送信するクライアントを作成
+func NewInferenceClient(endpoint string, timeout time.Duration, config model.WebSocketBackendConfig) interfaces.InferenceClient {
+	return &InferenceClient{
+		endpoint: endpoint,
+		timeout:  timeout,
+		config:   config,
+		conns:    make(map[string]*upstreamConn),
+	}
+}
+
+// SendInferenceRequest 推論リクエストを送信し、同じIDの応答を待機
+// 応答を受け取る前に接続が切れた場合は、設定の回数まで新しい接続で再送する
+func (ic *InferenceClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	log.Printf("WebSocket推論リクエスト送信: クライアント=%s, タスク=%s, 送信先=%s", request.ClientID, request.Task, ic.endpoint)
+
+	if ic.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ic.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		response, err := ic.roundTrip(ctx, request)
+		if err == nil {
+			response.ClientID = request.ClientID
+			if response.ProcessingTime == 0 {
+				response.ProcessingTime = time.Since(start)
+			}
+			return response, nil
+		}
+		if !errors.Is(err, errConnectionLost) {
+			return nil, err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, contextError(ctxErr)
+		}
+		if attempt >= ic.config.Reconnects {
+			return nil, status.Errorf(codes.Unavailable, "WebSocket推論サーバーと通信できません: %v", err)
+		}
+		log.Printf("WebSocket推論サーバーとの接続が切れたため新しい接続で再送: クライアント=%s, 再送=%d/%d, エラー=%v",
+			request.ClientID, attempt+1, ic.config.Reconnects, err)
+	}
+}
+
+// SendBatchInferenceRequest バッチ推論リクエストを送信
+func (ic *InferenceClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	// AudioBatchをInferenceRequestに変換
+	request := &model.InferenceRequest{
+		ClientID:  batch.ClientID,
+		AudioData: batch.AudioData,
+		Timestamp: batch.Timestamp,
+		BatchSize: batch.BatchSize,
+		Features:  batch.Features,
+
+		Format:     batch.Format,
+		Sequence:   batch.Sequence,
+		OffsetMs:   batch.OffsetMs,
+		DurationMs: batch.DurationMs,
+
+		IdempotencyKey: batch.IdempotencyKey,
+	}
+
+	return ic.SendInferenceRequest(ctx, request)
+}
+
+// Connect 接続プールを作成し、推論サーバーに接続できることを確認
+func (ic *InferenceClient) Connect(ctx context.Context) error {
+	log.Printf("WebSocket推論サーバーに接続中: %s", ic.endpoint)
+
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	if ic.pool != nil {
+		return nil
+	}
+
+	config := core.PoolConfig{
+		MaxPoolSize: ic.config.MaxConnections,
+		ServerURL:   ic.endpoint,
+	}
+	_ = config.Validate() // 未指定の項目にデフォルト値を設定
+	ic.pool = connection_pool.NewConnectionPool(config)
+	ic.ctx, ic.cancel = context.WithCancel(context.Background())
+
+	if err := ic.probe(ctx); err != nil {
+		ic.shutdown()
+		return fmt.Errorf("WebSocket推論サーバー接続失敗: %w", err)
+	}
+	go ic.cleanupLoop(ic.ctx, ic.pool, config.CleanupInterval)
+
+	log.Printf("WebSocket推論サーバー接続成功: %s", ic.endpoint)
+	return nil
+}
+
+// Disconnect 推論サーバーから切断（プールの全ての接続を閉じる）
+// 応答待ちのリクエストは接続が切れたものとして失敗する
+func (ic *InferenceClient) Disconnect() error {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	if ic.pool == nil {
+		return nil
+	}
+
+	ic.shutdown()
+	log.Printf("WebSocket推論サーバー切断完了: %s", ic.endpoint)
+	return nil
+}
+
+// IsConnected 接続状態を確認
+func (ic *InferenceClient) IsConnected() bool {
+	ic.mu.RLock()
+	defer ic.mu.RUnlock()
+	return ic.pool != nil
+}
+
+// GetServerStatus サーバーの状態を取得（プールから接続を取得できるか確認）
+func (ic *InferenceClient) GetServerStatus() (string, error) {
+	ic.mu.RLock()
+	defer ic.mu.RUnlock()
+	if ic.pool == nil {
+		return "disconnected", nil
+	}
+
+	ctx := context.Background()
+	if ic.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ic.timeout)
+		defer cancel()
+	}
+	if err := ic.probe(ctx); err != nil {
+		return "unhealthy", err
+	}
+	return "connected", nil
+}
+
+// roundTrip プールから借りた接続でリクエストを1回送信し、応答を待機
+func (ic *InferenceClient) roundTrip(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	id := uuid.New().String()
+	payload, err := json.Marshal(requestMessage{ID: id, Type: "inference", InferenceRequest: request})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "WebSocket推論リクエストのエンコード失敗: %v", err)
+	}
+
+	results, conn, err := ic.send(ctx, id, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case result := <-results:
+		return result.response, result.err
+	case <-ctx.Done():
+		conn.unregister(id)
+		return nil, contextError(ctx.Err())
+	}
+}
+
+// send プールから接続を借りてリクエストを書き込み、書き込み後すぐに接続を返却
+// 応答は戻り値のチャネルで受け取る
+func (ic *InferenceClient) send(ctx context.Context, id string, payload []byte) (<-chan result, *upstreamConn, error) {
+	ic.mu.RLock()
+	defer ic.mu.RUnlock()
+	if ic.pool == nil {
+		return nil, nil, status.Error(codes.Unavailable, "WebSocket推論サーバーに接続されていません")
+	}
+
+	pooled, err := ic.pool.Get(ctx)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, contextError(ctxErr)
+		}
+		return nil, nil, status.Errorf(codes.Unavailable, "WebSocket推論サーバーと通信できません: %v", err)
+	}
+
+	conn := ic.upstream(pooled)
+	results, ok := conn.register(id)
+	if !ok {
+		// 受信側で切断を検知済みの接続（プールからの削除前に取得した）
+		_ = ic.pool.Close(pooled)
+		return nil, nil, errConnectionLost
+	}
+
+	if err := pooled.Conn.Write(ctx, websocket.MessageText, payload); err != nil {
+		conn.unregister(id)
+		ic.lose(conn, err)
+		return nil, nil, &connectionLostError{err: err}
+	}
+	if err := ic.pool.Put(pooled); err != nil {
+		log.Printf("WebSocket接続のプールへの返却失敗: %v", err)
+	}
+	return results, conn, nil
+}
+
+// probe プールから接続を取得して返却し、推論サーバーに接続できることを確認（ic.mu を保持して呼び出す）
+func (ic *InferenceClient) probe(ctx context.Context) error {
+	pooled, err := ic.pool.Get(ctx)
+	if err != nil {
+		return err
+	}
+	ic.upstream(pooled)
+	return ic.pool.Put(pooled)
+}
+
+// upstream プールの接続の受信状態を取得（初めて使う接続は受信を開始、ic.mu を保持して呼び出す）
+func (ic *InferenceClient) upstream(pooled *core.PooledConnection) *upstreamConn {
+	ic.connsMu.Lock()
+	defer ic.connsMu.Unlock()
+
+	if conn, ok := ic.conns[pooled.ID]; ok {
+		return conn
+	}
+	conn := &upstreamConn{pooled: pooled, pool: ic.pool, pending: make(map[string]chan result)}
+	ic.conns[pooled.ID] = conn
+	pooled.Conn.SetReadLimit(ic.config.MaxMessageBytes)
+	go ic.readLoop(ic.ctx, conn)
+	return conn
+}
+
+// readLoop 接続から応答を受信し、同じIDで待機しているリクエストに渡す
+// 受信に失敗した場合は接続をプールから削除し、応答待ちのリクエストを失敗させる
+func (ic *InferenceClient) readLoop(ctx context.Context, conn *upstreamConn) {
+	for {
+		_, payload, err := conn.pooled.Conn.Read(ctx)
+		if err != nil {
+			ic.lose(conn, err)
+			return
+		}
+
+		var message responseMessage
+		if err := json.Unmarshal(payload, &message); err != nil {
+			log.Printf("WebSocket推論サーバーの応答を解析できません: %v", err)
+			continue
+		}
+		var res result
+		switch {
+		case message.Error != nil:
+			res.err = message.Error.Err()
+		case message.Response == nil:
+			res.err = status.Error(codes.Internal, "WebSocket推論サーバーの応答に推論結果がありません")
+		default:
+			res.response = message.Response
+		}
+		if !conn.deliver(message.ID, res) {
+			// タイムアウト・取り消し済みのリクエストへの応答
+			log.Printf("対応するリクエストのない応答を破棄: ID=%s", message.ID)
+		}
+	}
+}
+
+// lose 切れた接続をプールから削除し、応答待ちのリクエストを失敗させる
+func (ic *InferenceClient) lose(conn *upstreamConn, err error) {
+	if !conn.fail(err) {
+		return
+	}
+	log.Printf("WebSocket推論サーバーとの接続が切れました: %s, 接続ID=%s, エラー=%v", ic.endpoint, conn.pooled.ID, err)
+
+	// 先にプールから削除し、以降の取得で同じ接続が返らないようにする
+	_ = conn.pool.Close(conn.pooled)
+	ic.connsMu.Lock()
+	delete(ic.conns, conn.pooled.ID)
+	ic.connsMu.Unlock()
+}
+
+// cleanupLoop 生存期間を超えた接続・アイドル状態の接続を定期的に閉じる
+func (ic *InferenceClient) cleanupLoop(ctx context.Context, pool *connection_pool.ConnectionPool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := pool.Cleanup(); err != nil {
+				log.Printf("WebSocket接続プールのクリーンアップ失敗: %v", err)
+			}
+		}
+	}
+}
+
+// shutdown 受信を止めて接続プールを閉じる（ic.mu を保持して呼び出す）
+func (ic *InferenceClient) shutdown() {
+	ic.cancel()
+	if err := ic.pool.Shutdown(); err != nil {
+		log.Printf("WebSocket接続プールのシャットダウン失敗: %v", err)
+	}
+	ic.pool = nil
+}
+
+// contextError 取り消し・タイムアウトをgRPCと同様に Canceled / DeadlineExceeded のエラーに変換
+func contextError(err error) error {
+	return status.Errorf(status.FromContextError(err).Code(), "WebSocket推論リクエスト中断: %v", err)
+}
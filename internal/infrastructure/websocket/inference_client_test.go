@@ -0,0 +1,236 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+
+	"github.com/coder/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// upstreamRequest テスト用の推論サーバーが受信した推論リクエスト
+type upstreamRequest struct {
+	ID       string `json:"id"`
+	ClientID string `json:"client_id"`
+}
+
+// readRequest 推論リクエストを1件受信
+func readRequest(ctx context.Context, conn *websocket.Conn) (upstreamRequest, error) {
+	var request upstreamRequest
+	_, payload, err := conn.Read(ctx)
+	if err != nil {
+		return request, err
+	}
+	err = json.Unmarshal(payload, &request)
+	return request, err
+}
+
+// writeMessage 応答のメッセージを送信
+func writeMessage(ctx context.Context, conn *websocket.Conn, message any) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return conn.Write(ctx, websocket.MessageText, payload)
+}
+
+// echo リクエストのセッションIDを認識テキストとして返す応答
+func echo(request upstreamRequest) map[string]any {
+	return map[string]any{"id": request.ID, "response": map[string]any{"result": request.ClientID}}
+}
+
+// serveEcho 接続が切れるまで推論リクエストに echo で応答
+func serveEcho(ctx context.Context, conn *websocket.Conn) {
+	for {
+		request, err := readRequest(ctx, conn)
+		if err != nil {
+			return
+		}
+		if err := writeMessage(ctx, conn, echo(request)); err != nil {
+			return
+		}
+	}
+}
+
+// newTestUpstream handle で応答するWebSocket推論サーバーの代役と、それに接続したクライアントを作成
+// handle には接続の番号（1始まり、Connect の確認で作る接続が1）を渡す
+func newTestUpstream(t *testing.T, config model.WebSocketBackendConfig, handle func(ctx context.Context, conn *websocket.Conn, connection int)) (*InferenceClient, *atomic.Int32) {
+	t.Helper()
+
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		handle(r.Context(), conn, int(connections.Add(1)))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewInferenceClient("ws"+strings.TrimPrefix(server.URL, "http"), 5*time.Second, config).(*InferenceClient)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Disconnect() })
+	return client, &connections
+}
+
+func TestInferenceClientMatchesResponsesByID(t *testing.T) {
+	config := model.DefaultWebSocketBackendConfig()
+	config.MaxConnections = 1
+	client, connections := newTestUpstream(t, config, func(ctx context.Context, conn *websocket.Conn, connection int) {
+		// 2件のリクエストを受信してから、後のリクエストに先に応答する
+		first, err := readRequest(ctx, conn)
+		if err != nil {
+			return
+		}
+		second, err := readRequest(ctx, conn)
+		if err != nil {
+			return
+		}
+		if writeMessage(ctx, conn, echo(second)) != nil || writeMessage(ctx, conn, echo(first)) != nil {
+			return
+		}
+		serveEcho(ctx, conn)
+	})
+
+	var wg sync.WaitGroup
+	for _, sessionID := range []string{"session-a", "session-b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := client.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: sessionID})
+			if err != nil {
+				t.Errorf("%s: エラー = %v", sessionID, err)
+				return
+			}
+			if response.Result != sessionID || response.ClientID != sessionID {
+				t.Errorf("%s: 他のリクエストへの応答を受け取りました: %+v", sessionID, response)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := connections.Load(); got != 1 {
+		t.Fatalf("接続数 = %d（1つの接続の共有を期待）", got)
+	}
+}
+
+func TestInferenceClientResendsOnNewConnection(t *testing.T) {
+	tests := []struct {
+		name            string
+		reconnects      int
+		wantCode        codes.Code
+		wantConnections int32
+	}{
+		{name: "新しい接続で再送", reconnects: 2, wantCode: codes.OK, wantConnections: 2},
+		{name: "再送しない設定", reconnects: 0, wantCode: codes.Unavailable, wantConnections: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := model.DefaultWebSocketBackendConfig()
+			config.MaxConnections = 1
+			config.Reconnects = tt.reconnects
+			client, connections := newTestUpstream(t, config, func(ctx context.Context, conn *websocket.Conn, connection int) {
+				if connection == 1 {
+					// 最初の接続はリクエストを受信した後、応答せずに切断する
+					_, _ = readRequest(ctx, conn)
+					return
+				}
+				serveEcho(ctx, conn)
+			})
+
+			response, err := client.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "session"})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("ステータスコード = %s（期待値 %s）, エラー = %v", code, tt.wantCode, err)
+			}
+			if err == nil && response.Result != "session" {
+				t.Fatalf("推論結果 = %+v", response)
+			}
+			if got := connections.Load(); got != tt.wantConnections {
+				t.Fatalf("接続数 = %d（期待値 %d）", got, tt.wantConnections)
+			}
+		})
+	}
+}
+
+func TestInferenceClientMapsErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code any
+		want codes.Code
+	}{
+		{name: "コード名", code: "RESOURCE_EXHAUSTED", want: codes.ResourceExhausted},
+		{name: "コード番号", code: 3, want: codes.InvalidArgument},
+		{name: "未知のコード", code: "NO_SUCH_CODE", want: codes.Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestUpstream(t, model.DefaultWebSocketBackendConfig(), func(ctx context.Context, conn *websocket.Conn, connection int) {
+				for {
+					request, err := readRequest(ctx, conn)
+					if err != nil {
+						return
+					}
+					message := map[string]any{"id": request.ID, "error": map[string]any{"code": tt.code, "message": "失敗"}}
+					if err := writeMessage(ctx, conn, message); err != nil {
+						return
+					}
+				}
+			})
+
+			_, err := client.SendInferenceRequest(context.Background(), &model.InferenceRequest{ClientID: "session"})
+			if code := status.Code(err); code != tt.want {
+				t.Fatalf("ステータスコード = %s（期待値 %s）, エラー = %v", code, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestInferenceClientTimeoutRemovesPendingRequest(t *testing.T) {
+	received := make(chan struct{}, 1)
+	client, _ := newTestUpstream(t, model.DefaultWebSocketBackendConfig(), func(ctx context.Context, conn *websocket.Conn, connection int) {
+		// 受信したリクエストに応答しない
+		for {
+			if _, err := readRequest(ctx, conn); err != nil {
+				return
+			}
+			received <- struct{}{}
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.SendInferenceRequest(ctx, &model.InferenceRequest{ClientID: "session"})
+	if code := status.Code(err); code != codes.DeadlineExceeded {
+		t.Fatalf("ステータスコード = %s（期待値 %s）, エラー = %v", code, codes.DeadlineExceeded, err)
+	}
+	<-received
+
+	client.connsMu.Lock()
+	defer client.connsMu.Unlock()
+	if len(client.conns) != 1 {
+		t.Fatalf("接続数 = %d（タイムアウトで接続を閉じないことを期待）", len(client.conns))
+	}
+	for _, conn := range client.conns {
+		conn.mu.Lock()
+		pending := len(conn.pending)
+		conn.mu.Unlock()
+		if pending != 0 {
+			t.Fatalf("タイムアウトしたリクエストが応答待ちに残っています: %d 件", pending)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"sync"
+
+	"socket_inference/internal/model"
+	"socket_inference/pkg/connection_pool"
+	"socket_inference/pkg/connection_pool/core"
+)
+
+// result 1件のリクエストへの応答
+type result struct {
+	response *model.InferenceResponse
+	err      error
+}
+
+// upstreamConn プールの1接続で応答を待っているリクエスト
+// 同じ接続を複数のリクエストで共有するため、応答はリクエストのIDで対応付ける
+type upstreamConn struct {
+	pooled *core.PooledConnection
+	pool   *connection_pool.ConnectionPool // 接続を取得したプール（切断後も削除に使用）
+
+	mu      sync.Mutex
+	pending map[string]chan result
+	lost    bool
+}
+
+// register 応答を待つリクエストを登録（接続が切れている場合はfalse）
+func (c *upstreamConn) register(id string) (<-chan result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lost {
+		return nil, false
+	}
+	results := make(chan result, 1)
+	c.pending[id] = results
+	return results, true
+}
+
+// unregister 応答を待たなくなったリクエストを削除
+func (c *upstreamConn) unregister(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, id)
+}
+
+// deliver 応答を同じIDのリクエストに渡す（待っているリクエストがない場合はfalse）
+func (c *upstreamConn) deliver(id string, res result) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results, ok := c.pending[id]
+	if !ok {
+		return false
+	}
+	delete(c.pending, id)
+	results <- res
+	return true
+}
+
+// fail 接続が切れたことを記録し、応答待ちの全てのリクエストを失敗させる（既に切れていた場合はfalse）
+func (c *upstreamConn) fail(err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lost {
+		return false
+	}
+	c.lost = true
+	for id, results := range c.pending {
+		results <- result{err: &connectionLostError{err: err}}
+		delete(c.pending, id)
+	}
+	return true
+}
+
+// connectionLostError 接続が切れたため応答を受け取れなかったことを表すエラー
+// errors.Is(err, errConnectionLost) で判定できる
+type connectionLostError struct {
+	err error
+}
+
+// Error エラーメッセージ
+func (e *connectionLostError) Error() string {
+	return errConnectionLost.Error() + ": " + e.err.Error()
+}
+
+// Unwrap 接続が切れた原因と errConnectionLost
+func (e *connectionLostError) Unwrap() []error {
+	return []error{errConnectionLost, e.err}
+}
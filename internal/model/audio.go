@@ -5,8 +5,25 @@ import "time"
 // AudioBatch 推論処理用の音声データバッチを表現
 // 音声データのバッチ化と管理を担当するドメインモデル
 type AudioBatch struct {
-	ClientID  string    `json:"client_id"`  // クライアント識別ID
+	ClientID  string    `json:"client_id"`  // セッションID（AudioClient.SessionID）
 	AudioData [][]byte  `json:"audio_data"` // 音声データ配列
 	Timestamp time.Time `json:"timestamp"`  // バッチ生成時刻
 	BatchSize int       `json:"batch_size"` // バッチサイズ
+
+	// Format AudioDataの音声フォーマット（前処理後は出力エンコーディング・サンプリングレートのモノラル）
+	Format AudioFormat `json:"format"`
+	// Sequence セッション内のバッチ番号（0始まり）
+	Sequence uint64 `json:"sequence"`
+	// OffsetMs AudioData先頭のセッション先頭からの位置（ミリ秒、前処理で確定）
+	OffsetMs int64 `json:"offset_ms"`
+	// DurationMs AudioDataの再生時間（ミリ秒、前処理で確定）
+	DurationMs int64 `json:"duration_ms"`
+	// IdempotencyKey リトライ時に推論サーバーが重複を排除するためのキー（送信時に付与）
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// Events 前処理で検出したセッションイベント（発話開始・終了）
+	Events []SessionEvent `json:"events,omitempty"`
+
+	// Features 前処理で抽出した特徴量（特徴量抽出が無効の場合はnil）
+	Features *FeatureTensor `json:"features,omitempty"`
 }
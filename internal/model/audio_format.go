@@ -0,0 +1,70 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedAudioFormat 音声フォーマットが未対応または不正
+var ErrUnsupportedAudioFormat = errors.New("未対応の音声フォーマット")
+
+// AudioEncoding 音声サンプルのエンコーディング
+type AudioEncoding string
+
+const (
+	EncodingS16LE AudioEncoding = "s16le" // 符号付き16bitリトルエンディアン
+	EncodingS32LE AudioEncoding = "s32le" // 符号付き32bitリトルエンディアン
+	EncodingF32LE AudioEncoding = "f32le" // 32bit浮動小数点リトルエンディアン
+	EncodingU8    AudioEncoding = "u8"    // 符号なし8bit
+	EncodingMulaw AudioEncoding = "mulaw" // G.711 μ-law
+	EncodingAlaw  AudioEncoding = "alaw"  // G.711 A-law
+)
+
+// BytesPerSample 1サンプルあたりのバイト数（未対応の場合は0）
+func (e AudioEncoding) BytesPerSample() int {
+	switch e {
+	case EncodingS16LE:
+		return 2
+	case EncodingS32LE, EncodingF32LE:
+		return 4
+	case EncodingU8, EncodingMulaw, EncodingAlaw:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AudioFormat セッションが宣言する音声フォーマットを表現
+type AudioFormat struct {
+	Encoding   AudioEncoding `json:"encoding"`    // サンプルエンコーディング
+	SampleRate int           `json:"sample_rate"` // サンプリングレート（Hz）
+	Channels   int           `json:"channels"`    // チャンネル数（インターリーブ）
+}
+
+// DefaultAudioFormat フォーマット未宣言時に使用する音声フォーマット
+func DefaultAudioFormat() AudioFormat {
+	return AudioFormat{
+		Encoding:   EncodingS16LE,
+		SampleRate: 16000,
+		Channels:   1,
+	}
+}
+
+// FrameSize 全チャンネル分の1フレームあたりのバイト数
+func (f AudioFormat) FrameSize() int {
+	return f.Encoding.BytesPerSample() * f.Channels
+}
+
+// Validate フォーマットの妥当性を検証
+func (f AudioFormat) Validate() error {
+	if f.Encoding.BytesPerSample() == 0 {
+		return fmt.Errorf("%w: エンコーディング %q", ErrUnsupportedAudioFormat, f.Encoding)
+	}
+	if f.SampleRate <= 0 {
+		return fmt.Errorf("%w: サンプリングレートが不正です: %d", ErrUnsupportedAudioFormat, f.SampleRate)
+	}
+	if f.Channels <= 0 {
+		return fmt.Errorf("%w: チャンネル数が不正です: %d", ErrUnsupportedAudioFormat, f.Channels)
+	}
+	return nil
+}
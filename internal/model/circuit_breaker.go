@@ -0,0 +1,74 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInferenceUnavailable 推論サーバーを利用できない（サーキットブレーカーが開いている）
+var ErrInferenceUnavailable = errors.New("推論サーバーを利用できません")
+
+// ErrInvalidCircuitBreakerConfig サーキットブレーカー設定が不正
+var ErrInvalidCircuitBreakerConfig = errors.New("サーキットブレーカー設定が不正です")
+
+// InferenceUnavailableError 推論サーバーへの送信を即座に打ち切ったことを表すエラー
+// errors.Is(err, ErrInferenceUnavailable) で判定できる
+type InferenceUnavailableError struct {
+	Reason     string        // 打ち切った理由
+	RetryAfter time.Duration // 再び送信を試みるまでの目安
+}
+
+// Error エラーメッセージ
+func (e *InferenceUnavailableError) Error() string {
+	return fmt.Sprintf("%s: %s（%s後に再試行）", ErrInferenceUnavailable, e.Reason, e.RetryAfter.Round(time.Millisecond))
+}
+
+// Unwrap ErrInferenceUnavailable を返す
+func (e *InferenceUnavailableError) Unwrap() error {
+	return ErrInferenceUnavailable
+}
+
+// CircuitState サーキットブレーカーの状態
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // 通常どおり送信
+	CircuitOpen     CircuitState = "open"      // 送信せずに即座に失敗
+	CircuitHalfOpen CircuitState = "half_open" // 少数の試行リクエストで回復を確認
+)
+
+// CircuitBreakerConfig 推論サーバーのサーキットブレーカー設定
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `json:"failure_threshold"` // 開くまでの連続失敗回数
+	OpenDuration     time.Duration `json:"open_duration"`     // 開いてから試行リクエストを許可するまでの時間
+	HalfOpenProbes   int           `json:"half_open_probes"`  // 半開状態で同時に許可する試行リクエスト数
+	SuccessThreshold int           `json:"success_threshold"` // 閉じるまでに必要な試行リクエストの成功回数
+}
+
+// DefaultCircuitBreakerConfig デフォルトのサーキットブレーカー設定
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold: 5,
+		OpenDuration:     10 * time.Second,
+		HalfOpenProbes:   1,
+		SuccessThreshold: 1,
+	}
+}
+
+// Validate サーキットブレーカー設定の妥当性を検証
+func (c CircuitBreakerConfig) Validate() error {
+	if c.FailureThreshold < 1 {
+		return fmt.Errorf("%w: failure_threshold は 1 以上です: %d", ErrInvalidCircuitBreakerConfig, c.FailureThreshold)
+	}
+	if c.OpenDuration <= 0 {
+		return fmt.Errorf("%w: open_duration は 0 より大きい値です: %s", ErrInvalidCircuitBreakerConfig, c.OpenDuration)
+	}
+	if c.HalfOpenProbes < 1 {
+		return fmt.Errorf("%w: half_open_probes は 1 以上です: %d", ErrInvalidCircuitBreakerConfig, c.HalfOpenProbes)
+	}
+	if c.SuccessThreshold < 1 {
+		return fmt.Errorf("%w: success_threshold は 1 以上です: %d", ErrInvalidCircuitBreakerConfig, c.SuccessThreshold)
+	}
+	return nil
+}
@@ -1,10 +1,30 @@
 package model
 
-import "github.com/coder/websocket"
+import (
+	"context"
+	"time"
+
+	"github.com/coder/websocket"
+)
 
 // AudioClient 音声ストリーミング用のWebSocket接続を表現
 // クライアント接続とセッション管理を担当するドメインモデル
 type AudioClient struct {
-	Conn     *websocket.Conn // WebSocket接続
-	ClientID string          // クライアント識別用ID
+	Conn          *websocket.Conn      // WebSocket接続（WebSocket以外のプロトコルではnil）
+	SessionID     string               // 接続毎に一意のセッションID（前処理・推論・結果配信のキー）
+	ClientID      string               // クライアント識別ID（mTLSの証明書またはX-Client-ID、匿名の場合は空。セッションの属性でありキーには使わない）
+	Sender        ResultSender         // 推論結果の送信先（プロトコル非依存）
+	Format        AudioFormat          // セッションが宣言した音声フォーマット
+	Preprocessing *PreprocessingConfig // セッション固有の前処理設定（nilの場合はサーバー共通の設定）
+	Task          TaskConfig           // セッションが宣言した推論タスク（ゼロ値の場合は音声認識）
+	Model         ModelSelection       // セッションが宣言した推論モデル（ゼロ値の場合は既定のモデル）
+
+	InferenceTimeout time.Duration // セッションが宣言した1バッチの推論のタイムアウト（0の場合はサーバーのデフォルト）
+}
+
+// ResultSender 推論結果をクライアントへ送信する手段を表現
+// WebSocket、gRPC等のプロトコル毎に実装される
+type ResultSender interface {
+	// SendResult 推論結果をクライアントに送信
+	SendResult(ctx context.Context, response *InferenceResponse) error
 }
@@ -0,0 +1,28 @@
+package model
+
+// FeatureType 特徴量の種類
+type FeatureType string
+
+const (
+	FeatureTypeLogMel FeatureType = "log_mel" // 対数メルスペクトログラム
+)
+
+// CMVNMode ケプストラム平均・分散正規化の方式
+type CMVNMode string
+
+const (
+	CMVNNone    CMVNMode = "none"    // 正規化しない
+	CMVNSession CMVNMode = "session" // セッションの累積統計量で正規化
+)
+
+// FeatureTensor 推論サーバーに送る特徴量テンソル
+// Dataは行優先（フレーム × 次元）のfloat32で、Shapeが各次元の大きさを表す
+type FeatureTensor struct {
+	Type          FeatureType `json:"type"`            // 特徴量の種類
+	Shape         []int       `json:"shape"`           // [フレーム数, メルバンク数]
+	Data          []float32   `json:"data"`            // 行優先の特徴量
+	SampleRate    int         `json:"sample_rate"`     // 抽出元の音声のサンプリングレート（Hz）
+	FrameLengthMs int         `json:"frame_length_ms"` // 分析フレーム長（ミリ秒）
+	HopMs         int         `json:"hop_ms"`          // フレームシフト（ミリ秒）
+	CMVN          CMVNMode    `json:"cmvn"`            // 適用した正規化
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidHedgingConfig ヘッジ設定が不正
+var ErrInvalidHedgingConfig = errors.New("ヘッジ設定が不正です")
+
+// HedgingConfig 推論リクエストのヘッジ（遅いリクエストの複製送信）設定
+// 直近のレイテンシの Percentile パーセンタイルを過ぎても応答がない場合に、別のレプリカへ複製を送る
+type HedgingConfig struct {
+	Enabled       bool          `json:"enabled"`
+	Percentile    float64       `json:"percentile"`      // 複製を送るまでの待機時間に使うレイテンシのパーセンタイル（50〜99.9）
+	InitialDelay  time.Duration `json:"initial_delay"`   // レイテンシの計測数が足りない間の待機時間
+	MinDelay      time.Duration `json:"min_delay"`       // 待機時間の下限
+	MaxHedgeRatio float64       `json:"max_hedge_ratio"` // リクエスト数に対する複製送信の割合の上限（0〜1）
+	WindowSize    int           `json:"window_size"`     // パーセンタイルの計算に使う直近のレイテンシの数
+}
+
+// DefaultHedgingConfig デフォルトのヘッジ設定（無効）
+func DefaultHedgingConfig() HedgingConfig {
+	return HedgingConfig{
+		Enabled:       false,
+		Percentile:    95,
+		InitialDelay:  500 * time.Millisecond,
+		MinDelay:      20 * time.Millisecond,
+		MaxHedgeRatio: 0.1,
+		WindowSize:    1000,
+	}
+}
+
+// Validate ヘッジ設定の妥当性を検証
+func (c HedgingConfig) Validate() error {
+	if c.Percentile < 50 || c.Percentile > 99.9 {
+		return fmt.Errorf("%w: percentile は 50〜99.9 です: %g", ErrInvalidHedgingConfig, c.Percentile)
+	}
+	if c.InitialDelay <= 0 {
+		return fmt.Errorf("%w: initial_delay は 0 より大きい値です: %s", ErrInvalidHedgingConfig, c.InitialDelay)
+	}
+	if c.MinDelay < 0 {
+		return fmt.Errorf("%w: min_delay は 0 以上です: %s", ErrInvalidHedgingConfig, c.MinDelay)
+	}
+	if c.MaxHedgeRatio <= 0 || c.MaxHedgeRatio > 1 {
+		return fmt.Errorf("%w: max_hedge_ratio は 0 より大きく 1 以下です: %g", ErrInvalidHedgingConfig, c.MaxHedgeRatio)
+	}
+	if c.WindowSize < 10 {
+		return fmt.Errorf("%w: window_size は 10 以上です: %d", ErrInvalidHedgingConfig, c.WindowSize)
+	}
+	return nil
+}
+
+// HedgingStats 推論リクエストのヘッジの統計
+type HedgingStats struct {
+	Requests        uint64  `json:"requests"`         // 送信したリクエスト数
+	Hedged          uint64  `json:"hedged"`           // 複製を送信したリクエスト数
+	HedgeWins       uint64  `json:"hedge_wins"`       // 複製が先に成功したリクエスト数
+	BudgetExhausted uint64  `json:"budget_exhausted"` // 上限に達して複製を送らなかったリクエスト数
+	HedgeRatio      float64 `json:"hedge_ratio"`      // 複製を送信した割合
+	DelayMs         int64   `json:"delay_ms"`         // 現在の複製を送るまでの待機時間（ミリ秒）
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidBackendConfig 推論サーバーとの通信設定が不正
+var ErrInvalidBackendConfig = errors.New("推論サーバーとの通信設定が不正です")
+
+// InferenceBackend 推論サーバーとの通信方式
+type InferenceBackend string
+
+const (
+	BackendGRPC      InferenceBackend = "grpc"      // gRPC（デフォルト）
+	BackendHTTP      InferenceBackend = "http"      // HTTP/JSON（エンドポイントはURL）
+	BackendWebSocket InferenceBackend = "websocket" // WebSocket（エンドポイントはws(s)のURL、接続プールで共有）
+)
+
+// HTTPRequestEncoding HTTP推論サーバーへのバッチの送り方
+type HTTPRequestEncoding string
+
+const (
+	HTTPEncodingJSON      HTTPRequestEncoding = "json"      // 音声をbase64で埋め込んだJSON
+	HTTPEncodingMultipart HTTPRequestEncoding = "multipart" // メタデータのJSONと生の音声のmultipart/form-data
+)
+
+// HTTPBackendConfig HTTP推論サーバーとの通信設定
+type HTTPBackendConfig struct {
+	Encoding HTTPRequestEncoding `json:"encoding"`
+
+	// ResponseTemplate 推論サーバーのレスポンスJSONを推論結果のJSONに変換するテンプレート（text/template）
+	// 空の場合はレスポンスを推論結果のJSONとしてそのまま読み込む
+	ResponseTemplate     string `json:"response_template,omitempty"`
+	ResponseTemplateFile string `json:"response_template_file,omitempty"` // テンプレートのファイル（ResponseTemplate と排他）
+
+	HealthPath   string            `json:"health_path,omitempty"`    // ヘルスチェックのパス（エンドポイントのURLからの相対、空の場合は無効）
+	Headers      map[string]string `json:"headers,omitempty"`        // リクエストに付与するヘッダー（認証等）
+	MaxIdleConns int               `json:"max_idle_conns,omitempty"` // 推論サーバー毎に再利用のため保持するアイドル接続数
+}
+
+// DefaultHTTPBackendConfig デフォルトのHTTP推論サーバーとの通信設定
+func DefaultHTTPBackendConfig() HTTPBackendConfig {
+	return HTTPBackendConfig{
+		Encoding:     HTTPEncodingJSON,
+		MaxIdleConns: 16,
+	}
+}
+
+// Validate HTTP推論サーバーとの通信設定の妥当性を検証
+func (c HTTPBackendConfig) Validate() error {
+	switch c.Encoding {
+	case HTTPEncodingJSON, HTTPEncodingMultipart:
+	default:
+		return fmt.Errorf("%w: encoding は json / multipart です: %q", ErrInvalidBackendConfig, c.Encoding)
+	}
+	if c.ResponseTemplate != "" && c.ResponseTemplateFile != "" {
+		return fmt.Errorf("%w: response_template と response_template_file は同時に指定できません", ErrInvalidBackendConfig)
+	}
+	if c.MaxIdleConns < 0 {
+		return fmt.Errorf("%w: max_idle_conns は 0 以上です: %d", ErrInvalidBackendConfig, c.MaxIdleConns)
+	}
+	return nil
+}
@@ -1,21 +1,83 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // InferenceRequest 推論サーバーへのリクエストを表現
 // 音声データを推論処理するためのリクエストドメインモデル
 type InferenceRequest struct {
-	ClientID  string    `json:"client_id"`  // クライアント識別ID
+	ClientID  string    `json:"client_id"`  // セッションID（AudioClient.SessionID）
 	AudioData [][]byte  `json:"audio_data"` // 音声データ配列
 	Timestamp time.Time `json:"timestamp"`  // リクエスト生成時刻
 	BatchSize int       `json:"batch_size"` // バッチサイズ
+
+	Format     AudioFormat `json:"format"`      // AudioDataの音声フォーマット
+	Sequence   uint64      `json:"sequence"`    // セッション内のバッチ番号（0始まり）
+	OffsetMs   int64       `json:"offset_ms"`   // AudioData先頭のセッション先頭からの位置（ミリ秒）
+	DurationMs int64       `json:"duration_ms"` // AudioDataの再生時間（ミリ秒）
+
+	// IdempotencyKey リトライ時に推論サーバーが重複を排除するためのキー（送信時に付与、再送でも同じ値）
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	Model        string `json:"model,omitempty"`         // 推論モデルの名前（未指定の場合は推論サーバーの既定）
+	ModelVersion string `json:"model_version,omitempty"` // 推論モデルのバージョン
+
+	Task     TaskType `json:"task"`               // 推論タスク
+	Keywords []string `json:"keywords,omitempty"` // 検出対象のキーワード（keyword_spotting のみ）
+
+	// Features 前処理で抽出した特徴量（特徴量抽出が無効の場合はnil）
+	Features *FeatureTensor `json:"features,omitempty"`
 }
 
 // InferenceResponse 推論サーバーからのレスポンスを表現
 // 推論処理結果を格納するレスポンスドメインモデル
 type InferenceResponse struct {
-	ClientID       string        `json:"client_id"`       // クライアント識別ID
-	Result         string        `json:"result"`          // 推論結果
+	ClientID       string        `json:"client_id"`       // セッションID（クライアントへの送信時は WithClientID で識別IDに置き換える）
+	Result         string        `json:"result"`          // 推論結果（最良候補のテキスト）
 	Confidence     float64       `json:"confidence"`      // 推論の信頼度
 	ProcessingTime time.Duration `json:"processing_time"` // 処理時間
+
+	Sequence     uint64          `json:"sequence"`               // 推論したバッチの番号
+	IsFinal      bool            `json:"is_final"`               // 確定結果か（falseの途中結果は後続の結果で置き換えられる）
+	Language     string          `json:"language,omitempty"`     // 認識した言語（BCP 47）
+	Segments     []Segment       `json:"segments,omitempty"`     // 発話区間・単語単位の結果
+	Alternatives []Alternative   `json:"alternatives,omitempty"` // 最良候補以外のN-best候補
+	ModelOutput  json.RawMessage `json:"model_output,omitempty"` // モデル固有の出力（JSON）
+
+	// タスク毎の結果（音声認識以外のタスクで使用）
+	Task      TaskType           `json:"task,omitempty"`      // 推論タスク
+	Labels    []LabelScore       `json:"labels,omitempty"`    // 分類ラベルとスコア（classification）
+	Embedding []float32          `json:"embedding,omitempty"` // 話者埋め込みベクトル（speaker_embedding）
+	Detected  []KeywordDetection `json:"detected,omitempty"`  // 検出したキーワード（keyword_spotting）
+}
+
+// ShiftTimes セグメント・単語・検出したキーワードの時刻にオフセットを加算
+// 推論サーバーはバッチ先頭からの時刻を返すため、セッション先頭からの時刻に変換する際に使用する
+func (r *InferenceResponse) ShiftTimes(offsetMs int64) {
+	for i := range r.Segments {
+		segment := &r.Segments[i]
+		segment.StartMs += offsetMs
+		segment.EndMs += offsetMs
+		for j := range segment.Words {
+			segment.Words[j].StartMs += offsetMs
+			segment.Words[j].EndMs += offsetMs
+		}
+	}
+	for i := range r.Detected {
+		r.Detected[i].StartMs += offsetMs
+		r.Detected[i].EndMs += offsetMs
+	}
+}
+
+// WithClientID クライアントに送信する推論結果（ClientIDを接続元のクライアント識別IDに置き換えたコピー）
+// 匿名の接続（識別IDが空）ではセッションIDのまま返す
+func (r *InferenceResponse) WithClientID(clientID string) *InferenceResponse {
+	if clientID == "" || clientID == r.ClientID {
+		return r
+	}
+	copied := *r
+	copied.ClientID = clientID
+	return &copied
 }
@@ -0,0 +1,13 @@
+package model
+
+// InferenceStats 推論処理の稼働状況
+type InferenceStats struct {
+	Workers        int    `json:"workers"`         // ワーカー数
+	MaxInFlight    int    `json:"max_in_flight"`   // 推論サーバーへの同時リクエスト数の上限
+	InFlight       int    `json:"in_flight"`       // 推論サーバーに送信中のリクエスト数
+	QueueDepth     int    `json:"queue_depth"`     // 推論待ちのバッチ数
+	QueueCapacity  int    `json:"queue_capacity"`  // 推論待ちのバッチ数の上限
+	ActiveSessions int    `json:"active_sessions"` // 推論待ちまたは処理中のバッチがあるセッション数
+	Processed      uint64 `json:"processed"`       // 処理したバッチ数（無音で推論しなかったバッチを含む）
+	Failed         uint64 `json:"failed"`          // 推論に失敗したバッチ数
+}
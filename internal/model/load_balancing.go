@@ -0,0 +1,56 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidLoadBalancingConfig 負荷分散設定が不正
+var ErrInvalidLoadBalancingConfig = errors.New("負荷分散設定が不正です")
+
+// LoadBalancingPolicy 推論サーバーのレプリカを選ぶ方式
+type LoadBalancingPolicy string
+
+const (
+	LoadBalancingRoundRobin       LoadBalancingPolicy = "round_robin"       // 順番に振り分け
+	LoadBalancingLeastOutstanding LoadBalancingPolicy = "least_outstanding" // 送信中のリクエストが最も少ないレプリカ
+	LoadBalancingConsistentHash   LoadBalancingPolicy = "consistent_hash"   // セッション毎に同じレプリカ（コンシステントハッシュ）
+)
+
+// LoadBalancingConfig 複数の推論サーバーへの負荷分散設定
+type LoadBalancingConfig struct {
+	Policy              LoadBalancingPolicy `json:"policy"`
+	HealthCheckInterval time.Duration       `json:"health_check_interval"` // レプリカのヘルスチェック間隔
+	EjectionThreshold   int                 `json:"ejection_threshold"`    // 振り分け対象から外すまでの連続失敗回数
+	EjectionDuration    time.Duration       `json:"ejection_duration"`     // 連続失敗で外したレプリカを戻すまでの最短時間（外れる度に延長）
+}
+
+// DefaultLoadBalancingConfig デフォルトの負荷分散設定
+func DefaultLoadBalancingConfig() LoadBalancingConfig {
+	return LoadBalancingConfig{
+		Policy:              LoadBalancingRoundRobin,
+		HealthCheckInterval: 5 * time.Second,
+		EjectionThreshold:   3,
+		EjectionDuration:    30 * time.Second,
+	}
+}
+
+// Validate 負荷分散設定の妥当性を検証
+func (c LoadBalancingConfig) Validate() error {
+	switch c.Policy {
+	case LoadBalancingRoundRobin, LoadBalancingLeastOutstanding, LoadBalancingConsistentHash:
+	default:
+		return fmt.Errorf("%w: policy は round_robin / least_outstanding / consistent_hash です: %q", ErrInvalidLoadBalancingConfig, c.Policy)
+	}
+	if c.HealthCheckInterval <= 0 {
+		return fmt.Errorf("%w: health_check_interval は 0 より大きい値です: %s", ErrInvalidLoadBalancingConfig, c.HealthCheckInterval)
+	}
+	if c.EjectionThreshold < 1 {
+		return fmt.Errorf("%w: ejection_threshold は 1 以上です: %d", ErrInvalidLoadBalancingConfig, c.EjectionThreshold)
+	}
+	if c.EjectionDuration < 0 {
+		return fmt.Errorf("%w: ejection_duration は 0 以上です: %s", ErrInvalidLoadBalancingConfig, c.EjectionDuration)
+	}
+	return nil
+}
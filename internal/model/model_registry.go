@@ -0,0 +1,173 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownModel 指定された推論モデルが登録されていない
+var ErrUnknownModel = errors.New("推論モデルが見つかりません")
+
+// ErrInvalidModelSpec 推論モデルの設定が不正
+var ErrInvalidModelSpec = errors.New("推論モデルの設定が不正です")
+
+// ModelSelection セッションが宣言した推論モデル
+// Name が空の場合は既定のモデル、Version が空の場合はそのモデルの既定のバージョンを使用する
+type ModelSelection struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// ParseModelSelection "名前" または "名前@バージョン" 形式の文字列を解析
+func ParseModelSelection(value string) ModelSelection {
+	name, version, _ := strings.Cut(strings.TrimSpace(value), "@")
+	return ModelSelection{Name: strings.TrimSpace(name), Version: strings.TrimSpace(version)}
+}
+
+// String "名前@バージョン" 形式の文字列（バージョン未指定の場合は名前のみ）
+func (s ModelSelection) String() string {
+	if s.Version == "" {
+		return s.Name
+	}
+	return s.Name + "@" + s.Version
+}
+
+// ModelInfo 登録されている推論モデルの情報
+type ModelInfo struct {
+	Name        string     `json:"name"`
+	Version     string     `json:"version"`
+	Description string     `json:"description,omitempty"`
+	Languages   []string   `json:"languages,omitempty"` // 対応する言語（BCP 47）
+	Tasks       []TaskType `json:"tasks,omitempty"`     // 対応する推論タスク（空の場合は全てのタスク）
+	Default     bool       `json:"default"`             // 名前・バージョンを省略したセッションが使用するか
+
+	// Preprocessing モデルのデフォルトの前処理設定（nilの場合はサーバー共通の設定）
+	Preprocessing *PreprocessingConfig `json:"preprocessing,omitempty"`
+
+	Canary *CanaryConfig `json:"canary,omitempty"` // 新しいバージョンへのセッションの振り分け（nilの場合は無効）
+	Shadow *ShadowConfig `json:"shadow,omitempty"` // 別のバージョンへのバッチの複製（nilの場合は無効）
+}
+
+// ID "名前@バージョン" 形式の識別子
+func (m ModelInfo) ID() string {
+	return m.Name + "@" + m.Version
+}
+
+// SupportsTask モデルが推論タスクに対応しているか
+func (m ModelInfo) SupportsTask(task TaskType) bool {
+	return len(m.Tasks) == 0 || slices.Contains(m.Tasks, task)
+}
+
+// ModelSpec 推論モデルの登録設定
+// Endpoints が空の場合はサーバー共通の推論サーバー（GRPC_SERVER）を使用する
+type ModelSpec struct {
+	ModelInfo
+	Endpoints []string                `json:"endpoints,omitempty"` // 推論サーバーのアドレス（複数の場合は負荷分散、HTTP・WebSocketの場合はURL）
+	Backend   InferenceBackend        `json:"backend,omitempty"`   // 推論サーバーとの通信方式（空の場合はgRPC）
+	HTTP      *HTTPBackendConfig      `json:"http,omitempty"`      // HTTP推論サーバーとの通信設定（nilの場合はデフォルト）
+	WebSocket *WebSocketBackendConfig `json:"websocket,omitempty"` // WebSocket推論サーバーとの通信設定（nilの場合はデフォルト）
+}
+
+// HTTPConfig HTTP推論サーバーとの通信設定を取得（未指定の場合はデフォルト）
+func (s ModelSpec) HTTPConfig() HTTPBackendConfig {
+	if s.HTTP == nil {
+		return DefaultHTTPBackendConfig()
+	}
+	return *s.HTTP
+}
+
+// WebSocketConfig WebSocket推論サーバーとの通信設定を取得（未指定の場合はデフォルト）
+func (s ModelSpec) WebSocketConfig() WebSocketBackendConfig {
+	if s.WebSocket == nil {
+		return DefaultWebSocketBackendConfig()
+	}
+	return *s.WebSocket
+}
+
+// Validate 推論モデルの設定の妥当性を検証
+func (s ModelSpec) Validate() error {
+	if s.Name == "" || strings.ContainsAny(s.Name, "@, ") {
+		return fmt.Errorf("%w: name は空でなく @・カンマ・空白を含まない文字列です: %q", ErrInvalidModelSpec, s.Name)
+	}
+	if s.Version == "" || strings.ContainsAny(s.Version, "@, ") {
+		return fmt.Errorf("%w: %s の version は空でなく @・カンマ・空白を含まない文字列です: %q", ErrInvalidModelSpec, s.Name, s.Version)
+	}
+	for _, task := range s.Tasks {
+		switch task {
+		case TaskTranscription, TaskClassification, TaskSpeakerEmbedding, TaskKeywordSpotting:
+		default:
+			return fmt.Errorf("%w: %s のタスク: %w: %q", ErrInvalidModelSpec, s.ID(), ErrUnsupportedTask, task)
+		}
+	}
+	if s.Preprocessing != nil {
+		if err := s.Preprocessing.Validate(); err != nil {
+			return fmt.Errorf("%w: %s の前処理設定: %w", ErrInvalidModelSpec, s.ID(), err)
+		}
+	}
+	switch s.Backend {
+	case "", BackendGRPC:
+	case BackendHTTP:
+		if err := s.HTTPConfig().Validate(); err != nil {
+			return fmt.Errorf("%w: %s の通信設定: %w", ErrInvalidModelSpec, s.ID(), err)
+		}
+		if err := s.validateURLs("http", "https"); err != nil {
+			return err
+		}
+	case BackendWebSocket:
+		if err := s.WebSocketConfig().Validate(); err != nil {
+			return fmt.Errorf("%w: %s の通信設定: %w", ErrInvalidModelSpec, s.ID(), err)
+		}
+		if err := s.validateURLs("ws", "wss"); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: %s の backend は grpc / http / websocket です: %q", ErrInvalidModelSpec, s.ID(), s.Backend)
+	}
+	if s.Canary != nil {
+		if err := s.Canary.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", s.ID(), err)
+		}
+		if s.Canary.Version == s.Version {
+			return fmt.Errorf("%w: %s の canary は別のバージョンです", ErrInvalidModelSpec, s.ID())
+		}
+	}
+	if s.Shadow != nil {
+		if err := s.Shadow.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", s.ID(), err)
+		}
+		if s.Shadow.Version == s.Version {
+			return fmt.Errorf("%w: %s の shadow は別のバージョンです", ErrInvalidModelSpec, s.ID())
+		}
+	}
+	return nil
+}
+
+// validateURLs 推論サーバーのアドレスが指定したスキームのURLか検証
+func (s ModelSpec) validateURLs(schemes ...string) error {
+	for _, endpoint := range s.Endpoints {
+		if u, err := url.Parse(endpoint); err != nil || !slices.Contains(schemes, u.Scheme) || u.Host == "" {
+			return fmt.Errorf("%w: %s の endpoints は%s のURLです: %q", ErrInvalidModelSpec, s.ID(), strings.Join(schemes, " / "), endpoint)
+		}
+	}
+	return nil
+}
+
+// UnknownModelError 指定された推論モデルが登録されていないことを表すエラー
+// 利用可能なモデルの一覧を含み、errors.Is(err, ErrUnknownModel) で判定できる
+type UnknownModelError struct {
+	Requested ModelSelection
+	Available []string // 利用可能なモデル（"名前@バージョン"）
+}
+
+// Error エラーメッセージ
+func (e *UnknownModelError) Error() string {
+	return fmt.Sprintf("%v: %q（利用可能: %s）", ErrUnknownModel, e.Requested.String(), strings.Join(e.Available, ", "))
+}
+
+// Unwrap ErrUnknownModel を返す
+func (e *UnknownModelError) Unwrap() error {
+	return ErrUnknownModel
+}
@@ -0,0 +1,327 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPreprocessingConfig 前処理設定が不正
+var ErrInvalidPreprocessingConfig = errors.New("前処理設定が不正です")
+
+// ResampleQuality サンプリングレート変換の品質プリセット
+type ResampleQuality string
+
+const (
+	ResampleQualityLow    ResampleQuality = "low"    // 低遅延・低負荷
+	ResampleQualityMedium ResampleQuality = "medium" // 標準
+	ResampleQualityHigh   ResampleQuality = "high"   // 高品質（エイリアシング最小）
+)
+
+// PreprocessingConfig 前処理パイプラインの設定
+// 段の順序は 復号 → ダウンミックス → リサンプリング → DC除去 → 雑音抑圧 → VAD → プリエンファシス → ゲイン正規化 → AGC → クリッピング検出 → 特徴量抽出 で固定
+type PreprocessingConfig struct {
+	OutputEncoding   AudioEncoding          `json:"output_encoding"` // 出力エンコーディング（f32le / s16le）
+	Resample         ResampleConfig         `json:"resample"`
+	DCRemoval        DCRemovalConfig        `json:"dc_removal"`
+	NoiseSuppression NoiseSuppressionConfig `json:"noise_suppression"`
+	VAD              VADConfig              `json:"vad"`
+	PreEmphasis      PreEmphasisConfig      `json:"pre_emphasis"`
+	Gain             GainConfig             `json:"gain"`
+	AGC              AGCConfig              `json:"agc"`
+	Clipping         ClippingConfig         `json:"clipping"`
+	Features         FeatureConfig          `json:"features"`
+}
+
+// ResampleConfig サンプリングレート変換の設定
+type ResampleConfig struct {
+	TargetSampleRate int             `json:"target_sample_rate"` // 出力サンプリングレート（Hz）
+	Quality          ResampleQuality `json:"quality"`            // 品質プリセット
+}
+
+// DCRemovalConfig 直流成分除去（1次ハイパスフィルター）の設定
+type DCRemovalConfig struct {
+	Enabled bool    `json:"enabled"`
+	Pole    float64 `json:"pole"` // 極の位置（1に近いほどカットオフが低い）
+}
+
+// NoiseSuppressionConfig スペクトル減算による雑音抑圧の設定
+type NoiseSuppressionConfig struct {
+	Enabled         bool    `json:"enabled"`
+	OverSubtraction float64 `json:"over_subtraction"` // 雑音推定値に掛ける減算係数（大きいほど強く抑圧）
+	SpectralFloor   float64 `json:"spectral_floor"`   // 各周波数ビンの最小ゲイン（ミュージカルノイズを抑える）
+	NoiseAdaptRate  float64 `json:"noise_adapt_rate"` // 雑音と判定したフレームで雑音推定値を更新する速度
+	InitialNoiseMs  int     `json:"initial_noise_ms"` // セッション先頭で雑音とみなして推定に使う区間の長さ
+}
+
+// VADMode 発話区間検出の判定方式
+type VADMode string
+
+const (
+	VADModeEnergy VADMode = "energy" // エネルギーとゼロ交差率による判定
+	VADModeGMM    VADMode = "gmm"    // 音声・雑音の混合ガウスモデルによる尤度比判定
+)
+
+// VADConfig 発話区間検出の設定
+type VADConfig struct {
+	Enabled        bool    `json:"enabled"`
+	Mode           VADMode `json:"mode"`
+	Aggressiveness int     `json:"aggressiveness"` // 0〜3（大きいほど無音と判定しやすい）
+	FrameMs        int     `json:"frame_ms"`       // 判定フレーム長（10 / 20 / 30ms）
+	MinSpeechMs    int     `json:"min_speech_ms"`  // 発話開始とみなす連続音声区間の長さ
+	HangoverMs     int     `json:"hangover_ms"`    // 発話終了とみなす連続無音区間の長さ
+	DropSilence    bool    `json:"drop_silence"`   // 無音チャンクを推論前に破棄する
+}
+
+// PreEmphasisConfig プリエンファシス（高域強調）の設定
+type PreEmphasisConfig struct {
+	Enabled     bool    `json:"enabled"`
+	Coefficient float64 `json:"coefficient"` // y[n] = x[n] - coefficient * x[n-1]
+}
+
+// GainConfig ゲイン正規化の設定
+type GainConfig struct {
+	Enabled    bool    `json:"enabled"`
+	TargetDBFS float64 `json:"target_dbfs"` // 目標RMSレベル（dBFS）
+	MaxGainDB  float64 `json:"max_gain_db"` // 最大増幅量（dB）
+	Smoothing  float64 `json:"smoothing"`   // チャンク間のゲイン平滑化係数（0で平滑化なし）
+}
+
+// AGCConfig 自動利得制御（アタック・リリース付き）の設定
+type AGCConfig struct {
+	Enabled    bool    `json:"enabled"`
+	TargetDBFS float64 `json:"target_dbfs"` // 目標RMSレベル（dBFS）
+	MaxGainDB  float64 `json:"max_gain_db"` // 最大増幅量（dB）
+	AttackMs   int     `json:"attack_ms"`   // レベル上昇時にゲインを下げる時定数（ミリ秒）
+	ReleaseMs  int     `json:"release_ms"`  // レベル低下時にゲインを戻す時定数（ミリ秒）
+	GateDBFS   float64 `json:"gate_dbfs"`   // これより小さいレベルではゲインを保持（無音の過増幅を防止）
+}
+
+// ClippingConfig クリッピング検出の設定
+type ClippingConfig struct {
+	Enabled   bool    `json:"enabled"`
+	Threshold float64 `json:"threshold"`  // クリップとみなす振幅
+	WarnRatio float64 `json:"warn_ratio"` // 警告を出すクリップサンプルの割合
+}
+
+// FeatureConfig 対数メルスペクトログラム抽出の設定
+type FeatureConfig struct {
+	Enabled       bool     `json:"enabled"`
+	FrameLengthMs int      `json:"frame_length_ms"` // 分析フレーム長（ミリ秒）
+	HopMs         int      `json:"hop_ms"`          // フレームシフト（ミリ秒）
+	MelBins       int      `json:"mel_bins"`        // メルフィルターバンクの数
+	LowFreq       float64  `json:"low_freq"`        // フィルターバンクの下限周波数（Hz）
+	HighFreq      float64  `json:"high_freq"`       // フィルターバンクの上限周波数（Hz、0でナイキスト周波数）
+	CMVN          CMVNMode `json:"cmvn"`            // 平均・分散正規化
+	IncludeAudio  bool     `json:"include_audio"`   // 特徴量に加えて音声データも送信する
+}
+
+// DefaultPreprocessingConfig 前処理パイプラインのデフォルト設定
+func DefaultPreprocessingConfig() PreprocessingConfig {
+	return PreprocessingConfig{
+		OutputEncoding: EncodingF32LE,
+		Resample: ResampleConfig{
+			TargetSampleRate: 16000,
+			Quality:          ResampleQualityMedium,
+		},
+		DCRemoval: DCRemovalConfig{
+			Enabled: true,
+			Pole:    0.995,
+		},
+		NoiseSuppression: NoiseSuppressionConfig{
+			Enabled:         false,
+			OverSubtraction: 2.0,
+			SpectralFloor:   0.05,
+			NoiseAdaptRate:  0.05,
+			InitialNoiseMs:  200,
+		},
+		VAD: VADConfig{
+			Enabled:        false,
+			Mode:           VADModeEnergy,
+			Aggressiveness: 1,
+			FrameMs:        20,
+			MinSpeechMs:    60,
+			HangoverMs:     300,
+			DropSilence:    true,
+		},
+		PreEmphasis: PreEmphasisConfig{
+			Enabled:     false,
+			Coefficient: 0.97,
+		},
+		Gain: GainConfig{
+			Enabled:    false,
+			TargetDBFS: -20,
+			MaxGainDB:  30,
+			Smoothing:  0.9,
+		},
+		AGC: AGCConfig{
+			Enabled:    false,
+			TargetDBFS: -20,
+			MaxGainDB:  30,
+			AttackMs:   10,
+			ReleaseMs:  500,
+			GateDBFS:   -50,
+		},
+		Clipping: ClippingConfig{
+			Enabled:   true,
+			Threshold: 0.999,
+			WarnRatio: 0.01,
+		},
+		Features: FeatureConfig{
+			Enabled:       false,
+			FrameLengthMs: 25,
+			HopMs:         10,
+			MelBins:       80,
+			LowFreq:       20,
+			HighFreq:      0,
+			CMVN:          CMVNNone,
+			IncludeAudio:  false,
+		},
+	}
+}
+
+// Validate 設定の妥当性を検証
+func (c PreprocessingConfig) Validate() error {
+	switch c.OutputEncoding {
+	case EncodingF32LE, EncodingS16LE:
+	default:
+		return invalidPreprocessing("output_encoding は f32le または s16le です: %q", c.OutputEncoding)
+	}
+
+	if c.Resample.TargetSampleRate < 8000 || c.Resample.TargetSampleRate > 192000 {
+		return invalidPreprocessing("resample.target_sample_rate は 8000〜192000 です: %d", c.Resample.TargetSampleRate)
+	}
+	switch c.Resample.Quality {
+	case ResampleQualityLow, ResampleQualityMedium, ResampleQualityHigh:
+	default:
+		return invalidPreprocessing("resample.quality は low / medium / high です: %q", c.Resample.Quality)
+	}
+
+	if c.DCRemoval.Enabled && (c.DCRemoval.Pole <= 0 || c.DCRemoval.Pole >= 1) {
+		return invalidPreprocessing("dc_removal.pole は 0〜1（両端を除く）です: %v", c.DCRemoval.Pole)
+	}
+	if c.NoiseSuppression.Enabled {
+		if c.NoiseSuppression.OverSubtraction < 1 || c.NoiseSuppression.OverSubtraction > 10 {
+			return invalidPreprocessing("noise_suppression.over_subtraction は 1〜10 です: %v", c.NoiseSuppression.OverSubtraction)
+		}
+		if c.NoiseSuppression.SpectralFloor < 0 || c.NoiseSuppression.SpectralFloor >= 1 {
+			return invalidPreprocessing("noise_suppression.spectral_floor は 0以上1未満です: %v", c.NoiseSuppression.SpectralFloor)
+		}
+		if c.NoiseSuppression.NoiseAdaptRate <= 0 || c.NoiseSuppression.NoiseAdaptRate > 1 {
+			return invalidPreprocessing("noise_suppression.noise_adapt_rate は 0より大きく1以下です: %v", c.NoiseSuppression.NoiseAdaptRate)
+		}
+		if c.NoiseSuppression.InitialNoiseMs < 0 || c.NoiseSuppression.InitialNoiseMs > 5000 {
+			return invalidPreprocessing("noise_suppression.initial_noise_ms は 0〜5000 です: %d", c.NoiseSuppression.InitialNoiseMs)
+		}
+	}
+	if c.VAD.Enabled {
+		if err := c.VAD.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.PreEmphasis.Enabled && (c.PreEmphasis.Coefficient <= 0 || c.PreEmphasis.Coefficient >= 1) {
+		return invalidPreprocessing("pre_emphasis.coefficient は 0〜1（両端を除く）です: %v", c.PreEmphasis.Coefficient)
+	}
+
+	if c.Gain.Enabled {
+		if c.Gain.TargetDBFS < -60 || c.Gain.TargetDBFS > 0 {
+			return invalidPreprocessing("gain.target_dbfs は -60〜0 です: %v", c.Gain.TargetDBFS)
+		}
+		if c.Gain.MaxGainDB < 0 || c.Gain.MaxGainDB > 60 {
+			return invalidPreprocessing("gain.max_gain_db は 0〜60 です: %v", c.Gain.MaxGainDB)
+		}
+		if c.Gain.Smoothing < 0 || c.Gain.Smoothing >= 1 {
+			return invalidPreprocessing("gain.smoothing は 0以上1未満です: %v", c.Gain.Smoothing)
+		}
+	}
+
+	if c.AGC.Enabled {
+		if c.AGC.TargetDBFS < -60 || c.AGC.TargetDBFS > 0 {
+			return invalidPreprocessing("agc.target_dbfs は -60〜0 です: %v", c.AGC.TargetDBFS)
+		}
+		if c.AGC.MaxGainDB < 0 || c.AGC.MaxGainDB > 60 {
+			return invalidPreprocessing("agc.max_gain_db は 0〜60 です: %v", c.AGC.MaxGainDB)
+		}
+		if c.AGC.AttackMs <= 0 || c.AGC.AttackMs > 1000 {
+			return invalidPreprocessing("agc.attack_ms は 1〜1000 です: %d", c.AGC.AttackMs)
+		}
+		if c.AGC.ReleaseMs <= 0 || c.AGC.ReleaseMs > 10000 {
+			return invalidPreprocessing("agc.release_ms は 1〜10000 です: %d", c.AGC.ReleaseMs)
+		}
+		if c.AGC.GateDBFS < -120 || c.AGC.GateDBFS >= c.AGC.TargetDBFS {
+			return invalidPreprocessing("agc.gate_dbfs は -120以上 target_dbfs 未満です: %v", c.AGC.GateDBFS)
+		}
+	}
+
+	if c.Clipping.Enabled {
+		if c.Clipping.Threshold <= 0 || c.Clipping.Threshold > 1 {
+			return invalidPreprocessing("clipping.threshold は 0より大きく1以下です: %v", c.Clipping.Threshold)
+		}
+		if c.Clipping.WarnRatio < 0 || c.Clipping.WarnRatio > 1 {
+			return invalidPreprocessing("clipping.warn_ratio は 0〜1 です: %v", c.Clipping.WarnRatio)
+		}
+	}
+
+	if c.Features.Enabled {
+		if err := c.Features.validate(c.Resample.TargetSampleRate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate 特徴量抽出設定の妥当性を出力サンプリングレートに対して検証
+func (c FeatureConfig) validate(sampleRate int) error {
+	if c.FrameLengthMs < 10 || c.FrameLengthMs > 100 {
+		return invalidPreprocessing("features.frame_length_ms は 10〜100 です: %d", c.FrameLengthMs)
+	}
+	if c.HopMs <= 0 || c.HopMs > c.FrameLengthMs {
+		return invalidPreprocessing("features.hop_ms は 1〜frame_length_ms です: %d", c.HopMs)
+	}
+	if c.MelBins < 8 || c.MelBins > 256 {
+		return invalidPreprocessing("features.mel_bins は 8〜256 です: %d", c.MelBins)
+	}
+	nyquist := float64(sampleRate) / 2
+	highFreq := c.HighFreq
+	if highFreq == 0 {
+		highFreq = nyquist
+	}
+	if c.LowFreq < 0 || highFreq > nyquist || c.LowFreq >= highFreq {
+		return invalidPreprocessing("features の周波数範囲が不正です: %v〜%vHz（ナイキスト周波数 %vHz）", c.LowFreq, highFreq, nyquist)
+	}
+	switch c.CMVN {
+	case CMVNNone, CMVNSession:
+	default:
+		return invalidPreprocessing("features.cmvn は none または session です: %q", c.CMVN)
+	}
+	return nil
+}
+
+// Validate VAD設定の妥当性を検証
+func (c VADConfig) Validate() error {
+	switch c.Mode {
+	case VADModeEnergy, VADModeGMM:
+	default:
+		return invalidPreprocessing("vad.mode は energy または gmm です: %q", c.Mode)
+	}
+	if c.Aggressiveness < 0 || c.Aggressiveness > 3 {
+		return invalidPreprocessing("vad.aggressiveness は 0〜3 です: %d", c.Aggressiveness)
+	}
+	switch c.FrameMs {
+	case 10, 20, 30:
+	default:
+		return invalidPreprocessing("vad.frame_ms は 10 / 20 / 30 です: %d", c.FrameMs)
+	}
+	if c.MinSpeechMs < 0 || c.MinSpeechMs > 1000 {
+		return invalidPreprocessing("vad.min_speech_ms は 0〜1000 です: %d", c.MinSpeechMs)
+	}
+	if c.HangoverMs < 0 || c.HangoverMs > 5000 {
+		return invalidPreprocessing("vad.hangover_ms は 0〜5000 です: %d", c.HangoverMs)
+	}
+	return nil
+}
+
+// invalidPreprocessing ErrInvalidPreprocessingConfig をラップしたエラーを作成
+func invalidPreprocessing(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPreprocessingConfig, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRecognitionIncomplete 推論できずに破棄されたバッチがあり、ファイル全体の推論結果を返せないことを表す
+var ErrRecognitionIncomplete = errors.New("推論できなかったバッチがあります")
+
+// ErrRecognitionQueueFull 実行待ちのファイル推論ジョブが上限に達していることを表す
+var ErrRecognitionQueueFull = errors.New("ファイル推論ジョブの実行待ちが上限に達しています")
+
+// RecognitionJobStatus ファイル推論ジョブの状態
+type RecognitionJobStatus string
+
+const (
+	RecognitionJobPending   RecognitionJobStatus = "pending"   // 実行待ち
+	RecognitionJobRunning   RecognitionJobStatus = "running"   // 実行中
+	RecognitionJobCompleted RecognitionJobStatus = "completed" // 完了
+	RecognitionJobFailed    RecognitionJobStatus = "failed"    // 失敗
+)
+
+// RecognitionResult 録音ファイル1件分の推論結果を表現
+type RecognitionResult struct {
+	SessionID  string               `json:"session_id"`  // 推論に使用した合成セッションID
+	Results    []*InferenceResponse `json:"results"`     // バッチ毎の推論結果
+	BatchCount int                  `json:"batch_count"` // 推論したバッチ数
+}
+
+// RecognitionJob 非同期ファイル推論ジョブを表現
+type RecognitionJob struct {
+	ID          string               `json:"job_id"`                 // ジョブID
+	Status      RecognitionJobStatus `json:"status"`                 // ジョブの状態
+	Result      *RecognitionResult   `json:"result,omitempty"`       // 完了時の推論結果
+	Error       string               `json:"error,omitempty"`        // 失敗時のエラー内容
+	CreatedAt   time.Time            `json:"created_at"`             // ジョブ作成時刻
+	CompletedAt *time.Time           `json:"completed_at,omitempty"` // ジョブ完了時刻
+}
@@ -0,0 +1,16 @@
+package model
+
+import "errors"
+
+// ErrSessionNotFound 推論結果を購読できるセッションが存在しないことを表す
+var ErrSessionNotFound = errors.New("セッションが見つかりません")
+
+// ErrSessionForbidden 他のクライアントのセッションの推論結果を購読しようとしたことを表す
+var ErrSessionForbidden = errors.New("セッションの推論結果を購読する権限がありません")
+
+// ResultEvent セッションの推論結果に通し番号を付与したイベントを表現
+// Server-Sent EventsのイベントIDとして再送（Last-Event-ID）に使用する
+type ResultEvent struct {
+	ID       uint64             `json:"id"`       // セッション内で単調増加するイベントID
+	Response *InferenceResponse `json:"response"` // 推論結果
+}
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedResultSchema 未対応の推論結果スキーマバージョン
+var ErrUnsupportedResultSchema = errors.New("未対応の推論結果スキーマバージョン")
+
+// ResultSchemaVersion クライアントに配信する推論結果のスキーマバージョン
+type ResultSchemaVersion int
+
+const (
+	ResultSchemaV1 ResultSchemaVersion = 1 // 従来の形式（client_id / result / confidence / processing_time）
+	ResultSchemaV2 ResultSchemaVersion = 2 // 途中・確定、セグメント、N-best候補、言語、モデル固有出力を含む形式
+
+	// DefaultResultSchemaVersion バージョン未指定時のスキーマ（既存クライアントとの互換のため従来の形式）
+	DefaultResultSchemaVersion = ResultSchemaV1
+	// LatestResultSchemaVersion 最新のスキーマ
+	LatestResultSchemaVersion = ResultSchemaV2
+)
+
+// Validate 対応しているバージョンかを検証
+func (v ResultSchemaVersion) Validate() error {
+	if v < ResultSchemaV1 || v > LatestResultSchemaVersion {
+		return fmt.Errorf("%w: %d（1〜%d）", ErrUnsupportedResultSchema, v, LatestResultSchemaVersion)
+	}
+	return nil
+}
+
+// ResultMessageV1 スキーマv1の推論結果メッセージ
+type ResultMessageV1 struct {
+	ClientID       string  `json:"client_id"`
+	Result         string  `json:"result"`
+	Confidence     float64 `json:"confidence"`
+	ProcessingTime int64   `json:"processing_time"` // ナノ秒
+}
+
+// ResultHeaderV2 スキーマv2の全タスク共通のフィールド
+// typeでセッションイベントと区別し、taskでタスク毎のフィールドを判別する
+type ResultHeaderV2 struct {
+	Type             string   `json:"type"`           // 常に "result"
+	SchemaVersion    int      `json:"schema_version"` // 常に 2
+	Task             TaskType `json:"task"`
+	ClientID         string   `json:"client_id"`
+	Sequence         uint64   `json:"sequence"`
+	IsFinal          bool     `json:"is_final"`
+	ProcessingTimeMs int64    `json:"processing_time_ms"`
+}
+
+// ResultMessageV2 スキーマv2の音声認識結果メッセージ
+type ResultMessageV2 struct {
+	ResultHeaderV2
+	Text         string          `json:"text"`
+	Confidence   float64         `json:"confidence"`
+	Language     string          `json:"language,omitempty"`
+	Segments     []Segment       `json:"segments,omitempty"`
+	Alternatives []Alternative   `json:"alternatives,omitempty"`
+	ModelOutput  json.RawMessage `json:"model_output,omitempty"`
+}
+
+// ClassificationMessageV2 スキーマv2の分類結果メッセージ
+type ClassificationMessageV2 struct {
+	ResultHeaderV2
+	Labels      []LabelScore    `json:"labels"` // スコアの降順
+	ModelOutput json.RawMessage `json:"model_output,omitempty"`
+}
+
+// EmbeddingMessageV2 スキーマv2の話者埋め込みメッセージ
+type EmbeddingMessageV2 struct {
+	ResultHeaderV2
+	Dimensions  int             `json:"dimensions"`
+	Embedding   []float32       `json:"embedding"`
+	ModelOutput json.RawMessage `json:"model_output,omitempty"`
+}
+
+// KeywordMessageV2 スキーマv2のキーワード検出結果メッセージ
+type KeywordMessageV2 struct {
+	ResultHeaderV2
+	Detected    []KeywordDetection `json:"detected"`
+	ModelOutput json.RawMessage    `json:"model_output,omitempty"`
+}
+
+// NewResultMessage 推論結果を指定したスキーマバージョンのメッセージに変換
+// v2ではタスク毎のメッセージに変換する。未対応のバージョンはデフォルトのスキーマで変換する
+func NewResultMessage(response *InferenceResponse, version ResultSchemaVersion) interface{} {
+	switch version {
+	case ResultSchemaV2:
+		return newResultMessageV2(response)
+	default:
+		return &ResultMessageV1{
+			ClientID:       response.ClientID,
+			Result:         response.Result,
+			Confidence:     response.Confidence,
+			ProcessingTime: int64(response.ProcessingTime),
+		}
+	}
+}
+
+// newResultMessageV2 推論結果をタスク毎のスキーマv2のメッセージに変換
+func newResultMessageV2(response *InferenceResponse) interface{} {
+	task := response.Task
+	if task == "" {
+		task = TaskTranscription
+	}
+	header := ResultHeaderV2{
+		Type:             "result",
+		SchemaVersion:    int(ResultSchemaV2),
+		Task:             task,
+		ClientID:         response.ClientID,
+		Sequence:         response.Sequence,
+		IsFinal:          response.IsFinal,
+		ProcessingTimeMs: response.ProcessingTime.Milliseconds(),
+	}
+
+	switch task {
+	case TaskClassification:
+		return &ClassificationMessageV2{
+			ResultHeaderV2: header,
+			Labels:         response.Labels,
+			ModelOutput:    response.ModelOutput,
+		}
+	case TaskSpeakerEmbedding:
+		return &EmbeddingMessageV2{
+			ResultHeaderV2: header,
+			Dimensions:     len(response.Embedding),
+			Embedding:      response.Embedding,
+			ModelOutput:    response.ModelOutput,
+		}
+	case TaskKeywordSpotting:
+		return &KeywordMessageV2{
+			ResultHeaderV2: header,
+			Detected:       response.Detected,
+			ModelOutput:    response.ModelOutput,
+		}
+	default:
+		return &ResultMessageV2{
+			ResultHeaderV2: header,
+			Text:           response.Result,
+			Confidence:     response.Confidence,
+			Language:       response.Language,
+			Segments:       response.Segments,
+			Alternatives:   response.Alternatives,
+			ModelOutput:    response.ModelOutput,
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidRetryPolicy リトライポリシーが不正
+var ErrInvalidRetryPolicy = errors.New("リトライポリシーが不正です")
+
+// retryStatusCodes リトライ対象に指定できるステータスコード（gRPCの正規名）
+var retryStatusCodes = map[string]bool{
+	"CANCELLED":           true,
+	"UNKNOWN":             true,
+	"INVALID_ARGUMENT":    true,
+	"DEADLINE_EXCEEDED":   true,
+	"NOT_FOUND":           true,
+	"ALREADY_EXISTS":      true,
+	"PERMISSION_DENIED":   true,
+	"RESOURCE_EXHAUSTED":  true,
+	"FAILED_PRECONDITION": true,
+	"ABORTED":             true,
+	"OUT_OF_RANGE":        true,
+	"UNIMPLEMENTED":       true,
+	"INTERNAL":            true,
+	"UNAVAILABLE":         true,
+	"DATA_LOSS":           true,
+	"UNAUTHENTICATED":     true,
+}
+
+// RetryPolicy 推論リクエストのリトライポリシー
+// 失敗したリクエストを指数バックオフ（ジッター付き）で再送し、初回の失敗以降のリトライを Deadline 内に収める
+// 初回の送信は呼び出し元のタイムアウト（GRPC_TIMEOUT / X-Inference-Timeout）のみに従う
+type RetryPolicy struct {
+	MaxAttempts       int           `json:"max_attempts"`       // 初回を含む最大試行回数（1でリトライなし）
+	InitialBackoff    time.Duration `json:"initial_backoff"`    // 初回リトライまでの待機時間
+	MaxBackoff        time.Duration `json:"max_backoff"`        // 待機時間の上限
+	BackoffMultiplier float64       `json:"backoff_multiplier"` // リトライ毎の待機時間の倍率
+	Jitter            float64       `json:"jitter"`             // 待機時間を ±Jitter の割合でランダムに揺らす（0〜1）
+	RetryableCodes    []string      `json:"retryable_codes"`    // リトライ対象のステータスコード（例: UNAVAILABLE）
+	Deadline          time.Duration `json:"deadline"`           // 初回の失敗以降のリトライの合計時間の上限（0で無制限）
+}
+
+// DefaultRetryPolicy デフォルトのリトライポリシー
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        2 * time.Second,
+		BackoffMultiplier: 2.0,
+		Jitter:            0.2,
+		RetryableCodes:    []string{"UNAVAILABLE", "DEADLINE_EXCEEDED", "RESOURCE_EXHAUSTED", "ABORTED"},
+		Deadline:          10 * time.Second,
+	}
+}
+
+// Validate リトライポリシーの妥当性を検証
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return invalidRetryPolicy("max_attempts は 1 以上です: %d", p.MaxAttempts)
+	}
+	if p.InitialBackoff < 0 {
+		return invalidRetryPolicy("initial_backoff は 0 以上です: %s", p.InitialBackoff)
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		return invalidRetryPolicy("max_backoff は initial_backoff 以上です: %s", p.MaxBackoff)
+	}
+	if p.BackoffMultiplier < 1 {
+		return invalidRetryPolicy("backoff_multiplier は 1 以上です: %g", p.BackoffMultiplier)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return invalidRetryPolicy("jitter は 0〜1 です: %g", p.Jitter)
+	}
+	for _, code := range p.RetryableCodes {
+		if !retryStatusCodes[code] {
+			return invalidRetryPolicy("retryable_codes に未知のステータスコードがあります: %q", code)
+		}
+	}
+	if p.Deadline < 0 {
+		return invalidRetryPolicy("deadline は 0 以上です: %s", p.Deadline)
+	}
+	return nil
+}
+
+// Backoff retry回目（1始まり）のリトライまでの待機時間（ジッター適用前）
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		backoff *= p.BackoffMultiplier
+		if backoff >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(backoff)
+}
+
+// invalidRetryPolicy ErrInvalidRetryPolicy をラップしたエラーを作成
+func invalidRetryPolicy(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRetryPolicy, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// CanaryConfig 推論モデルの新しいバージョンにセッションの一部を振り分けるカナリア設定
+// バージョンを指定しないセッションのうち Fraction の割合を、セッションID毎に固定で Version に振り分ける
+type CanaryConfig struct {
+	Version  string  `json:"version"`  // 振り分け先のバージョン（同じ名前で登録されていること）
+	Fraction float64 `json:"fraction"` // 振り分けるセッションの割合（0〜1）
+}
+
+// Validate カナリア設定の妥当性を検証
+func (c CanaryConfig) Validate() error {
+	if c.Version == "" {
+		return fmt.Errorf("%w: canary の version がありません", ErrInvalidModelSpec)
+	}
+	if c.Fraction < 0 || c.Fraction > 1 {
+		return fmt.Errorf("%w: canary の fraction は 0〜1 です: %g", ErrInvalidModelSpec, c.Fraction)
+	}
+	return nil
+}
+
+// ShadowConfig 推論モデルのバッチの一部を別のバージョンに複製するシャドー設定
+// 複製の推論結果はクライアントに送信せず、両方の出力と一致率・レイテンシを記録する
+type ShadowConfig struct {
+	Version     string  `json:"version"`                 // 複製先のバージョン（同じ名前で登録されていること）
+	Fraction    float64 `json:"fraction"`                // 複製するバッチの割合（0〜1）
+	TimeoutMs   int     `json:"timeout_ms,omitempty"`    // 複製の推論のタイムアウト（0の場合は10秒）
+	MaxInFlight int     `json:"max_in_flight,omitempty"` // 同時に送信する複製の上限（0の場合は4、超えた分は複製しない）
+	LogFile     string  `json:"log_file,omitempty"`      // 両方の出力を書き出すJSON Linesファイル（空の場合はログに出力）
+}
+
+// Timeout 複製の推論のタイムアウト
+func (c ShadowConfig) Timeout() time.Duration {
+	if c.TimeoutMs <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
+
+// InFlightLimit 同時に送信する複製の上限
+func (c ShadowConfig) InFlightLimit() int {
+	if c.MaxInFlight <= 0 {
+		return 4
+	}
+	return c.MaxInFlight
+}
+
+// Validate シャドー設定の妥当性を検証
+func (c ShadowConfig) Validate() error {
+	if c.Version == "" {
+		return fmt.Errorf("%w: shadow の version がありません", ErrInvalidModelSpec)
+	}
+	if c.Fraction < 0 || c.Fraction > 1 {
+		return fmt.Errorf("%w: shadow の fraction は 0〜1 です: %g", ErrInvalidModelSpec, c.Fraction)
+	}
+	if c.TimeoutMs < 0 || c.MaxInFlight < 0 {
+		return fmt.Errorf("%w: shadow の timeout_ms・max_in_flight は 0 以上です", ErrInvalidModelSpec)
+	}
+	return nil
+}
+
+// ShadowStats シャドーへの複製の統計
+type ShadowStats struct {
+	Model            string  `json:"model"`              // 複製元の推論モデル（"名前@バージョン"）
+	Shadow           string  `json:"shadow"`             // 複製先の推論モデル
+	Mirrored         uint64  `json:"mirrored"`           // 複製したバッチ数
+	Dropped          uint64  `json:"dropped"`            // 同時送信の上限により複製しなかったバッチ数
+	Failed           uint64  `json:"failed"`             // 複製元・複製先のいずれかが失敗して比較できなかったバッチ数
+	Compared         uint64  `json:"compared"`           // 出力を比較したバッチ数
+	ExactMatches     uint64  `json:"exact_matches"`      // 出力が完全に一致したバッチ数
+	MeanAgreement    float64 `json:"mean_agreement"`     // 出力の一致率の平均（0〜1）
+	PrimaryLatencyMs float64 `json:"primary_latency_ms"` // 複製元のレイテンシの平均（ミリ秒）
+	ShadowLatencyMs  float64 `json:"shadow_latency_ms"`  // 複製先のレイテンシの平均（ミリ秒）
+}
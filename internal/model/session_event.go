@@ -0,0 +1,54 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// SessionEventType セッションイベントの種類
+type SessionEventType string
+
+const (
+	SessionEventSpeechStart    SessionEventType = "speech_start"    // 発話開始を検出
+	SessionEventSpeechEnd      SessionEventType = "speech_end"      // 発話終了を検出
+	SessionEventSilenceSkipped SessionEventType = "silence_skipped" // 無音のみのバッチを推論せずに破棄
+	SessionEventKeyword        SessionEventType = "keyword"         // キーワードを検出（keyword_spotting）
+
+	// SessionEventInferenceUnavailable 推論サーバーを利用できず、バッチを推論せずに破棄
+	SessionEventInferenceUnavailable SessionEventType = "inference_unavailable"
+
+	// SessionEventBatchDropped 推論の失敗またはサーバーの過負荷により、バッチの推論結果を返さずに破棄
+	SessionEventBatchDropped SessionEventType = "batch_dropped"
+)
+
+// SessionEvent 推論結果以外にクライアントへ通知するセッションのイベント
+type SessionEvent struct {
+	Type      SessionEventType `json:"type"`      // イベントの種類
+	ClientID  string           `json:"client_id"` // セッションID（クライアントへの送信時は WithClientID で識別IDに置き換える）
+	OffsetMs  int64            `json:"offset_ms"` // セッション先頭からの音声上の位置（ミリ秒）
+	Timestamp time.Time        `json:"timestamp"` // イベント生成時刻
+
+	Keyword string  `json:"keyword,omitempty"` // 検出したキーワード（keyword イベントのみ）
+	Score   float64 `json:"score,omitempty"`   // 検出スコア（keyword イベントのみ）
+
+	Message      string `json:"message,omitempty"`        // クライアント向けの説明（inference_unavailable / batch_dropped イベントのみ）
+	RetryAfterMs int64  `json:"retry_after_ms,omitempty"` // 推論の再開を試みるまでの目安（inference_unavailable イベントのみ）
+}
+
+// EventSender セッションイベントをクライアントへ送信する手段を表現
+// ResultSenderの実装のうち、イベントを受け取れるものが実装する
+type EventSender interface {
+	// SendEvent セッションイベントをクライアントに送信
+	SendEvent(ctx context.Context, event *SessionEvent) error
+}
+
+// WithClientID クライアントに送信するイベント（ClientIDを接続元のクライアント識別IDに置き換えたコピー）
+// 匿名の接続（識別IDが空）ではセッションIDのまま返す
+func (e *SessionEvent) WithClientID(clientID string) *SessionEvent {
+	if clientID == "" || clientID == e.ClientID {
+		return e
+	}
+	copied := *e
+	copied.ClientID = clientID
+	return &copied
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedTask 未対応または不正な推論タスク
+var ErrUnsupportedTask = errors.New("未対応の推論タスク")
+
+// TaskType セッションが推論サーバーに依頼するタスクの種類
+type TaskType string
+
+const (
+	TaskTranscription    TaskType = "transcription"     // 音声認識（テキスト）
+	TaskClassification   TaskType = "classification"    // 音響イベント等の分類（ラベル毎のスコア）
+	TaskSpeakerEmbedding TaskType = "speaker_embedding" // 話者埋め込みベクトル
+	TaskKeywordSpotting  TaskType = "keyword_spotting"  // キーワード検出（検出時にイベントを通知）
+)
+
+// TaskConfig セッションが接続時に宣言する推論タスク
+type TaskConfig struct {
+	Type     TaskType `json:"type"`
+	Keywords []string `json:"keywords,omitempty"` // 検出対象のキーワード（keyword_spotting のみ）
+}
+
+// DefaultTaskConfig タスク未宣言時に使用する推論タスク
+func DefaultTaskConfig() TaskConfig {
+	return TaskConfig{Type: TaskTranscription}
+}
+
+// Validate タスクの妥当性を検証
+func (c TaskConfig) Validate() error {
+	switch c.Type {
+	case TaskTranscription, TaskClassification, TaskSpeakerEmbedding:
+		if len(c.Keywords) > 0 {
+			return fmt.Errorf("%w: キーワードは keyword_spotting でのみ指定できます", ErrUnsupportedTask)
+		}
+	case TaskKeywordSpotting:
+		if len(c.Keywords) == 0 {
+			return fmt.Errorf("%w: keyword_spotting には検出するキーワードが必要です", ErrUnsupportedTask)
+		}
+		for _, keyword := range c.Keywords {
+			if keyword == "" {
+				return fmt.Errorf("%w: 空のキーワードは指定できません", ErrUnsupportedTask)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedTask, c.Type)
+	}
+	return nil
+}
+
+// LabelScore 分類ラベルとスコア
+type LabelScore struct {
+	Label string  `json:"label"`
+	Score float64 `json:"score"`
+}
+
+// KeywordDetection 検出したキーワード
+// 時刻はセッション先頭からの位置（ミリ秒）
+type KeywordDetection struct {
+	Keyword string  `json:"keyword"`
+	Score   float64 `json:"score"`
+	StartMs int64   `json:"start_ms"`
+	EndMs   int64   `json:"end_ms"`
+}
@@ -0,0 +1,26 @@
+package model
+
+// Word 単語単位の認識結果
+// 時刻はセッション先頭からの位置（ミリ秒）
+type Word struct {
+	Text       string  `json:"text"`
+	StartMs    int64   `json:"start_ms"`
+	EndMs      int64   `json:"end_ms"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Segment 発話区間（文・フレーズ）単位の認識結果
+// 時刻はセッション先頭からの位置（ミリ秒）
+type Segment struct {
+	Text       string  `json:"text"`
+	StartMs    int64   `json:"start_ms"`
+	EndMs      int64   `json:"end_ms"`
+	Confidence float64 `json:"confidence"`
+	Words      []Word  `json:"words,omitempty"`
+}
+
+// Alternative N-best候補の1つ
+type Alternative struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+}
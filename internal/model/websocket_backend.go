@@ -0,0 +1,33 @@
+package model
+
+import "fmt"
+
+// WebSocketBackendConfig WebSocket推論サーバーとの通信設定
+type WebSocketBackendConfig struct {
+	MaxConnections  int   `json:"max_connections"`   // 推論サーバー毎の接続プールの最大接続数
+	Reconnects      int   `json:"reconnects"`        // 接続が切れた場合に新しい接続で再送する回数
+	MaxMessageBytes int64 `json:"max_message_bytes"` // 受信するメッセージの最大バイト数
+}
+
+// DefaultWebSocketBackendConfig デフォルトのWebSocket推論サーバーとの通信設定
+func DefaultWebSocketBackendConfig() WebSocketBackendConfig {
+	return WebSocketBackendConfig{
+		MaxConnections:  4,
+		Reconnects:      2,
+		MaxMessageBytes: 16 << 20,
+	}
+}
+
+// Validate WebSocket推論サーバーとの通信設定の妥当性を検証
+func (c WebSocketBackendConfig) Validate() error {
+	if c.MaxConnections <= 0 {
+		return fmt.Errorf("%w: max_connections は 1 以上です: %d", ErrInvalidBackendConfig, c.MaxConnections)
+	}
+	if c.Reconnects < 0 {
+		return fmt.Errorf("%w: reconnects は 0 以上です: %d", ErrInvalidBackendConfig, c.Reconnects)
+	}
+	if c.MaxMessageBytes <= 0 {
+		return fmt.Errorf("%w: max_message_bytes は 1 以上です: %d", ErrInvalidBackendConfig, c.MaxMessageBytes)
+	}
+	return nil
+}
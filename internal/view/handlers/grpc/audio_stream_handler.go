@@ -0,0 +1,331 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"socket_inference/internal/model"
+	"socket_inference/internal/view/identity"
+	interfaces "socket_inference/internal/view/interfaces"
+	"socket_inference/internal/view/negotiation"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AudioStreamHandler gRPCを使用した音声ストリーミングハンドラー
+type AudioStreamHandler struct {
+	viewModel interfaces.AudioViewModelInterface
+}
+
+// NewAudioStreamHandler 新しいAudioStreamHandlerを作成
+func NewAudioStreamHandler(viewModel interfaces.AudioViewModelInterface) *AudioStreamHandler {
+	return &AudioStreamHandler{
+		viewModel: viewModel,
+	}
+}
+
+// Register gRPCサーバーにStreamAudioサービスを登録
+func (h *AudioStreamHandler) Register(s grpc.ServiceRegistrar) {
+	RegisterAudioStreamServiceServer(s, h)
+}
+
+// StreamAudio 音声ストリーミング用のgRPCストリームを処理
+func (h *AudioStreamHandler) StreamAudio(stream grpc.ServerStream) error {
+	format, err := audioFormat(stream.Context())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	preprocessing, err := preprocessingConfig(stream.Context())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	resultSchema, err := resultSchemaVersion(stream.Context())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	task, err := taskConfig(stream.Context())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	inferenceTimeout, err := inferenceTimeout(stream.Context())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	clientID := clientIdentity(stream.Context())
+	sessionID := negotiation.NewSessionID()
+	modelInfo, err := h.viewModel.ResolveModel(sessionID, modelSelection(stream.Context()))
+	if err != nil {
+		return modelError(err)
+	}
+	header := metadata.Pairs(strings.ToLower(negotiation.HeaderSessionID), sessionID)
+	if modelInfo.Name != "" {
+		header.Set(strings.ToLower(negotiation.HeaderInferenceModel), modelInfo.ID())
+	}
+	_ = stream.SetHeader(header)
+
+	conn := &streamConn{
+		stream:        stream,
+		sessionID:     sessionID,
+		clientID:      clientID,
+		format:        format,
+		preprocessing: preprocessing,
+		resultSchema:  resultSchema,
+		task:          task,
+		timeout:       inferenceTimeout,
+		model:         model.ModelSelection{Name: modelInfo.Name, Version: modelInfo.Version},
+	}
+
+	err = h.HandleConnection(stream.Context(), conn)
+	if err != nil {
+		log.Printf("セッション %s（クライアント %s）の音声データ読み取りエラー: %v", conn.sessionID, conn.clientID, err)
+	}
+	return err
+}
+
+// HandleConnection AudioStreamHandlerインターフェースの実装
+// クライアントが送信を終了するまで音声データを受信してViewModelに送信
+func (h *AudioStreamHandler) HandleConnection(ctx context.Context, conn interfaces.AudioStreamConn) error {
+	client := &model.AudioClient{
+		SessionID:     conn.SessionID(),
+		ClientID:      conn.ClientID(),
+		Sender:        conn,
+		Format:        conn.Format(),
+		Preprocessing: conn.Preprocessing(),
+		Task:          conn.Task(),
+		Model:         conn.Model(),
+
+		InferenceTimeout: conn.InferenceTimeout(),
+	}
+
+	// クライアントをViewModelに登録
+	if err := h.viewModel.RegisterClient(ctx, client); err != nil {
+		if errors.Is(err, model.ErrUnknownModel) {
+			return modelError(err)
+		}
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer h.viewModel.UnregisterClient(client)
+
+	// 読み取りループ - クライアントからの音声データを受信
+	for {
+		audioData, err := conn.Recv(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// 音声データをViewModelに送信
+		h.viewModel.ProcessAudioData(client.SessionID, audioData)
+	}
+}
+
+// streamConn gRPCストリームをAudioStreamConnとして扱うアダプター
+type streamConn struct {
+	stream        grpc.ServerStream
+	sessionID     string // 接続毎に発行したセッションID
+	clientID      string // クライアント識別ID（匿名の場合は空）
+	format        model.AudioFormat
+	preprocessing *model.PreprocessingConfig
+	resultSchema  model.ResultSchemaVersion // 推論結果を送信するスキーマバージョン
+	task          model.TaskConfig
+	timeout       time.Duration        // 1バッチの推論のタイムアウト（0の場合はサーバーのデフォルト）
+	model         model.ModelSelection // 接続時に確定した推論モデル
+	sendMu        sync.Mutex           // SendMsgは並行呼び出し不可のため保護
+}
+
+// SessionID 接続毎に発行したセッションIDを取得
+func (sc *streamConn) SessionID() string {
+	return sc.sessionID
+}
+
+// ClientID 接続元クライアントの識別IDを取得
+func (sc *streamConn) ClientID() string {
+	return sc.clientID
+}
+
+// Format 接続時に宣言された音声フォーマットを取得
+func (sc *streamConn) Format() model.AudioFormat {
+	return sc.format
+}
+
+// Preprocessing 接続時に指定されたセッション固有の前処理設定を取得
+func (sc *streamConn) Preprocessing() *model.PreprocessingConfig {
+	return sc.preprocessing
+}
+
+// Task 接続時に宣言された推論タスクを取得
+func (sc *streamConn) Task() model.TaskConfig {
+	return sc.task
+}
+
+// InferenceTimeout 接続時に宣言された1バッチの推論のタイムアウトを取得
+func (sc *streamConn) InferenceTimeout() time.Duration {
+	return sc.timeout
+}
+
+// Model 接続時に確定した推論モデルを取得
+func (sc *streamConn) Model() model.ModelSelection {
+	return sc.model
+}
+
+// Recv クライアントから次の音声チャンクを受信
+// ctx が取り消された場合は受信を待たずに返す（受信中のRecvMsgはハンドラーの終了でストリームが閉じると戻る）
+func (sc *streamConn) Recv(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type received struct {
+		chunk *AudioChunk
+		err   error
+	}
+	done := make(chan received, 1)
+	go func() {
+		chunk := &AudioChunk{}
+		done <- received{chunk: chunk, err: sc.stream.RecvMsg(chunk)}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return r.chunk.AudioData, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SendResult 推論結果を接続時に指定されたスキーマでストリームに送信
+func (sc *streamConn) SendResult(ctx context.Context, response *model.InferenceResponse) error {
+	sc.sendMu.Lock()
+	defer sc.sendMu.Unlock()
+
+	return sc.stream.SendMsg(model.NewResultMessage(response.WithClientID(sc.clientID), sc.resultSchema))
+}
+
+// SendEvent セッションイベントをストリームに送信
+func (sc *streamConn) SendEvent(ctx context.Context, event *model.SessionEvent) error {
+	sc.sendMu.Lock()
+	defer sc.sendMu.Unlock()
+
+	return sc.stream.SendMsg(event.WithClientID(sc.clientID))
+}
+
+// clientIdentity ストリームのコンテキストからクライアント識別IDを決定（匿名の場合は空）
+// mTLSで検証済みのクライアント証明書がある場合はメタデータより証明書のサブジェクトを優先
+// 識別IDは接続間で重複し得るため、セッションのキーには NewSessionID で発行したIDを使う
+func clientIdentity(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			if clientID, ok := identity.FromTLS(&tlsInfo.State); ok {
+				return clientID
+			}
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-client-id"); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// audioFormat ストリームのメタデータから音声フォーマットを解析
+func audioFormat(ctx context.Context) (model.AudioFormat, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return negotiation.ParseAudioFormat(func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+}
+
+// preprocessingConfig ストリームのメタデータ（x-audio-preprocessing）から前処理設定を解析
+func preprocessingConfig(ctx context.Context) (*model.PreprocessingConfig, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(negotiation.HeaderPreprocessing)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return negotiation.ParsePreprocessingConfig(values[0])
+}
+
+// resultSchemaVersion ストリームのメタデータ（x-result-schema-version）から推論結果のスキーマバージョンを解析
+func resultSchemaVersion(ctx context.Context) (model.ResultSchemaVersion, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(negotiation.HeaderResultSchema)
+	if len(values) == 0 {
+		return negotiation.ParseResultSchemaVersion("")
+	}
+	return negotiation.ParseResultSchemaVersion(values[0])
+}
+
+// taskConfig ストリームのメタデータ（x-inference-task / x-inference-keywords）から推論タスクを解析
+func taskConfig(ctx context.Context) (model.TaskConfig, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return negotiation.ParseTaskConfig(func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+}
+
+// inferenceTimeout ストリームのメタデータ（x-inference-timeout）から推論のタイムアウトを解析
+func inferenceTimeout(ctx context.Context) (time.Duration, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(negotiation.HeaderInferenceTimeout)
+	if len(values) == 0 {
+		return 0, nil
+	}
+	return negotiation.ParseInferenceTimeout(values[0])
+}
+
+// modelSelection ストリームのメタデータ（x-inference-model / x-inference-model-version）から推論モデルを解析
+func modelSelection(ctx context.Context) model.ModelSelection {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return negotiation.ParseModelSelection(func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+}
+
+// modelError 推論モデルを解決できない場合のgRPCステータスを作成
+// 未登録のモデルは NotFound に、宣言されたモデルと利用可能なモデルの一覧を ErrorInfo として付与する
+func modelError(err error) error {
+	var unknown *model.UnknownModelError
+	if !errors.As(err, &unknown) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	st := status.New(codes.NotFound, err.Error())
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: strings.ToUpper(negotiation.ErrorCodeUnknownModel),
+		Domain: "socket_inference",
+		Metadata: map[string]string{
+			"model":     unknown.Requested.String(),
+			"available": strings.Join(unknown.Available, ","),
+		},
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
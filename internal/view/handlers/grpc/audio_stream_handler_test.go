@@ -0,0 +1,137 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+	"socket_inference/internal/view/negotiation"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// echoViewModel 受信した音声チャンクをそのまま推論結果として返すテスト用のViewModel
+type echoViewModel struct {
+	clients map[string]*model.AudioClient
+}
+
+func (vm *echoViewModel) RegisterClient(ctx context.Context, client *model.AudioClient) error {
+	vm.clients[client.SessionID] = client
+	return nil
+}
+
+func (vm *echoViewModel) UnregisterClient(client *model.AudioClient) {}
+
+func (vm *echoViewModel) ProcessAudioData(sessionID string, audioData []byte) {
+	client := vm.clients[sessionID]
+	_ = client.Sender.SendResult(context.Background(), &model.InferenceResponse{ClientID: sessionID, Result: string(audioData)})
+}
+
+func (vm *echoViewModel) ResolveModel(sessionID string, selection model.ModelSelection) (model.ModelInfo, error) {
+	return model.ModelInfo{}, nil
+}
+
+// startServer 指定したオプションでgRPCサーバーをメモリ上で起動し、接続を返す
+func startServer(t *testing.T, serverOpts ...grpc.ServerOption) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(serverOpts...)
+	NewAudioStreamHandler(&echoViewModel{clients: map[string]*model.AudioClient{}}).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return cc
+}
+
+func TestStreamAudioJSONCodec(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverOpts []grpc.ServerOption
+		wantErr    string
+	}{
+		{name: "サーバーにJSONコーデックを設定", serverOpts: []grpc.ServerOption{ServerOption()}},
+		// コーデックをプロセス全体に登録していないため、設定しないサーバーはprotobufとして解析して失敗する
+		{name: "サーバーにJSONコーデックを設定しない", wantErr: "unmarshal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := startServer(t, tt.serverOpts...)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-client-id", "backend-001")
+			stream, err := OpenAudioStream(ctx, cc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := stream.SendMsg(&AudioChunk{AudioData: []byte("chunk")}); err != nil {
+				t.Fatal(err)
+			}
+
+			var result model.ResultMessageV1
+			err = stream.RecvMsg(&result)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("エラー = %v（%q を含むエラーを期待）", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Result != "chunk" || result.ClientID != "backend-001" {
+				t.Fatalf("推論結果 = %+v", result)
+			}
+			header, err := stream.Header()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if values := header.Get(strings.ToLower(negotiation.HeaderSessionID)); len(values) != 1 || values[0] == "" {
+				t.Fatalf("ヘッダーメタデータのセッションID = %v", values)
+			}
+		})
+	}
+}
+
+// blockingStream 受信がストリームの終了まで戻らないテスト用のServerStream
+type blockingStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *blockingStream) Context() context.Context { return s.ctx }
+
+func (s *blockingStream) RecvMsg(m interface{}) error {
+	<-s.ctx.Done()
+	return s.ctx.Err()
+}
+
+func TestStreamConnRecvHonorsContext(t *testing.T) {
+	streamCtx, closeStream := context.WithCancel(context.Background())
+	defer closeStream()
+	conn := &streamConn{stream: &blockingStream{ctx: streamCtx}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := conn.Recv(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("エラー = %v（%v を期待）", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("ctx の取り消し後も受信を待機しました: %s", elapsed)
+	}
+}
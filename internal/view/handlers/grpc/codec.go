@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc"
+)
+
+// codecName gRPCのcontent-subtypeとして使用するコーデック名
+// メッセージはprotobufではなくJSONでエンコードするため、リクエストの content-type は application/grpc+json になる
+const codecName = "json"
+
+// ServerOption AudioStreamService用のgRPCサーバーにJSONコーデックを設定するオプション
+// プロセス全体のコーデック登録（encoding.RegisterCodec）は行わず、このサーバーのみに適用する
+// サーバーの全サービスのメッセージがJSONになるため、protobufのサービスと同じサーバーに登録しないこと
+func ServerOption() grpc.ServerOption {
+	return grpc.ForceServerCodec(jsonCodec{})
+}
+
+// jsonCodec protobufの生成コードを使わずにメッセージをJSONでエンコードするコーデック
+type jsonCodec struct{}
+
+// Marshal メッセージをJSONにエンコード
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal JSONをメッセージにデコード
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Name コーデック名を返す
+func (jsonCodec) Name() string {
+	return codecName
+}
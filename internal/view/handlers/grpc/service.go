@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// AudioChunk クライアントから送信される音声チャンク
+type AudioChunk struct {
+	AudioData []byte `json:"audio_data"` // 音声データ（JSONではbase64）
+}
+
+// AudioStreamServiceServer 音声ストリーミングgRPCサービスのサーバーインターフェース
+type AudioStreamServiceServer interface {
+	// StreamAudio 双方向ストリームで音声チャンクを受信し、推論結果を返す
+	StreamAudio(stream grpc.ServerStream) error
+}
+
+// ServiceName gRPCサービス名
+const ServiceName = "socket_inference.AudioStreamService"
+
+// StreamAudioMethod StreamAudio RPCのフルメソッド名
+const StreamAudioMethod = "/" + ServiceName + "/StreamAudio"
+
+// streamAudioDesc StreamAudio RPCのストリーム定義
+var streamAudioDesc = grpc.StreamDesc{
+	StreamName:    "StreamAudio",
+	Handler:       streamAudioHandler,
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// audioStreamServiceDesc AudioStreamServiceのサービス定義
+// メッセージはJSONコーデックでエンコードするため、対応する .proto ファイルはない（Metadataは空）
+var audioStreamServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*AudioStreamServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams:     []grpc.StreamDesc{streamAudioDesc},
+}
+
+// streamAudioHandler StreamAudio RPCをサーバー実装に振り分け
+func streamAudioHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AudioStreamServiceServer).StreamAudio(stream)
+}
+
+// RegisterAudioStreamServiceServer gRPCサーバーにAudioStreamServiceを登録
+func RegisterAudioStreamServiceServer(s grpc.ServiceRegistrar, srv AudioStreamServiceServer) {
+	s.RegisterService(&audioStreamServiceDesc, srv)
+}
+
+// OpenAudioStream クライアント側でStreamAudioストリームを開始
+// 送信は AudioChunk、受信は model.InferenceResponse を使用する
+// JSONコーデックはこの呼び出しのみに適用する（content-subtype: json）
+func OpenAudioStream(ctx context.Context, cc grpc.ClientConnInterface, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	opts = append([]grpc.CallOption{grpc.ForceCodec(jsonCodec{})}, opts...)
+	return cc.NewStream(ctx, &streamAudioDesc, StreamAudioMethod, opts...)
+}
@@ -0,0 +1,140 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socket_inference/internal/model"
+	"socket_inference/internal/view/identity"
+	interfaces "socket_inference/internal/view/interfaces"
+	"socket_inference/internal/view/negotiation"
+)
+
+// heartbeatInterval 接続維持のためのコメント送信間隔
+const heartbeatInterval = 15 * time.Second
+
+// EventsHandler セッションの推論結果をServer-Sent Eventsで配信するHTTPハンドラー
+type EventsHandler struct {
+	viewModel interfaces.SessionEventsViewModelInterface
+}
+
+// NewEventsHandler 新しいEventsHandlerを作成
+func NewEventsHandler(viewModel interfaces.SessionEventsViewModelInterface) *EventsHandler {
+	return &EventsHandler{
+		viewModel: viewModel,
+	}
+}
+
+// HandleEvents GET /v1/sessions/{id}/events
+// Last-Event-IDヘッダー（またはlast_event_idクエリ）以降の結果を再送してから配信を続ける
+// 購読できるのはセッションを開いたクライアント（匿名のセッションはセッションIDを知っている者）のみ
+func (h *EventsHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("id")
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resultSchema, err := negotiation.ResultSchemaVersionFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	replay, events, unsubscribe, err := h.viewModel.SubscribeResults(sessionID, identity.FromRequest(r), lastEventID)
+	switch {
+	case errors.Is(err, model.ErrSessionNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, model.ErrSessionForbidden):
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer unsubscribe()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Printf("SSEをサポートしていないレスポンス: %v", err)
+		return
+	}
+
+	log.Printf("SSE購読開始: セッション=%s, Last-Event-ID=%d, 再送=%d件", sessionID, lastEventID, len(replay))
+
+	for _, event := range replay {
+		if err := writeEvent(w, event, resultSchema); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// 購読が打ち切られた場合はクライアントの再接続に任せる
+				return
+			}
+			if err := writeEvent(w, event, resultSchema); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			log.Printf("SSE購読終了: セッション=%s", sessionID)
+			return
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// parseLastEventID 再送の起点となるイベントIDを取得
+// EventSourceの初回接続ではヘッダーを指定できないためクエリも受け付ける
+func parseLastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Last-Event-IDが不正です: %q", value)
+	}
+	return id, nil
+}
+
+// writeEvent 推論結果を指定されたスキーマでSSEのイベントとして書き込み
+func writeEvent(w http.ResponseWriter, event model.ResultEvent, version model.ResultSchemaVersion) error {
+	payload, err := json.Marshal(model.NewResultMessage(event.Response, version))
+	if err != nil {
+		return fmt.Errorf("推論結果のシリアライズ失敗: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: result\ndata: %s\n\n", event.ID, payload)
+	return err
+}
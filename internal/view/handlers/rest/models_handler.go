@@ -0,0 +1,31 @@
+package rest
+
+import (
+	"net/http"
+
+	"socket_inference/internal/model"
+	interfaces "socket_inference/internal/view/interfaces"
+)
+
+// modelsResponse 利用可能な推論モデル一覧のレスポンス
+type modelsResponse struct {
+	Models []model.ModelInfo `json:"models"`
+}
+
+// ModelsHandler 利用可能な推論モデルの一覧を返すHTTPハンドラー
+type ModelsHandler struct {
+	viewModel interfaces.ModelsViewModelInterface
+}
+
+// NewModelsHandler 新しいModelsHandlerを作成
+func NewModelsHandler(viewModel interfaces.ModelsViewModelInterface) *ModelsHandler {
+	return &ModelsHandler{
+		viewModel: viewModel,
+	}
+}
+
+// HandleModels GET /v1/models
+// 推論モデルの名前・バージョン・対応する言語とタスク・デフォルトの前処理設定を登録順に返す
+func (h *ModelsHandler) HandleModels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, modelsResponse{Models: h.viewModel.Models()})
+}
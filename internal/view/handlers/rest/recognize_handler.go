@@ -0,0 +1,164 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socket_inference/internal/model"
+	interfaces "socket_inference/internal/view/interfaces"
+	"socket_inference/internal/view/negotiation"
+)
+
+// RecognizeOptions ファイル推論エンドポイントの設定
+type RecognizeOptions struct {
+	ChunkBytes int           // パイプラインに流すチャンクのバイト数
+	MaxBytes   int64         // アップロードを受け付ける最大バイト数
+	Timeout    time.Duration // 同期推論のタイムアウト
+}
+
+// RecognizeHandler 録音ファイルを推論するHTTPハンドラー
+type RecognizeHandler struct {
+	viewModel interfaces.RecognitionViewModelInterface
+	options   RecognizeOptions
+}
+
+// NewRecognizeHandler 新しいRecognizeHandlerを作成
+func NewRecognizeHandler(viewModel interfaces.RecognitionViewModelInterface, options RecognizeOptions) *RecognizeHandler {
+	if options.ChunkBytes <= 0 {
+		options.ChunkBytes = 3200
+	}
+	return &RecognizeHandler{
+		viewModel: viewModel,
+		options:   options,
+	}
+}
+
+// HandleRecognize POST /v1/recognize
+// WAV/生PCMのボディまたはmultipartの"file"フィールドを受け取り推論結果を返す
+// ?async=true の場合はジョブIDを返し、結果はHandleGetJobで取得する
+func (h *RecognizeHandler) HandleRecognize(w http.ResponseWriter, r *http.Request) {
+	audio, err := h.readAudio(w, r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("音声ファイルが上限(%dバイト)を超えています", maxBytesErr.Limit))
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(audio) == 0 {
+		writeError(w, http.StatusBadRequest, "音声データが空です")
+		return
+	}
+
+	format, err := negotiation.AudioFormatFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	chunks := splitChunks(audio, h.options.ChunkBytes)
+
+	if async, _ := strconv.ParseBool(r.URL.Query().Get("async")); async {
+		job, err := h.viewModel.SubmitRecognitionJob(chunks, format)
+		if errors.Is(err, model.ErrRecognitionQueueFull) {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, recognizeErrorStatus(err), err.Error())
+			return
+		}
+		w.Header().Set("Location", r.URL.Path+"/jobs/"+job.ID)
+		writeJSON(w, http.StatusAccepted, job)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.options.Timeout)
+	defer cancel()
+
+	result, err := h.viewModel.Recognize(ctx, chunks, format)
+	var unavailable *model.InferenceUnavailableError
+	if errors.As(err, &unavailable) {
+		retryAfter := int((unavailable.RetryAfter + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, recognizeErrorStatus(err), fmt.Sprintf("推論が完了しませんでした: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// recognizeErrorStatus ファイル推論のエラーをHTTPステータスに変換
+// リクエストの宣言の誤りは4xx、推論の失敗は502、タイムアウトのみ504とする
+func recognizeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrUnsupportedAudioFormat):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, model.ErrUnknownModel):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrUnsupportedTask), errors.Is(err, model.ErrInvalidPreprocessingConfig):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrRecognitionIncomplete):
+		return http.StatusBadGateway
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// HandleGetJob GET /v1/recognize/jobs/{id}
+// 非同期ファイル推論ジョブの状態と結果を返す
+func (h *RecognizeHandler) HandleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.viewModel.GetRecognitionJob(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "ジョブが見つかりません")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// readAudio リクエストから音声データを読み取り
+func (h *RecognizeHandler) readAudio(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	if h.options.MaxBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.options.MaxBytes)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return io.ReadAll(r.Body)
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return nil, fmt.Errorf("multipartに\"file\"フィールドがありません")
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+// splitChunks 音声データを指定サイズのチャンクに分割
+func splitChunks(audio []byte, chunkBytes int) [][]byte {
+	chunks := make([][]byte, 0, (len(audio)+chunkBytes-1)/chunkBytes)
+	for start := 0; start < len(audio); start += chunkBytes {
+		end := min(start+chunkBytes, len(audio))
+		chunks = append(chunks, audio[start:end])
+	}
+	return chunks
+}
@@ -0,0 +1,26 @@
+package rest
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// errorResponse エラー時のレスポンスボディ
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON 値をJSONレスポンスとして書き込み
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("JSONレスポンス書き込み失敗: %v", err)
+	}
+}
+
+// writeError エラーをJSONレスポンスとして書き込み
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"net/http"
+
+	"socket_inference/internal/model"
+	interfaces "socket_inference/internal/view/interfaces"
+)
+
+// statsResponse 推論処理の稼働状況のレスポンス
+type statsResponse struct {
+	model.InferenceStats
+	Hedging *model.HedgingStats `json:"hedging,omitempty"` // ヘッジの統計（ヘッジ無効の場合は省略）
+	Shadow  []model.ShadowStats `json:"shadow,omitempty"`  // シャドーへの複製の統計（シャドー無効の場合は省略）
+}
+
+// StatsHandler 推論処理の稼働状況を返すHTTPハンドラー
+type StatsHandler struct {
+	viewModel interfaces.InferenceStatsViewModelInterface
+	hedging   interfaces.HedgingStatsInterface
+	shadow    interfaces.ShadowStatsInterface
+}
+
+// NewStatsHandler 新しいStatsHandlerを作成
+func NewStatsHandler(viewModel interfaces.InferenceStatsViewModelInterface) *StatsHandler {
+	return &StatsHandler{
+		viewModel: viewModel,
+	}
+}
+
+// SetHedgingStats ヘッジの統計をレスポンスに含める
+func (h *StatsHandler) SetHedgingStats(hedging interfaces.HedgingStatsInterface) {
+	h.hedging = hedging
+}
+
+// SetShadowStats シャドーへの複製の統計をレスポンスに含める
+func (h *StatsHandler) SetShadowStats(shadow interfaces.ShadowStatsInterface) {
+	h.shadow = shadow
+}
+
+// HandleStats GET /v1/inference/stats
+// ワーカー数、待ち行列の長さ、推論サーバーに送信中のリクエスト数等を返す
+func (h *StatsHandler) HandleStats(w http.ResponseWriter, r *http.Request) {
+	response := statsResponse{InferenceStats: h.viewModel.InferenceStats()}
+	if h.hedging != nil {
+		hedging := h.hedging.Stats()
+		response.Hedging = &hedging
+	}
+	if h.shadow != nil {
+		response.Shadow = h.shadow.Stats()
+	}
+	writeJSON(w, http.StatusOK, response)
+}
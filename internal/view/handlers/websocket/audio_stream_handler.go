@@ -2,16 +2,26 @@ package websocket
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	"socket_inference/internal/model"
+	"socket_inference/internal/view/identity"
 	interfaces "socket_inference/internal/view/interfaces"
+	"socket_inference/internal/view/negotiation"
 
 	"github.com/coder/websocket"
 )
 
+const (
+	readTimeout  = 60 * time.Second // 音声データ受信のタイムアウト
+	writeTimeout = 5 * time.Second  // 推論結果送信のタイムアウト
+)
+
 // AudioStreamHandler WebSocketを使用した音声ストリーミングハンドラー
 type AudioStreamHandler struct {
 	viewModel interfaces.AudioViewModelInterface
@@ -26,6 +36,44 @@ func NewAudioStreamHandler(viewModel interfaces.AudioViewModelInterface) *AudioS
 
 // HandleWebSocket 音声ストリーミング用のWebSocket接続を処理
 func (h *AudioStreamHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// 接続前に音声フォーマットを確定し、受理したフォーマットをレスポンスヘッダーで返す
+	format, err := negotiation.AudioFormatFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	preprocessing, err := negotiation.PreprocessingConfigFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resultSchema, err := negotiation.ResultSchemaVersionFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	task, err := negotiation.TaskConfigFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	inferenceTimeout, err := negotiation.InferenceTimeoutFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// 識別IDは接続間で重複し得るため、セッションのキーには NewSessionID で発行したIDを使う
+	clientID := identity.FromRequest(r)
+	sessionID := negotiation.NewSessionID()
+	modelInfo, err := h.viewModel.ResolveModel(sessionID, negotiation.ModelSelectionFromRequest(r))
+	if err != nil {
+		writeModelError(w, err)
+		return
+	}
+	w.Header().Set(negotiation.HeaderSessionID, sessionID)
+	negotiation.SetAcceptedFormat(w.Header(), format)
+	negotiation.SetAcceptedModel(w.Header(), modelInfo)
+
 	c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
 		InsecureSkipVerify: true, // 開発用。本番はOriginチェックを！
 	})
@@ -34,43 +82,157 @@ func (h *AudioStreamHandler) HandleWebSocket(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	clientID := r.Header.Get("X-Client-ID")
-	if clientID == "" {
-		clientID = "unknown"
+	conn := &streamConn{
+		conn:          c,
+		sessionID:     sessionID,
+		clientID:      clientID,
+		format:        format,
+		preprocessing: preprocessing,
+		resultSchema:  resultSchema,
+		task:          task,
+		timeout:       inferenceTimeout,
+		model:         model.ModelSelection{Name: modelInfo.Name, Version: modelInfo.Version},
 	}
+	defer func() {
+		_ = c.Close(websocket.StatusNormalClosure, "bye")
+	}()
 
+	if err := h.HandleConnection(r.Context(), conn); err != nil {
+		log.Printf("セッション %s（クライアント %s）の音声データ読み取りエラー: %v", conn.sessionID, conn.clientID, err)
+	}
+}
+
+// HandleConnection AudioStreamHandlerインターフェースの実装
+// 接続が終了するまで音声データを受信してViewModelに送信
+func (h *AudioStreamHandler) HandleConnection(ctx context.Context, conn interfaces.AudioStreamConn) error {
 	client := &model.AudioClient{
-		Conn:     c,
-		ClientID: clientID,
+		SessionID:     conn.SessionID(),
+		ClientID:      conn.ClientID(),
+		Sender:        conn,
+		Format:        conn.Format(),
+		Preprocessing: conn.Preprocessing(),
+		Task:          conn.Task(),
+		Model:         conn.Model(),
+
+		InferenceTimeout: conn.InferenceTimeout(),
+	}
+	if sc, ok := conn.(*streamConn); ok {
+		client.Conn = sc.conn
 	}
 
 	// クライアントをViewModelに登録
-	h.viewModel.RegisterClient(client)
+	if err := h.viewModel.RegisterClient(ctx, client); err != nil {
+		return err
+	}
+	defer h.viewModel.UnregisterClient(client)
 
 	// 読み取りループ - クライアントからの音声データを受信
-	ctx := r.Context()
-	defer func() {
-		h.viewModel.UnregisterClient(client)
-		_ = client.Conn.Close(websocket.StatusNormalClosure, "bye")
-	}()
-
 	for {
-		readCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
-		_, audioData, err := client.Conn.Read(readCtx)
-		cancel()
+		audioData, err := conn.Recv(ctx)
 		if err != nil {
-			log.Printf("クライアント %s の音声データ読み取りエラー: %v", client.ClientID, err)
-			return
+			return err
 		}
 
 		// 音声データをViewModelに送信
-		h.viewModel.ProcessAudioData(client.ClientID, audioData)
+		h.viewModel.ProcessAudioData(client.SessionID, audioData)
 	}
 }
 
-// HandleConnection AudioStreamHandlerインターフェースの実装
-func (h *AudioStreamHandler) HandleConnection(connectionData interface{}) error {
-	// この実装はHTTPハンドラーとして使用されるため、
-	// 直接的なインターフェース実装は将来のgRPC統合で使用予定
-	return nil
+// streamConn WebSocket接続をAudioStreamConnとして扱うアダプター
+type streamConn struct {
+	conn          *websocket.Conn
+	sessionID     string // 接続毎に発行したセッションID
+	clientID      string // クライアント識別ID（匿名の場合は空）
+	format        model.AudioFormat
+	preprocessing *model.PreprocessingConfig
+	resultSchema  model.ResultSchemaVersion // 推論結果を送信するスキーマバージョン
+	task          model.TaskConfig
+	timeout       time.Duration        // 1バッチの推論のタイムアウト（0の場合はサーバーのデフォルト）
+	model         model.ModelSelection // 接続時に確定した推論モデル
+}
+
+// SessionID 接続毎に発行したセッションIDを取得
+func (sc *streamConn) SessionID() string {
+	return sc.sessionID
+}
+
+// ClientID 接続元クライアントの識別IDを取得
+func (sc *streamConn) ClientID() string {
+	return sc.clientID
+}
+
+// Format 接続時に宣言された音声フォーマットを取得
+func (sc *streamConn) Format() model.AudioFormat {
+	return sc.format
+}
+
+// Preprocessing 接続時に指定されたセッション固有の前処理設定を取得
+func (sc *streamConn) Preprocessing() *model.PreprocessingConfig {
+	return sc.preprocessing
+}
+
+// Task 接続時に宣言された推論タスクを取得
+func (sc *streamConn) Task() model.TaskConfig {
+	return sc.task
+}
+
+// InferenceTimeout 接続時に宣言された1バッチの推論のタイムアウトを取得
+func (sc *streamConn) InferenceTimeout() time.Duration {
+	return sc.timeout
+}
+
+// Model 接続時に確定した推論モデルを取得
+func (sc *streamConn) Model() model.ModelSelection {
+	return sc.model
+}
+
+// Recv クライアントから次の音声チャンクを受信
+func (sc *streamConn) Recv(ctx context.Context) ([]byte, error) {
+	readCtx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	_, audioData, err := sc.conn.Read(readCtx)
+	return audioData, err
+}
+
+// SendResult 推論結果を接続時に指定されたスキーマのJSONテキストメッセージとして送信
+func (sc *streamConn) SendResult(ctx context.Context, response *model.InferenceResponse) error {
+	payload, err := json.Marshal(model.NewResultMessage(response.WithClientID(sc.clientID), sc.resultSchema))
+	if err != nil {
+		return fmt.Errorf("推論結果のシリアライズ失敗: %w", err)
+	}
+
+	writeCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+
+	return sc.conn.Write(writeCtx, websocket.MessageText, payload)
+}
+
+// SendEvent セッションイベントをJSONテキストメッセージとして送信
+func (sc *streamConn) SendEvent(ctx context.Context, event *model.SessionEvent) error {
+	payload, err := json.Marshal(event.WithClientID(sc.clientID))
+	if err != nil {
+		return fmt.Errorf("イベントのシリアライズ失敗: %w", err)
+	}
+
+	writeCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+
+	return sc.conn.Write(writeCtx, websocket.MessageText, payload)
+}
+
+// writeModelError 推論モデルを解決できない場合のレスポンスを書き込み
+// 未登録のモデルは利用可能なモデルの一覧を含むJSON（404）、それ以外は400を返す
+func writeModelError(w http.ResponseWriter, err error) {
+	var unknown *model.UnknownModelError
+	if !errors.As(err, &unknown) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	if err := json.NewEncoder(w).Encode(negotiation.NewUnknownModelResponse(unknown)); err != nil {
+		log.Printf("JSONレスポンス書き込み失敗: %v", err)
+	}
 }
@@ -0,0 +1,35 @@
+package identity
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// FromTLS 検証済みクライアント証明書のサブジェクトからクライアント識別IDを取得
+// 検証済み証明書がない場合はfalseを返す
+func FromTLS(state *tls.ConnectionState) (string, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+		return "", false
+	}
+
+	cert := state.PeerCertificates[0]
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName, true
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0], true
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0], true
+	default:
+		return cert.Subject.String(), true
+	}
+}
+
+// FromRequest HTTPリクエストのクライアント識別IDを取得
+// 検証済みクライアント証明書を X-Client-ID ヘッダーより優先し、どちらもない場合は空（匿名）を返す
+func FromRequest(r *http.Request) string {
+	if clientID, ok := FromTLS(r.TLS); ok {
+		return clientID
+	}
+	return r.Header.Get("X-Client-ID")
+}
@@ -1,17 +1,77 @@
 package interfaces
 
-import "socket_inference/internal/model"
+import (
+	"context"
+	"time"
+
+	"socket_inference/internal/model"
+)
 
 // AudioStreamHandler 音声ストリーミング処理の共通インターフェース
 // WebSocket、gRPC等の異なるプロトコルで共通利用可能
 type AudioStreamHandler interface {
-	// HandleConnection 接続を処理し、音声ストリーミングを開始
-	HandleConnection(connectionData interface{}) error
+	// HandleConnection 接続を処理し、接続が終了するまで音声ストリーミングを行う
+	HandleConnection(ctx context.Context, conn AudioStreamConn) error
+}
+
+// AudioStreamConn プロトコル非依存の音声ストリーム接続
+type AudioStreamConn interface {
+	model.ResultSender
+
+	// SessionID 接続毎に発行したセッションIDを取得
+	SessionID() string
+
+	// ClientID 接続元クライアントの識別IDを取得（匿名の場合は空）
+	ClientID() string
+
+	// Format 接続時に宣言された音声フォーマットを取得
+	Format() model.AudioFormat
+
+	// Preprocessing 接続時に指定されたセッション固有の前処理設定を取得（未指定の場合はnil）
+	Preprocessing() *model.PreprocessingConfig
+
+	// Task 接続時に宣言された推論タスクを取得
+	Task() model.TaskConfig
+
+	// InferenceTimeout 接続時に宣言された1バッチの推論のタイムアウトを取得（0の場合はサーバーのデフォルト）
+	InferenceTimeout() time.Duration
+
+	// Model 接続時に宣言された推論モデルを取得（ゼロ値の場合は既定のモデル）
+	Model() model.ModelSelection
+
+	// Recv クライアントから次の音声チャンクを受信
+	Recv(ctx context.Context) ([]byte, error)
 }
 
 // AudioViewModelInterface 音声ViewModelのインターフェースを定義
 type AudioViewModelInterface interface {
-	RegisterClient(client *model.AudioClient)
+	RegisterClient(ctx context.Context, client *model.AudioClient) error
 	UnregisterClient(client *model.AudioClient)
-	ProcessAudioData(clientID string, audioData []byte)
+	ProcessAudioData(sessionID string, audioData []byte)
+	ResolveModel(sessionID string, selection model.ModelSelection) (model.ModelInfo, error)
+}
+
+// SessionEventsViewModelInterface セッションの推論結果購読用ViewModelのインターフェースを定義
+type SessionEventsViewModelInterface interface {
+	SubscribeResults(sessionID, subscriberID string, lastEventID uint64) (replay []model.ResultEvent, events <-chan model.ResultEvent, unsubscribe func(), err error)
+}
+
+// InferenceStatsViewModelInterface 推論処理の稼働状況取得用ViewModelのインターフェースを定義
+type InferenceStatsViewModelInterface interface {
+	InferenceStats() model.InferenceStats
+}
+
+// ModelsViewModelInterface 推論モデル一覧取得用ViewModelのインターフェースを定義
+type ModelsViewModelInterface interface {
+	Models() []model.ModelInfo
+}
+
+// HedgingStatsInterface 推論リクエストのヘッジの統計取得のインターフェースを定義
+type HedgingStatsInterface interface {
+	Stats() model.HedgingStats
+}
+
+// ShadowStatsInterface シャドーへの複製の統計取得のインターフェースを定義
+type ShadowStatsInterface interface {
+	Stats() []model.ShadowStats
 }
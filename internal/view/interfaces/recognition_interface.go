@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"socket_inference/internal/model"
+)
+
+// RecognitionViewModelInterface 録音ファイル推論用ViewModelのインターフェースを定義
+type RecognitionViewModelInterface interface {
+	Recognize(ctx context.Context, chunks [][]byte, format model.AudioFormat) (*model.RecognitionResult, error)
+	SubmitRecognitionJob(chunks [][]byte, format model.AudioFormat) (*model.RecognitionJob, error)
+	GetRecognitionJob(jobID string) (*model.RecognitionJob, bool)
+}
@@ -0,0 +1,71 @@
+package negotiation
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"socket_inference/internal/model"
+)
+
+// 音声フォーマットを宣言するヘッダー名（gRPCメタデータでは小文字）
+const (
+	HeaderEncoding   = "X-Audio-Encoding"
+	HeaderSampleRate = "X-Audio-Sample-Rate"
+	HeaderChannels   = "X-Audio-Channels"
+)
+
+// ParseAudioFormat ヘッダー名で値を引く関数から音声フォーマットを解析
+// 宣言されていない項目はデフォルト値を使用する
+func ParseAudioFormat(lookup func(key string) string) (model.AudioFormat, error) {
+	format := model.DefaultAudioFormat()
+
+	if value := lookup(HeaderEncoding); value != "" {
+		format.Encoding = model.AudioEncoding(strings.ToLower(value))
+	}
+	if value := lookup(HeaderSampleRate); value != "" {
+		sampleRate, err := strconv.Atoi(value)
+		if err != nil {
+			return format, fmt.Errorf("%sが不正です: %q", HeaderSampleRate, value)
+		}
+		format.SampleRate = sampleRate
+	}
+	if value := lookup(HeaderChannels); value != "" {
+		channels, err := strconv.Atoi(value)
+		if err != nil {
+			return format, fmt.Errorf("%sが不正です: %q", HeaderChannels, value)
+		}
+		format.Channels = channels
+	}
+
+	if err := format.Validate(); err != nil {
+		return format, err
+	}
+	return format, nil
+}
+
+// AudioFormatFromRequest HTTPリクエストのヘッダーまたはクエリから音声フォーマットを解析
+// クエリは encoding / sample_rate / channels（ブラウザのWebSocketはヘッダーを指定できないため）
+func AudioFormatFromRequest(r *http.Request) (model.AudioFormat, error) {
+	queryKeys := map[string]string{
+		HeaderEncoding:   "encoding",
+		HeaderSampleRate: "sample_rate",
+		HeaderChannels:   "channels",
+	}
+	query := r.URL.Query()
+
+	return ParseAudioFormat(func(key string) string {
+		if value := r.Header.Get(key); value != "" {
+			return value
+		}
+		return query.Get(queryKeys[key])
+	})
+}
+
+// SetAcceptedFormat 受理した音声フォーマットをレスポンスヘッダーに設定
+func SetAcceptedFormat(header http.Header, format model.AudioFormat) {
+	header.Set(HeaderEncoding, string(format.Encoding))
+	header.Set(HeaderSampleRate, strconv.Itoa(format.SampleRate))
+	header.Set(HeaderChannels, strconv.Itoa(format.Channels))
+}
@@ -0,0 +1,69 @@
+package negotiation
+
+import (
+	"net/http"
+
+	"socket_inference/internal/model"
+)
+
+// HeaderInferenceModel 推論モデルを "名前" または "名前@バージョン" で宣言するヘッダー名（gRPCメタデータでは小文字）
+// 接続を受理した場合は確定したモデルを同じヘッダーで返す
+const HeaderInferenceModel = "X-Inference-Model"
+
+// HeaderInferenceModelVersion 推論モデルのバージョンを宣言するヘッダー名（HeaderInferenceModel のバージョンより優先）
+const HeaderInferenceModelVersion = "X-Inference-Model-Version"
+
+// ErrorCodeUnknownModel 推論モデルが見つからない場合のエラーコード
+const ErrorCodeUnknownModel = "unknown_model"
+
+// ParseModelSelection ヘッダー名で値を引く関数から推論モデルを解析
+// 宣言されていない場合はゼロ値（既定のモデル）を返す
+func ParseModelSelection(lookup func(key string) string) model.ModelSelection {
+	selection := model.ParseModelSelection(lookup(HeaderInferenceModel))
+	if version := lookup(HeaderInferenceModelVersion); version != "" {
+		selection.Version = version
+	}
+	return selection
+}
+
+// ModelSelectionFromRequest HTTPリクエストのヘッダーまたはクエリ（model / model_version）から推論モデルを解析
+func ModelSelectionFromRequest(r *http.Request) model.ModelSelection {
+	queryKeys := map[string]string{
+		HeaderInferenceModel:        "model",
+		HeaderInferenceModelVersion: "model_version",
+	}
+	query := r.URL.Query()
+
+	return ParseModelSelection(func(key string) string {
+		if value := r.Header.Get(key); value != "" {
+			return value
+		}
+		return query.Get(queryKeys[key])
+	})
+}
+
+// SetAcceptedModel 受理した推論モデル（"名前@バージョン"）をレスポンスヘッダーに設定
+func SetAcceptedModel(header http.Header, info model.ModelInfo) {
+	if info.Name == "" {
+		return
+	}
+	header.Set(HeaderInferenceModel, info.ID())
+}
+
+// UnknownModelResponse 推論モデルが見つからない場合のレスポンスボディ
+type UnknownModelResponse struct {
+	Error     string   `json:"error"`
+	Code      string   `json:"code"`      // 常に "unknown_model"
+	Model     string   `json:"model"`     // 宣言された推論モデル
+	Available []string `json:"available"` // 利用可能な推論モデル（"名前@バージョン"）
+}
+
+// NewUnknownModelResponse UnknownModelError からレスポンスボディを作成
+func NewUnknownModelResponse(err *model.UnknownModelError) UnknownModelResponse {
+	return UnknownModelResponse{
+		Error:     err.Error(),
+		Code:      ErrorCodeUnknownModel,
+		Model:     err.Requested.String(),
+		Available: err.Available,
+	}
+}
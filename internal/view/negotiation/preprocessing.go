@@ -0,0 +1,41 @@
+package negotiation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"socket_inference/internal/model"
+)
+
+// HeaderPreprocessing セッション固有の前処理設定（JSON）を指定するヘッダー名
+const HeaderPreprocessing = "X-Audio-Preprocessing"
+
+// ParsePreprocessingConfig JSONの前処理設定を解析して検証
+// 指定されていない項目はデフォルト値を使用する。値が空の場合はnilを返す
+func ParsePreprocessingConfig(value string) (*model.PreprocessingConfig, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	config := model.DefaultPreprocessingConfig()
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%sが不正です: %w", HeaderPreprocessing, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// PreprocessingConfigFromRequest HTTPリクエストのヘッダーまたはクエリ（preprocessing）から前処理設定を解析
+func PreprocessingConfigFromRequest(r *http.Request) (*model.PreprocessingConfig, error) {
+	value := r.Header.Get(HeaderPreprocessing)
+	if value == "" {
+		value = r.URL.Query().Get("preprocessing")
+	}
+	return ParsePreprocessingConfig(value)
+}
@@ -0,0 +1,39 @@
+package negotiation
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"socket_inference/internal/model"
+)
+
+// HeaderResultSchema 推論結果のスキーマバージョンを指定するヘッダー名
+const HeaderResultSchema = "X-Result-Schema-Version"
+
+// ParseResultSchemaVersion 推論結果のスキーマバージョンを解析
+// 値が空の場合はデフォルトのバージョンを返す
+func ParseResultSchemaVersion(value string) (model.ResultSchemaVersion, error) {
+	if value == "" {
+		return model.DefaultResultSchemaVersion, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%sが不正です: %q", HeaderResultSchema, value)
+	}
+	version := model.ResultSchemaVersion(number)
+	if err := version.Validate(); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// ResultSchemaVersionFromRequest HTTPリクエストのヘッダーまたはクエリ（result_schema）からスキーマバージョンを解析
+func ResultSchemaVersionFromRequest(r *http.Request) (model.ResultSchemaVersion, error) {
+	value := r.Header.Get(HeaderResultSchema)
+	if value == "" {
+		value = r.URL.Query().Get("result_schema")
+	}
+	return ParseResultSchemaVersion(value)
+}
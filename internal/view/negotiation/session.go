@@ -0,0 +1,13 @@
+package negotiation
+
+import "github.com/google/uuid"
+
+// HeaderSessionID 接続毎に発行したセッションIDを返すヘッダー名（gRPCメタデータでは小文字）
+// セッションの推論結果の購読（/v1/sessions/{id}/events）に使用する
+const HeaderSessionID = "X-Session-ID"
+
+// NewSessionID 接続毎に一意のセッションIDを発行
+// クライアント識別IDは匿名の接続や同じ証明書を共有する接続で重複するため、セッションのキーには使わない
+func NewSessionID() string {
+	return uuid.New().String()
+}
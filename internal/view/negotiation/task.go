@@ -0,0 +1,50 @@
+package negotiation
+
+import (
+	"net/http"
+	"strings"
+
+	"socket_inference/internal/model"
+)
+
+// 推論タスクを宣言するヘッダー名（gRPCメタデータでは小文字）
+const (
+	HeaderTask     = "X-Inference-Task"
+	HeaderKeywords = "X-Inference-Keywords" // カンマ区切り（keyword_spotting のみ）
+)
+
+// ParseTaskConfig ヘッダー名で値を引く関数から推論タスクを解析
+// 宣言されていない場合は音声認識を使用する
+func ParseTaskConfig(lookup func(key string) string) (model.TaskConfig, error) {
+	config := model.DefaultTaskConfig()
+
+	if value := lookup(HeaderTask); value != "" {
+		config.Type = model.TaskType(strings.ToLower(value))
+	}
+	if value := lookup(HeaderKeywords); value != "" {
+		for _, keyword := range strings.Split(value, ",") {
+			config.Keywords = append(config.Keywords, strings.TrimSpace(keyword))
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// TaskConfigFromRequest HTTPリクエストのヘッダーまたはクエリ（task / keywords）から推論タスクを解析
+func TaskConfigFromRequest(r *http.Request) (model.TaskConfig, error) {
+	queryKeys := map[string]string{
+		HeaderTask:     "task",
+		HeaderKeywords: "keywords",
+	}
+	query := r.URL.Query()
+
+	return ParseTaskConfig(func(key string) string {
+		if value := r.Header.Get(key); value != "" {
+			return value
+		}
+		return query.Get(queryKeys[key])
+	})
+}
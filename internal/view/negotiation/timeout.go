@@ -0,0 +1,44 @@
+package negotiation
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HeaderInferenceTimeout 1バッチの推論のタイムアウトを宣言するヘッダー名（gRPCメタデータでは小文字）
+const HeaderInferenceTimeout = "X-Inference-Timeout"
+
+// MaxInferenceTimeout セッションが宣言できる推論のタイムアウトの上限
+const MaxInferenceTimeout = 5 * time.Minute
+
+// ParseInferenceTimeout 推論のタイムアウトを解析
+// "2s" 等の期間表記またはミリ秒の整数を受け付け、宣言されていない場合は0（サーバーのデフォルト）を返す
+func ParseInferenceTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		ms, convErr := strconv.ParseInt(value, 10, 64)
+		if convErr != nil {
+			return 0, fmt.Errorf("推論のタイムアウトが不正です: %q", value)
+		}
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+	if timeout <= 0 || timeout > MaxInferenceTimeout {
+		return 0, fmt.Errorf("推論のタイムアウトは 0 より大きく %s 以下です: %q", MaxInferenceTimeout, value)
+	}
+	return timeout, nil
+}
+
+// InferenceTimeoutFromRequest HTTPリクエストのヘッダーまたはクエリ（inference_timeout）から推論のタイムアウトを解析
+func InferenceTimeoutFromRequest(r *http.Request) (time.Duration, error) {
+	value := r.Header.Get(HeaderInferenceTimeout)
+	if value == "" {
+		value = r.URL.Query().Get("inference_timeout")
+	}
+	return ParseInferenceTimeout(value)
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"socket_inference/internal/config"
+	grpchandler "socket_inference/internal/view/handlers/grpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// GRPCServer 音声ストリーミング用のgRPCサーバーを表現
+type GRPCServer struct {
+	audioHandler *grpchandler.AudioStreamHandler
+	tlsOptions   TLSOptions
+	mu           sync.Mutex
+	grpcServer   *grpc.Server
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+// NewGRPCServer 新しいgRPCサーバーを作成
+// HTTPサーバーと同じTLS設定を使用する
+func NewGRPCServer(audioHandler *grpchandler.AudioStreamHandler, cfg *config.ServerConfig) *GRPCServer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GRPCServer{
+		audioHandler: audioHandler,
+		tlsOptions: TLSOptions{
+			CertFile:          cfg.TLSCertFile,
+			KeyFile:           cfg.TLSKeyFile,
+			ClientCAFile:      cfg.TLSClientCAFile,
+			RequireClientCert: cfg.TLSRequireClientCert,
+			ReloadInterval:    cfg.TLSReloadInterval,
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start gRPCサーバーを開始
+func (s *GRPCServer) Start(addr string) error {
+	opts := []grpc.ServerOption{grpchandler.ServerOption()}
+	if s.tlsOptions.Enabled() {
+		reloader, err := NewCertReloader(s.tlsOptions)
+		if err != nil {
+			return err
+		}
+		reloader.Watch(s.ctx)
+		opts = append(opts, grpc.Creds(credentials.NewTLS(reloader.TLSConfig())))
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	s.audioHandler.Register(grpcServer)
+	s.mu.Lock()
+	s.grpcServer = grpcServer
+	s.mu.Unlock()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("gRPCリスナー作成失敗: %w", err)
+	}
+
+	log.Printf("音声ストリーミングgRPCサーバーがリスニング中: %s (TLS=%t)", addr, s.tlsOptions.Enabled())
+	return grpcServer.Serve(listener)
+}
+
+// Shutdown gRPCサーバーを正常に停止（期限切れの場合は強制停止）
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	log.Println("gRPCサーバーを停止中...")
+	s.cancel()
+
+	s.mu.Lock()
+	grpcServer := s.grpcServer
+	s.mu.Unlock()
+	if grpcServer == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		grpcServer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Middleware HTTPハンドラーをラップするミドルウェア
+type Middleware func(http.Handler) http.Handler
+
+// requestIDKey リクエストIDを格納するコンテキストキー
+type requestIDKey struct{}
+
+// RequestIDHeader リクエストIDを受け渡すHTTPヘッダー名
+const RequestIDHeader = "X-Request-ID"
+
+// Chain ミドルウェアを順番に適用（先頭が最も外側）
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Recovery ハンドラー内のpanicを捕捉して500を返す
+func Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				log.Printf("ハンドラーでpanic発生: %s %s (request_id=%s): %v\n%s",
+					r.Method, r.URL.Path, RequestIDFromContext(r.Context()), rec, debug.Stack())
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequestID リクエストIDを付与（クライアント指定があれば引き継ぐ）
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext コンテキストからリクエストIDを取得
+func RequestIDFromContext(ctx context.Context) string {
+	if requestID, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return requestID
+	}
+	return ""
+}
+
+// AccessLog リクエスト毎のアクセスログを出力
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		log.Printf("アクセス: %s %s status=%d bytes=%d duration=%v remote=%s request_id=%s",
+			r.Method, r.URL.RequestURI(), recorder.status, recorder.bytes,
+			time.Since(start), r.RemoteAddr, RequestIDFromContext(r.Context()))
+	})
+}
+
+// responseRecorder ステータスコードと書き込みバイト数を記録するResponseWriter
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader ステータスコードを記録
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader {
+		rr.status = status
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// Write 書き込みバイト数を記録
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	n, err := rr.ResponseWriter.Write(b)
+	rr.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap 元のResponseWriterを返す（Hijack/Flushをhttp.ResponseController経由で利用可能にする）
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
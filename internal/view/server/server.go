@@ -1,33 +1,140 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
 
+	"socket_inference/internal/config"
 	"socket_inference/internal/view/handlers/websocket"
 )
 
 // Server HTTPサーバーを表現
 type Server struct {
 	audioHandler *websocket.AudioStreamHandler
+	config       *config.ServerConfig
+	mux          *http.ServeMux
+	httpServer   *http.Server
+	middlewares  []Middleware
+	handler      http.Handler
+	handlerOnce  sync.Once
+	tlsOptions   TLSOptions
+	ctx          context.Context
+	cancel       context.CancelFunc
 }
 
 // NewServer 新しいHTTPサーバーを作成
-func NewServer(audioHandler *websocket.AudioStreamHandler) *Server {
-	return &Server{
+func NewServer(audioHandler *websocket.AudioStreamHandler, cfg *config.ServerConfig) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
 		audioHandler: audioHandler,
+		config:       cfg,
+		mux:          http.NewServeMux(),
+		middlewares:  []Middleware{RequestID, AccessLog, Recovery},
+		tlsOptions: TLSOptions{
+			CertFile:          cfg.TLSCertFile,
+			KeyFile:           cfg.TLSKeyFile,
+			ClientCAFile:      cfg.TLSClientCAFile,
+			RequireClientCert: cfg.TLSRequireClientCert,
+			ReloadInterval:    cfg.TLSReloadInterval,
+		},
+		ctx:    ctx,
+		cancel: cancel,
 	}
+
+	s.httpServer = &http.Server{
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		// WriteTimeoutはWebSocket等の長時間接続を切断するため設定しない
+	}
+
+	return s
+}
+
+// Use ミドルウェアを追加（Handler/Start呼び出し前に使用すること）
+func (s *Server) Use(middlewares ...Middleware) {
+	s.middlewares = append(s.middlewares, middlewares...)
 }
 
 // SetupRoutes HTTPルートを設定
 func (s *Server) SetupRoutes() {
-	http.HandleFunc("/audio", s.audioHandler.HandleWebSocket)
+	s.mux.HandleFunc(s.Path("/audio"), s.audioHandler.HandleWebSocket)
+}
+
+// Handle パスプレフィックスを付与してルートを追加
+// パターンは "/path" または "METHOD /path" 形式
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		s.mux.Handle(method+" "+s.Path(path), handler)
+		return
+	}
+	s.mux.Handle(s.Path(pattern), handler)
+}
+
+// HandleFunc パスプレフィックスを付与してハンドラー関数のルートを追加
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// Path パスプレフィックスを付与したパスを返す
+func (s *Server) Path(path string) string {
+	prefix := strings.TrimRight(s.config.PathPrefix, "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix + path
+}
+
+// Handler ミドルウェアを適用したルートハンドラーを返す（httptest.NewServerでマウント可能）
+func (s *Server) Handler() http.Handler {
+	s.handlerOnce.Do(func() {
+		s.SetupRoutes()
+		s.handler = Chain(s.mux, s.middlewares...)
+	})
+	return s.handler
 }
 
 // Start HTTPサーバーを開始
 func (s *Server) Start(addr string) error {
-	s.SetupRoutes()
-	log.Printf("音声ストリーミングサーバーがリスニング中: %s", addr)
-	log.Printf("接続先: ws://%s/audio", addr)
-	return http.ListenAndServe(addr, nil)
+	s.httpServer.Addr = addr
+	s.httpServer.Handler = s.Handler()
+
+	if !s.tlsOptions.Enabled() {
+		log.Printf("音声ストリーミングサーバーがリスニング中: %s", addr)
+		log.Printf("接続先: ws://%s%s", addr, s.Path("/audio"))
+		return ignoreServerClosed(s.httpServer.ListenAndServe())
+	}
+
+	reloader, err := NewCertReloader(s.tlsOptions)
+	if err != nil {
+		return err
+	}
+	reloader.Watch(s.ctx)
+	s.httpServer.TLSConfig = reloader.TLSConfig()
+
+	log.Printf("音声ストリーミングサーバーがTLSでリスニング中: %s (クライアント証明書必須=%t)",
+		addr, s.tlsOptions.RequireClientCert)
+	log.Printf("接続先: wss://%s%s", addr, s.Path("/audio"))
+	// 証明書はTLSConfigから供給されるためファイル指定は不要
+	return ignoreServerClosed(s.httpServer.ListenAndServeTLS("", ""))
+}
+
+// ignoreServerClosed Shutdownによる正常終了をエラーとして扱わない
+func ignoreServerClosed(err error) error {
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown HTTPサーバーを正常に停止
+func (s *Server) Shutdown(ctx context.Context) error {
+	log.Println("HTTPサーバーを停止中...")
+	s.cancel()
+	return s.httpServer.Shutdown(ctx)
 }
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"socket_inference/internal/config"
+)
+
+// newTestServer ルートを追加したサーバーを httptest.NewServer でマウント
+func newTestServer(t *testing.T, cfg *config.ServerConfig) *httptest.Server {
+	t.Helper()
+
+	s := NewServer(nil, cfg)
+	s.HandleFunc("GET /ping", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "pong:"+RequestIDFromContext(r.Context()))
+	})
+	s.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("テスト用のpanic")
+	})
+
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestServerRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		prefix     string
+		method     string
+		path       string
+		requestID  string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "ルート", method: http.MethodGet, path: "/ping", requestID: "req-1", wantStatus: http.StatusOK, wantBody: "pong:req-1"},
+		{name: "プレフィックス付きのルート", prefix: "api", method: http.MethodGet, path: "/api/ping", requestID: "req-2", wantStatus: http.StatusOK, wantBody: "pong:req-2"},
+		{name: "プレフィックスのないパス", prefix: "/api/", method: http.MethodGet, path: "/ping", wantStatus: http.StatusNotFound},
+		{name: "許可されていないメソッド", method: http.MethodPost, path: "/ping", wantStatus: http.StatusMethodNotAllowed},
+		{name: "panicは500", method: http.MethodGet, path: "/panic", wantStatus: http.StatusInternalServerError},
+		{name: "未登録のパス", method: http.MethodGet, path: "/unknown", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, &config.ServerConfig{PathPrefix: tt.prefix})
+
+			request, err := http.NewRequest(tt.method, ts.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.requestID != "" {
+				request.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			response, err := ts.Client().Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+			body, _ := io.ReadAll(response.Body)
+
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("ステータス = %d（期待値 %d）", response.StatusCode, tt.wantStatus)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Fatalf("ボディ = %q（期待値 %q）", body, tt.wantBody)
+			}
+			requestID := response.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				t.Fatal("レスポンスにリクエストIDがありません")
+			}
+			if tt.requestID != "" && requestID != tt.requestID {
+				t.Fatalf("リクエストID = %q（期待値 %q）", requestID, tt.requestID)
+			}
+		})
+	}
+}
+
+func TestServerAppliesTimeouts(t *testing.T) {
+	cfg := &config.ServerConfig{
+		ReadHeaderTimeout: 3 * time.Second,
+		ReadTimeout:       20 * time.Second,
+		IdleTimeout:       40 * time.Second,
+		MaxHeaderBytes:    4096,
+	}
+	s := NewServer(nil, cfg)
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"ReadHeaderTimeout", s.httpServer.ReadHeaderTimeout, cfg.ReadHeaderTimeout},
+		{"ReadTimeout", s.httpServer.ReadTimeout, cfg.ReadTimeout},
+		{"IdleTimeout", s.httpServer.IdleTimeout, cfg.IdleTimeout},
+		{"MaxHeaderBytes", s.httpServer.MaxHeaderBytes, cfg.MaxHeaderBytes},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v（期待値 %v）", tt.name, tt.got, tt.want)
+		}
+	}
+}
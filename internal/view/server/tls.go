@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSOptions TLS終端の設定
+type TLSOptions struct {
+	CertFile          string        // サーバー証明書ファイル
+	KeyFile           string        // サーバー秘密鍵ファイル
+	ClientCAFile      string        // クライアント証明書検証用CAバンドル（mTLS）
+	RequireClientCert bool          // クライアント証明書を必須にするか
+	ReloadInterval    time.Duration // 証明書ファイルの変更確認間隔
+}
+
+// Enabled TLSが設定されているか
+func (o TLSOptions) Enabled() bool {
+	return o.CertFile != "" && o.KeyFile != ""
+}
+
+// CertReloader 証明書とCAバンドルをファイル変更時に再読み込み
+type CertReloader struct {
+	mu       sync.RWMutex
+	options  TLSOptions
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// NewCertReloader 新しいCertReloaderを作成し、初回の読み込みを行う
+func NewCertReloader(options TLSOptions) (*CertReloader, error) {
+	if !options.Enabled() {
+		return nil, fmt.Errorf("TLS証明書と秘密鍵のパスが必要です")
+	}
+	if options.RequireClientCert && options.ClientCAFile == "" {
+		return nil, fmt.Errorf("クライアント証明書を必須にするにはCAバンドルが必要です")
+	}
+	if options.ReloadInterval <= 0 {
+		options.ReloadInterval = 30 * time.Second
+	}
+
+	cr := &CertReloader{
+		options:  options,
+		modTimes: make(map[string]time.Time),
+	}
+	if err := cr.reload(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// TLSConfig 再読み込みに追従するtls.Configを返す
+func (cr *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cr.mu.RLock()
+			defer cr.mu.RUnlock()
+
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cr.cert},
+			}
+			if cr.clientCA != nil {
+				cfg.ClientCAs = cr.clientCA
+				cfg.ClientAuth = tls.VerifyClientCertIfGiven
+				if cr.options.RequireClientCert {
+					cfg.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// Watch 証明書ファイルの変更を定期的に確認して再読み込みするgoroutineを開始
+func (cr *CertReloader) Watch(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(cr.options.ReloadInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !cr.changed() {
+					continue
+				}
+				if err := cr.reload(); err != nil {
+					// 読み込みに失敗した場合は現在の証明書を使い続ける
+					log.Printf("TLS証明書の再読み込み失敗: %v", err)
+					continue
+				}
+				log.Printf("TLS証明書を再読み込みしました: %s", cr.options.CertFile)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// files 監視対象のファイル一覧
+func (cr *CertReloader) files() []string {
+	files := []string{cr.options.CertFile, cr.options.KeyFile}
+	if cr.options.ClientCAFile != "" {
+		files = append(files, cr.options.ClientCAFile)
+	}
+	return files
+}
+
+// changed 前回の読み込みからファイルが更新されたか
+func (cr *CertReloader) changed() bool {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	for _, file := range cr.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(cr.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// reload 証明書とCAバンドルを読み込み
+func (cr *CertReloader) reload() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range cr.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("TLSファイル確認失敗: %w", err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.options.CertFile, cr.options.KeyFile)
+	if err != nil {
+		return fmt.Errorf("TLS証明書読み込み失敗: %w", err)
+	}
+
+	var clientCA *x509.CertPool
+	if cr.options.ClientCAFile != "" {
+		pem, err := os.ReadFile(cr.options.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("CAバンドル読み込み失敗: %w", err)
+		}
+		clientCA = x509.NewCertPool()
+		if !clientCA.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("CAバンドルに有効な証明書がありません: %s", cr.options.ClientCAFile)
+		}
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.cert = &cert
+	cr.clientCA = clientCA
+	cr.modTimes = modTimes
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"socket_inference/internal/model"
+	"socket_inference/internal/viewmodel/interfaces"
 )
 
 // AudioBatcher 推論処理用の音声データバッチ化を処理
@@ -17,6 +18,10 @@ type AudioBatcher struct {
 	flushTimeout time.Duration          // フラッシュタイムアウト
 	batchReady   chan *model.AudioBatch // 完成したバッチを送信するチャネル
 	lastFlush    map[string]time.Time   // clientID -> 最後のフラッシュ時間
+	sequence     map[string]uint64      // clientID -> 次のバッチ番号
+	endpointer   interfaces.SpeechEndpointer
+	maxChunks    int                     // 発話単位でバッチを区切る場合の最大チャンク数
+	onDrop       func(*model.AudioBatch) // チャネルが満杯で破棄したバッチの通知先（nilの場合は通知しない）
 }
 
 // NewAudioBatcher 新しいAudioBatcherを作成
@@ -27,27 +32,91 @@ func NewAudioBatcher(batchSize int, flushTimeout time.Duration) *AudioBatcher {
 		flushTimeout: flushTimeout,
 		batchReady:   make(chan *model.AudioBatch, 100),
 		lastFlush:    make(map[string]time.Time),
+		sequence:     make(map[string]uint64),
 	}
 }
 
 // AddAudioData 音声データをバッファに追加し、バッチ準備状況をチェック
+// 発話終端の判定（復号・VAD）は他のセッションを待たせないようロックの外で行う
 func (ab *AudioBatcher) AddAudioData(clientID string, audioData []byte) {
 	ab.mu.Lock()
-	defer ab.mu.Unlock()
+	endpointer := ab.endpointer
+	ab.mu.Unlock()
+
+	var endOfSpeech, tracked bool
+	if endpointer != nil {
+		endOfSpeech, tracked = endpointer.ObserveChunk(clientID, audioData)
+	}
 
-	// 音声データをバッファに追加
+	ab.mu.Lock()
+	dropped := ab.addAudioData(clientID, audioData, endOfSpeech, tracked)
+	onDrop := ab.onDrop
+	ab.mu.Unlock()
+
+	notifyDropped(onDrop, dropped)
+}
+
+// addAudioData 音声データをバッファに追加し、バッチ化できればフラッシュ（ab.mu を保持して呼び出す）
+// tracked はエンドポインターが判定したセッションか、endOfSpeech はチャンクが発話の終端を含むか
+// チャネルが満杯で破棄したバッチを返す
+func (ab *AudioBatcher) addAudioData(clientID string, audioData []byte, endOfSpeech, tracked bool) *model.AudioBatch {
+	// 音声データをバッファに追加（最初のチャンクからフラッシュタイムアウトを計測）
+	if len(ab.audioBuffer[clientID]) == 0 {
+		ab.lastFlush[clientID] = time.Now()
+	}
 	ab.audioBuffer[clientID] = append(ab.audioBuffer[clientID], audioData)
 
+	// 発話の終端で区切るセッションは終端または最大チャンク数でバッチ化
+	if tracked {
+		if endOfSpeech || len(ab.audioBuffer[clientID]) >= ab.maxChunks {
+			return ab.flushBatch(clientID)
+		}
+		return nil
+	}
+
 	// バッチサイズに達したかチェック
 	if len(ab.audioBuffer[clientID]) >= ab.batchSize {
-		ab.flushBatch(clientID)
+		return ab.flushBatch(clientID)
+	}
+	return nil
+}
+
+// SetEndpointer 発話の終端でバッチを区切るよう設定
+// エンドポインターに登録されたセッションのみが対象で、それ以外はチャンク数で区切る
+func (ab *AudioBatcher) SetEndpointer(endpointer interfaces.SpeechEndpointer, maxChunks int) {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	ab.endpointer = endpointer
+	ab.maxChunks = maxChunks
+}
+
+// SetDropHandler チャネルが満杯で破棄したバッチの通知先を設定
+// handler はロックの外で、バッチを破棄した呼び出し元のgoroutineから呼び出される
+func (ab *AudioBatcher) SetDropHandler(handler func(batch *model.AudioBatch)) {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	ab.onDrop = handler
+}
+
+// notifyDropped 破棄したバッチを通知先に渡す
+func notifyDropped(onDrop func(*model.AudioBatch), dropped ...*model.AudioBatch) {
+	if onDrop == nil {
+		return
+	}
+	for _, batch := range dropped {
+		if batch != nil {
+			onDrop(batch)
+		}
 	}
 }
 
 // flushBatch バッチを作成し、準備完了チャネルに送信
-func (ab *AudioBatcher) flushBatch(clientID string) {
+// チャネルが満杯の場合はバッチを破棄して返す
+func (ab *AudioBatcher) flushBatch(clientID string) *model.AudioBatch {
 	if len(ab.audioBuffer[clientID]) == 0 {
-		return
+		return nil
 	}
 
 	batch := &model.AudioBatch{
@@ -55,7 +124,9 @@ func (ab *AudioBatcher) flushBatch(clientID string) {
 		AudioData: make([][]byte, len(ab.audioBuffer[clientID])),
 		Timestamp: time.Now(),
 		BatchSize: len(ab.audioBuffer[clientID]),
+		Sequence:  ab.sequence[clientID],
 	}
+	ab.sequence[clientID]++
 
 	// データをコピー
 	copy(batch.AudioData, ab.audioBuffer[clientID])
@@ -68,17 +139,42 @@ func (ab *AudioBatcher) flushBatch(clientID string) {
 	select {
 	case ab.batchReady <- batch:
 		log.Printf("クライアント %s のバッチ準備完了: %d 音声チャンク", clientID, batch.BatchSize)
+		return nil
 	default:
 		log.Printf("バッチチャネルが満杯、クライアント %s のバッチを破棄", clientID)
+		return batch
 	}
 }
 
+// FlushClient 指定クライアントのバッファ済みデータを即座にバッチ化し、バッファを解放
+func (ab *AudioBatcher) FlushClient(clientID string) {
+	ab.mu.Lock()
+	dropped := ab.flushBatch(clientID)
+	delete(ab.audioBuffer, clientID)
+	delete(ab.lastFlush, clientID)
+	delete(ab.sequence, clientID)
+	onDrop := ab.onDrop
+	ab.mu.Unlock()
+
+	notifyDropped(onDrop, dropped)
+}
+
+// RemoveClient 指定クライアントのバッファ済みデータを破棄し、バッチ番号等の状態を解放
+func (ab *AudioBatcher) RemoveClient(clientID string) {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+
+	delete(ab.audioBuffer, clientID)
+	delete(ab.lastFlush, clientID)
+	delete(ab.sequence, clientID)
+}
+
 // StartPeriodicFlush 古いデータを定期的にフラッシュするgoroutineを開始
 func (ab *AudioBatcher) StartPeriodicFlush(ctx context.Context) {
-	ticker := time.NewTicker(ab.flushTimeout)
-	defer ticker.Stop()
-
 	go func() {
+		ticker := time.NewTicker(ab.flushTimeout)
+		defer ticker.Stop()
+
 		for {
 			select {
 			case <-ticker.C:
@@ -93,15 +189,18 @@ func (ab *AudioBatcher) StartPeriodicFlush(ctx context.Context) {
 // flushOldBatches 長時間待機しているバッチをフラッシュ
 func (ab *AudioBatcher) flushOldBatches() {
 	ab.mu.Lock()
-	defer ab.mu.Unlock()
-
+	var dropped []*model.AudioBatch
 	now := time.Now()
 	for clientID, lastFlush := range ab.lastFlush {
 		if now.Sub(lastFlush) > ab.flushTimeout && len(ab.audioBuffer[clientID]) > 0 {
 			log.Printf("クライアント %s の古いバッチをフラッシュ（タイムアウト）", clientID)
-			ab.flushBatch(clientID)
+			dropped = append(dropped, ab.flushBatch(clientID))
 		}
 	}
+	onDrop := ab.onDrop
+	ab.mu.Unlock()
+
+	notifyDropped(onDrop, dropped...)
 }
 
 // GetBatchReady バッチ準備完了チャネルを返す
@@ -0,0 +1,81 @@
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+)
+
+// blockingEndpointer blocked のセッションの判定が解放されるまで戻らないテスト用のエンドポインター
+type blockingEndpointer struct {
+	blocked string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (e *blockingEndpointer) RegisterSession(clientID string, format model.AudioFormat) error {
+	return nil
+}
+
+func (e *blockingEndpointer) UnregisterSession(clientID string) {}
+
+func (e *blockingEndpointer) ObserveChunk(clientID string, chunk []byte) (bool, bool) {
+	if clientID == e.blocked {
+		close(e.entered)
+		<-e.release
+	}
+	return false, false
+}
+
+func TestAudioBatcherObservesChunksOutsideLock(t *testing.T) {
+	endpointer := &blockingEndpointer{blocked: "slow", entered: make(chan struct{}), release: make(chan struct{})}
+	batcher := NewAudioBatcher(1, time.Minute)
+	batcher.SetEndpointer(endpointer, 10)
+
+	slowDone := make(chan struct{})
+	go func() {
+		defer close(slowDone)
+		batcher.AddAudioData("slow", []byte{0})
+	}()
+	<-endpointer.entered
+
+	// 判定中のセッションがあっても、他のセッションのバッチ化が待たされないこと
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		batcher.AddAudioData("fast", []byte{0})
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("他のセッションの発話終端判定にバッチ化が待たされました")
+	}
+
+	close(endpointer.release)
+	<-slowDone
+}
+
+func TestAudioBatcherRemoveClient(t *testing.T) {
+	batcher := NewAudioBatcher(2, time.Minute)
+	batcher.AddAudioData("session", []byte{0})
+	batcher.AddAudioData("session", []byte{0})
+	batcher.AddAudioData("session", []byte{0})
+	if batch := <-batcher.GetBatchReady(); batch.Sequence != 0 {
+		t.Fatalf("バッチ番号 = %d（期待値 0）", batch.Sequence)
+	}
+
+	batcher.RemoveClient("session")
+
+	batcher.mu.Lock()
+	buffered, flushed, sequenced := len(batcher.audioBuffer), len(batcher.lastFlush), len(batcher.sequence)
+	batcher.mu.Unlock()
+	if buffered != 0 || flushed != 0 || sequenced != 0 {
+		t.Fatalf("切断後も状態が残っています: バッファ=%d, フラッシュ時刻=%d, バッチ番号=%d", buffered, flushed, sequenced)
+	}
+	select {
+	case batch := <-batcher.GetBatchReady():
+		t.Fatalf("破棄したデータがバッチ化されました: %+v", batch)
+	default:
+	}
+}
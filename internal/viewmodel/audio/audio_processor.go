@@ -27,6 +27,26 @@ func (p *Processor) ProcessAudioData(clientID string, audioData []byte) {
 	p.batcher.AddAudioData(clientID, audioData)
 }
 
+// FlushClient 指定クライアントのバッファ済みデータを即座にバッチ化
+func (p *Processor) FlushClient(clientID string) {
+	p.batcher.FlushClient(clientID)
+}
+
+// RemoveClient 指定クライアントのバッファ済みデータを破棄し、状態を解放
+func (p *Processor) RemoveClient(clientID string) {
+	p.batcher.RemoveClient(clientID)
+}
+
+// SetEndpointer 発話の終端でバッチを区切るよう設定
+func (p *Processor) SetEndpointer(endpointer interfaces.SpeechEndpointer, maxChunks int) {
+	p.batcher.SetEndpointer(endpointer, maxChunks)
+}
+
+// SetDropHandler チャネルが満杯で破棄したバッチの通知先を設定
+func (p *Processor) SetDropHandler(handler func(batch *model.AudioBatch)) {
+	p.batcher.SetDropHandler(handler)
+}
+
 // GetBatchReady 完成したバッチを受信するチャネルを取得
 func (p *Processor) GetBatchReady() <-chan *model.AudioBatch {
 	return p.batcher.GetBatchReady()
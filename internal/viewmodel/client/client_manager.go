@@ -27,7 +27,7 @@ func (cm *Manager) RegisterClient(client *model.AudioClient) {
 	defer cm.mu.Unlock()
 
 	cm.clients[client] = true
-	log.Printf("音声クライアント接続: %s (合計: %d)", client.ClientID, len(cm.clients))
+	log.Printf("音声クライアント接続: セッション=%s, クライアント=%s (合計: %d)", client.SessionID, client.ClientID, len(cm.clients))
 }
 
 // UnregisterClient クライアントの登録を解除
@@ -37,7 +37,7 @@ func (cm *Manager) UnregisterClient(client *model.AudioClient) {
 
 	if _, ok := cm.clients[client]; ok {
 		delete(cm.clients, client)
-		log.Printf("音声クライアント切断: %s (合計: %d)", client.ClientID, len(cm.clients))
+		log.Printf("音声クライアント切断: セッション=%s, クライアント=%s (合計: %d)", client.SessionID, client.ClientID, len(cm.clients))
 	}
 }
 
@@ -53,6 +53,20 @@ func (cm *Manager) GetConnectedClients() []*model.AudioClient {
 	return clients
 }
 
+// GetClientsBySession 指定セッションの接続中のクライアントを取得
+func (cm *Manager) GetClientsBySession(sessionID string) []*model.AudioClient {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	var clients []*model.AudioClient
+	for client := range cm.clients {
+		if client.SessionID == sessionID {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
 // GetClientCount 接続中のクライアント数を取得
 func (cm *Manager) GetClientCount() int {
 	cm.mu.RLock()
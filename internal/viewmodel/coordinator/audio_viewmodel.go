@@ -2,7 +2,9 @@ package coordinator
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"socket_inference/internal/infrastructure/interfaces"
@@ -11,6 +13,8 @@ import (
 	"socket_inference/internal/viewmodel/client"
 	"socket_inference/internal/viewmodel/inference"
 	vmInterfaces "socket_inference/internal/viewmodel/interfaces"
+	"socket_inference/internal/viewmodel/recognition"
+	"socket_inference/internal/viewmodel/result"
 )
 
 // AudioViewModel 軽量化された全体調整ViewModelの実装
@@ -18,26 +22,40 @@ type AudioViewModel struct {
 	clientManager    vmInterfaces.ClientManager
 	audioProcessor   vmInterfaces.AudioProcessor
 	inferenceManager vmInterfaces.InferenceManager
+	recognitionJobs  vmInterfaces.RecognitionJobManager
+	resultBroker     vmInterfaces.ResultBroker
+	endpointer       vmInterfaces.SpeechEndpointer // 発話単位のバッチ化（無効の場合はnil）
+	modelRegistry    interfaces.ModelRegistry      // 推論モデルの対応表（nilの場合は全セッションが同じ推論クライアントを使用）
+	writersMu        sync.RWMutex
+	writers          map[*model.AudioClient]*clientWriter // クライアント毎の送信goroutine
+	batchSize        int
 	ctx              context.Context
 	cancel           context.CancelFunc
 }
 
 // NewAudioViewModel 新しいAudioViewModelを作成
-func NewAudioViewModel(inferenceClient interfaces.InferenceClient) *AudioViewModel {
+// inferenceOptions で推論処理のワーカープール、jobOptions で非同期ファイル推論ジョブの実行を設定する
+func NewAudioViewModel(inferenceClient interfaces.InferenceClient, inferenceOptions inference.ManagerOptions, jobOptions recognition.JobOptions) *AudioViewModel {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// 各コンポーネントを初期化
+	batchSize := 10
 	clientManager := client.NewManager()
-	audioProcessor := audio.NewProcessor(10, 2*time.Second)
-	inferenceManager := inference.NewManager(inferenceClient)
+	audioProcessor := audio.NewProcessor(batchSize, 2*time.Second)
+	inferenceManager := inference.NewManagerWithOptions(inferenceClient, inferenceOptions)
 
 	vm := &AudioViewModel{
 		clientManager:    clientManager,
 		audioProcessor:   audioProcessor,
 		inferenceManager: inferenceManager,
+		resultBroker:     result.NewBroker(100, 10*time.Minute),
+		writers:          make(map[*model.AudioClient]*clientWriter),
+		batchSize:        batchSize,
 		ctx:              ctx,
 		cancel:           cancel,
 	}
+	vm.recognitionJobs = recognition.NewJobManager(vm, jobOptions)
+	audioProcessor.SetDropHandler(vm.batchDropped)
 
 	// バックグラウンド処理を開始
 	vm.startProcessing()
@@ -46,18 +64,114 @@ func NewAudioViewModel(inferenceClient interfaces.InferenceClient) *AudioViewMod
 }
 
 // RegisterClient 新しい音声クライアントを登録
-func (vm *AudioViewModel) RegisterClient(client *model.AudioClient) {
+// フォーマット未宣言の場合はデフォルトフォーマットを使用
+// ctx（接続のコンテキスト）が取り消されると、セッションの送信中の推論を中止する
+func (vm *AudioViewModel) RegisterClient(ctx context.Context, client *model.AudioClient) error {
+	return vm.registerClient(ctx, client, true)
+}
+
+// registerClient 音声クライアントを登録
+// 前処理・推論・結果配信の状態は接続毎に一意の SessionID をキーにする（ClientID は重複し得る）
+// endpointing が true で発話単位のバッチ化が有効な場合はエンドポインターにも登録する
+// セッション固有の前処理設定がない場合は推論モデルのデフォルトの前処理設定を使用する
+func (vm *AudioViewModel) registerClient(ctx context.Context, client *model.AudioClient, endpointing bool) error {
+	if client.SessionID == "" {
+		return fmt.Errorf("クライアント %s のセッションIDがありません", client.ClientID)
+	}
+	if client.Format == (model.AudioFormat{}) {
+		client.Format = model.DefaultAudioFormat()
+	}
+	info, inferenceClient, err := vm.resolveModel(client)
+	if err != nil {
+		return err
+	}
+	if err := vm.inferenceManager.RegisterSession(ctx, client.SessionID, client.Format); err != nil {
+		return err
+	}
+	if inferenceClient != nil {
+		if err := vm.inferenceManager.SetSessionModel(client.SessionID, info, inferenceClient); err != nil {
+			vm.inferenceManager.UnregisterSession(client.SessionID)
+			return err
+		}
+	}
+	preprocessing := client.Preprocessing
+	if preprocessing == nil {
+		preprocessing = info.Preprocessing
+	}
+	if preprocessing != nil {
+		if err := vm.inferenceManager.SetSessionPreprocessingConfig(client.SessionID, *preprocessing); err != nil {
+			vm.inferenceManager.UnregisterSession(client.SessionID)
+			return err
+		}
+	}
+	if err := vm.inferenceManager.SetSessionTask(client.SessionID, client.Task); err != nil {
+		vm.inferenceManager.UnregisterSession(client.SessionID)
+		return err
+	}
+	if err := vm.inferenceManager.SetSessionTimeout(client.SessionID, client.InferenceTimeout); err != nil {
+		vm.inferenceManager.UnregisterSession(client.SessionID)
+		return err
+	}
+	if endpointing && vm.endpointer != nil {
+		if err := vm.endpointer.RegisterSession(client.SessionID, client.Format); err != nil {
+			vm.inferenceManager.UnregisterSession(client.SessionID)
+			return err
+		}
+	}
+
+	vm.resultBroker.Open(client.SessionID, client.ClientID)
+	if client.Sender != nil {
+		vm.writersMu.Lock()
+		vm.writers[client] = newClientWriter(vm.ctx, client)
+		vm.writersMu.Unlock()
+	}
 	vm.clientManager.RegisterClient(client)
+	return nil
+}
+
+// resolveModel セッションが宣言した推論モデルを解決し、宣言をモデルの名前・バージョンに確定する
+// 推論モデルの対応表がない場合はゼロ値を返す（マネージャーの推論クライアントを使用）
+func (vm *AudioViewModel) resolveModel(client *model.AudioClient) (model.ModelInfo, interfaces.InferenceClient, error) {
+	if vm.modelRegistry == nil {
+		return model.ModelInfo{}, nil, nil
+	}
+	info, inferenceClient, err := vm.modelRegistry.Route(client.SessionID, client.Model)
+	if err != nil {
+		return model.ModelInfo{}, nil, err
+	}
+
+	task := client.Task.Type
+	if task == "" {
+		task = model.DefaultTaskConfig().Type
+	}
+	if !info.SupportsTask(task) {
+		return model.ModelInfo{}, nil, fmt.Errorf("%w: 推論モデル %s は %s に対応していません", model.ErrUnsupportedTask, info.ID(), task)
+	}
+
+	client.Model = model.ModelSelection{Name: info.Name, Version: info.Version}
+	return info, inferenceClient, nil
 }
 
 // UnregisterClient 音声クライアントの登録を解除
 func (vm *AudioViewModel) UnregisterClient(client *model.AudioClient) {
 	vm.clientManager.UnregisterClient(client)
+	vm.writersMu.Lock()
+	if w, ok := vm.writers[client]; ok {
+		w.stop()
+		delete(vm.writers, client)
+	}
+	vm.writersMu.Unlock()
+	vm.resultBroker.Close(client.SessionID)
+	vm.audioProcessor.RemoveClient(client.SessionID)
+	vm.inferenceManager.UnregisterSession(client.SessionID)
+	if vm.endpointer != nil {
+		vm.endpointer.UnregisterSession(client.SessionID)
+	}
 }
 
-// ProcessAudioData 受信した音声データを処理
-func (vm *AudioViewModel) ProcessAudioData(clientID string, audioData []byte) {
-	vm.audioProcessor.ProcessAudioData(clientID, audioData)
+// ProcessAudioData 受信した音声データをセッションのバッチに追加
+func (vm *AudioViewModel) ProcessAudioData(sessionID string, audioData []byte) {
+	vm.audioProcessor.ProcessAudioData(sessionID, audioData)
 }
 
 // startProcessing バックグラウンド処理を開始
@@ -71,6 +185,9 @@ func (vm *AudioViewModel) startProcessing() {
 	// 推論結果の処理を開始
 	go vm.processInferenceResults()
 
+	// セッションイベントの処理を開始
+	go vm.processSessionEvents()
+
 	log.Println("AudioViewModel: 全てのバックグラウンド処理を開始しました")
 }
 
@@ -78,21 +195,133 @@ func (vm *AudioViewModel) startProcessing() {
 func (vm *AudioViewModel) processInferenceResults() {
 	for {
 		select {
-		case result := <-vm.inferenceManager.GetResultChannel():
-			// TODO: 推論結果をクライアントに送信
+		case result, ok := <-vm.inferenceManager.GetResultChannel():
+			if !ok {
+				return
+			}
 			log.Printf("推論結果受信: クライアント=%s, 結果=%s, 信頼度=%.2f",
 				result.ClientID, result.Result, result.Confidence)
+			vm.deliverResult(result)
+		case <-vm.ctx.Done():
+			return
+		}
+	}
+}
+
+// deliverResult 推論結果をセッションの購読者と該当クライアントの送信待ち行列に追加
+// 推論結果の ClientID はセッションID
+func (vm *AudioViewModel) deliverResult(result *model.InferenceResponse) {
+	vm.resultBroker.Publish(result)
+	vm.enqueue(result.ClientID, delivery{result: result})
+}
+
+// enqueue セッションのクライアントの送信待ち行列に追加（送信はクライアント毎のgoroutineが行う）
+func (vm *AudioViewModel) enqueue(sessionID string, d delivery) {
+	vm.writersMu.RLock()
+	defer vm.writersMu.RUnlock()
+
+	for _, client := range vm.clientManager.GetClientsBySession(sessionID) {
+		if w, ok := vm.writers[client]; ok {
+			w.enqueue(d)
+		}
+	}
+}
+
+// SetPreprocessingConfig 全セッション共通の前処理設定を検証して適用
+func (vm *AudioViewModel) SetPreprocessingConfig(config model.PreprocessingConfig) error {
+	return vm.inferenceManager.SetPreprocessingConfig(config)
+}
+
+// processSessionEvents セッションイベントの処理
+func (vm *AudioViewModel) processSessionEvents() {
+	for {
+		select {
+		case event, ok := <-vm.inferenceManager.GetEventChannel():
+			if !ok {
+				return
+			}
+			log.Printf("セッションイベント: クライアント=%s, 種類=%s, 位置=%dms", event.ClientID, event.Type, event.OffsetMs)
+			vm.deliverEvent(event)
 		case <-vm.ctx.Done():
 			return
 		}
 	}
 }
 
+// deliverEvent セッションイベントを該当クライアントの送信待ち行列に追加
+// イベントの ClientID はセッションID（イベントを受け取れないクライアントには送信しない）
+func (vm *AudioViewModel) deliverEvent(event *model.SessionEvent) {
+	vm.enqueue(event.ClientID, delivery{event: event})
+}
+
+// batchDropped 過負荷でバッチチャネルに入らず破棄したバッチをセッションのクライアントに通知
+func (vm *AudioViewModel) batchDropped(batch *model.AudioBatch) {
+	vm.deliverEvent(&model.SessionEvent{
+		Type:      model.SessionEventBatchDropped,
+		ClientID:  batch.ClientID,
+		Timestamp: time.Now(),
+		Message:   "サーバーが混雑しているため、バッチを推論せずに破棄しました",
+	})
+}
+
+// EnableVADBatching 発話の終端でバッチを区切るよう設定
+// 以降に接続したストリーミングセッションが対象（ファイル推論はチャンク数で区切る）
+// クライアント接続の受け付け前に呼び出すこと
+func (vm *AudioViewModel) EnableVADBatching(config model.VADConfig, maxChunks int) error {
+	endpointer, err := inference.NewVADEndpointer(config)
+	if err != nil {
+		return err
+	}
+	vm.endpointer = endpointer
+	vm.audioProcessor.SetEndpointer(endpointer, maxChunks)
+	log.Printf("発話単位のバッチ化を有効化しました: 最大 %d チャンク", maxChunks)
+	return nil
+}
+
+// SetModelRegistry 推論モデルの対応表を設定
+// 以降に接続したセッションは宣言した推論モデルの推論クライアントに送信する
+// クライアント接続の受け付け前に呼び出すこと
+func (vm *AudioViewModel) SetModelRegistry(registry interfaces.ModelRegistry) {
+	vm.modelRegistry = registry
+}
+
+// ResolveModel セッションが宣言した推論モデルを解決（カナリアへの振り分けを含む）
+// 振り分けは接続毎のセッションIDで決まる。登録されていない場合は *model.UnknownModelError を返す
+func (vm *AudioViewModel) ResolveModel(sessionID string, selection model.ModelSelection) (model.ModelInfo, error) {
+	if vm.modelRegistry == nil {
+		return model.ModelInfo{}, nil
+	}
+	info, _, err := vm.modelRegistry.Route(sessionID, selection)
+	return info, err
+}
+
+// Models 利用可能な推論モデルの一覧を取得
+func (vm *AudioViewModel) Models() []model.ModelInfo {
+	if vm.modelRegistry == nil {
+		return []model.ModelInfo{}
+	}
+	return vm.modelRegistry.Models()
+}
+
+// InferenceStats 推論処理の稼働状況を取得
+func (vm *AudioViewModel) InferenceStats() model.InferenceStats {
+	return vm.inferenceManager.Stats()
+}
+
+// SubscribeResults セッションの推論結果を購読
+// lastEventID より後のバッファ済み結果と、以降の結果を受信するチャネルを返す
+// subscriberID はセッションを開いたクライアントの識別IDと一致する必要がある（匿名のセッションを除く）
+func (vm *AudioViewModel) SubscribeResults(sessionID, subscriberID string, lastEventID uint64) ([]model.ResultEvent, <-chan model.ResultEvent, func(), error) {
+	return vm.resultBroker.Subscribe(sessionID, subscriberID, lastEventID)
+}
+
 // Shutdown AudioViewModelを正常に停止
 func (vm *AudioViewModel) Shutdown() {
 	log.Println("AudioViewModel: シャットダウンを開始します")
 
 	vm.cancel()
+	vm.recognitionJobs.Shutdown()
+	vm.resultBroker.Shutdown()
 	vm.audioProcessor.Shutdown()
 	vm.inferenceManager.Shutdown()
 
@@ -0,0 +1,226 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+	"socket_inference/internal/viewmodel/inference"
+	"socket_inference/internal/viewmodel/recognition"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// echoClient バッチのセッションIDをそのまま返すテスト用の推論クライアント
+type echoClient struct{}
+
+func (echoClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	return &model.InferenceResponse{ClientID: request.ClientID, Result: "ok", IsFinal: true}, nil
+}
+
+func (c echoClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	return c.SendInferenceRequest(ctx, &model.InferenceRequest{ClientID: batch.ClientID})
+}
+
+func (echoClient) Connect(ctx context.Context) error { return nil }
+func (echoClient) Disconnect() error                 { return nil }
+func (echoClient) IsConnected() bool                 { return true }
+func (echoClient) GetServerStatus() (string, error)  { return "connected", nil }
+
+// failingClient 送信毎に send の結果を返すテスト用の推論クライアント
+type failingClient struct {
+	echoClient
+	send func(ctx context.Context) error
+}
+
+func (c failingClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	return nil, c.send(ctx)
+}
+
+// recordingSender 受信した推論結果を記録するResultSender
+type recordingSender struct {
+	mu      sync.Mutex
+	results []*model.InferenceResponse
+	notify  chan struct{}
+}
+
+func newRecordingSender() *recordingSender {
+	return &recordingSender{notify: make(chan struct{}, 16)}
+}
+
+func (s *recordingSender) SendResult(ctx context.Context, response *model.InferenceResponse) error {
+	s.mu.Lock()
+	s.results = append(s.results, response)
+	s.mu.Unlock()
+	s.notify <- struct{}{}
+	return nil
+}
+
+// waitResult 推論結果を1件受信するまで待機
+func (s *recordingSender) waitResult(t *testing.T) *model.InferenceResponse {
+	t.Helper()
+	select {
+	case <-s.notify:
+	case <-time.After(5 * time.Second):
+		t.Fatal("推論結果を受信できません")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.results[len(s.results)-1]
+}
+
+// sendBatch バッチ1つ分の無音でない音声チャンクを送信
+func sendBatch(vm *AudioViewModel, sessionID string) {
+	chunk := make([]byte, 320)
+	for i := range chunk {
+		chunk[i] = byte(i)
+	}
+	for i := 0; i < vm.batchSize; i++ {
+		vm.ProcessAudioData(sessionID, chunk)
+	}
+}
+
+func TestAudioViewModelKeysSessionsByConnection(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientID string
+	}{
+		{name: "匿名の接続", clientID: ""},
+		{name: "同じクライアント証明書を共有する接続", clientID: "shared-cert"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewAudioViewModel(echoClient{}, inference.ManagerOptions{}, recognition.JobOptions{})
+			defer vm.Shutdown()
+
+			first := &model.AudioClient{SessionID: "session-1", ClientID: tt.clientID, Sender: newRecordingSender()}
+			second := &model.AudioClient{SessionID: "session-2", ClientID: tt.clientID, Sender: newRecordingSender()}
+			firstCtx, cancelFirst := context.WithCancel(context.Background())
+			defer cancelFirst()
+			if err := vm.RegisterClient(firstCtx, first); err != nil {
+				t.Fatal(err)
+			}
+			if err := vm.RegisterClient(context.Background(), second); err != nil {
+				t.Fatal(err)
+			}
+
+			// 2つ目の接続の登録で1つ目のセッションが置き換えられていないこと
+			sendBatch(vm, first.SessionID)
+			if got := first.Sender.(*recordingSender).waitResult(t); got.ClientID != first.SessionID {
+				t.Fatalf("1つ目の接続の結果のセッション = %q（期待値 %q）", got.ClientID, first.SessionID)
+			}
+
+			// 1つ目の接続の切断で2つ目のセッションの状態が消えないこと
+			vm.UnregisterClient(first)
+			sendBatch(vm, second.SessionID)
+			if got := second.Sender.(*recordingSender).waitResult(t); got.ClientID != second.SessionID {
+				t.Fatalf("2つ目の接続の結果のセッション = %q（期待値 %q）", got.ClientID, second.SessionID)
+			}
+			vm.UnregisterClient(second)
+
+			first.Sender.(*recordingSender).mu.Lock()
+			defer first.Sender.(*recordingSender).mu.Unlock()
+			if len(first.Sender.(*recordingSender).results) != 1 {
+				t.Fatalf("1つ目の接続が他のセッションの結果を受信しました: %d 件", len(first.Sender.(*recordingSender).results))
+			}
+		})
+	}
+}
+
+// blockingSender 解放されるまで送信が戻らないResultSender
+type blockingSender struct {
+	release chan struct{}
+}
+
+func (s *blockingSender) SendResult(ctx context.Context, response *model.InferenceResponse) error {
+	select {
+	case <-s.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestAudioViewModelSlowClientDoesNotBlockOthers(t *testing.T) {
+	vm := NewAudioViewModel(echoClient{}, inference.ManagerOptions{}, recognition.JobOptions{})
+	defer vm.Shutdown()
+
+	slowSender := &blockingSender{release: make(chan struct{})}
+	defer close(slowSender.release)
+	slow := &model.AudioClient{SessionID: "slow", Sender: slowSender}
+	fast := &model.AudioClient{SessionID: "fast", Sender: newRecordingSender()}
+	if err := vm.RegisterClient(context.Background(), slow); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.RegisterClient(context.Background(), fast); err != nil {
+		t.Fatal(err)
+	}
+
+	// 送信が戻らないクライアントの結果を先に配信しても、他のクライアントへの配信が待たされないこと
+	sendBatch(vm, slow.SessionID)
+	sendBatch(vm, slow.SessionID)
+	sendBatch(vm, fast.SessionID)
+	select {
+	case <-fast.Sender.(*recordingSender).notify:
+	case <-time.After(time.Second):
+		t.Fatal("送信の遅いクライアントに他のクライアントへの配信が待たされました")
+	}
+}
+
+func TestAudioViewModelRejectsClientWithoutSession(t *testing.T) {
+	vm := NewAudioViewModel(echoClient{}, inference.ManagerOptions{}, recognition.JobOptions{})
+	defer vm.Shutdown()
+
+	if err := vm.RegisterClient(context.Background(), &model.AudioClient{ClientID: "client"}); err == nil {
+		t.Fatal("セッションIDのないクライアントが登録されました")
+	}
+}
+
+func TestRecognizeCompletesWhenBatchesFail(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(ctx context.Context) error
+	}{
+		{
+			name: "推論サーバーのエラー",
+			send: func(ctx context.Context) error { return status.Error(codes.Internal, "失敗") },
+		},
+		{
+			name: "推論のタイムアウト",
+			send: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewAudioViewModel(failingClient{send: tt.send}, inference.ManagerOptions{RequestTimeout: 50 * time.Millisecond}, recognition.JobOptions{})
+			defer vm.Shutdown()
+
+			chunks := make([][]byte, 3*vm.batchSize)
+			for i := range chunks {
+				chunks[i] = make([]byte, 320)
+				for j := range chunks[i] {
+					chunks[i][j] = byte(j)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, err := vm.Recognize(ctx, chunks, model.DefaultAudioFormat())
+			if !errors.Is(err, model.ErrRecognitionIncomplete) {
+				t.Fatalf("エラー = %v（%v を期待）", err, model.ErrRecognitionIncomplete)
+			}
+			if ctx.Err() != nil {
+				t.Fatal("推論の失敗を待たずにタイムアウトしました")
+			}
+		})
+	}
+}
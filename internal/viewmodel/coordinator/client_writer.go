@@ -0,0 +1,85 @@
+package coordinator
+
+import (
+	"context"
+	"log"
+
+	"socket_inference/internal/model"
+)
+
+// clientQueueSize クライアント毎に送信を待てる推論結果・イベントの上限
+const clientQueueSize = 64
+
+// delivery クライアントに送信する推論結果またはセッションイベント
+type delivery struct {
+	result *model.InferenceResponse
+	event  *model.SessionEvent
+}
+
+// clientWriter クライアント毎の送信待ち行列と送信goroutine
+// 送信の遅いクライアント（書き込みタイムアウトまで待つ接続等）が他のクライアントへの配信を妨げないよう、
+// 推論結果・イベントはクライアント毎に順に送信する
+type clientWriter struct {
+	client *model.AudioClient
+	queue  chan delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newClientWriter クライアントの送信goroutineを開始
+// ctx の取り消しまたは stop で送信待ちを破棄して終了する
+func newClientWriter(ctx context.Context, client *model.AudioClient) *clientWriter {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &clientWriter{
+		client: client,
+		queue:  make(chan delivery, clientQueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go w.run()
+	return w
+}
+
+// enqueue 送信待ち行列に追加（満杯の場合は破棄）
+func (w *clientWriter) enqueue(d delivery) {
+	select {
+	case w.queue <- d:
+	default:
+		log.Printf("送信待ちが満杯のため破棄: セッション=%s, クライアント=%s", w.client.SessionID, w.client.ClientID)
+	}
+}
+
+// run 送信待ち行列から順にクライアントへ送信
+func (w *clientWriter) run() {
+	for {
+		select {
+		case d := <-w.queue:
+			w.send(d)
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// send 推論結果またはセッションイベントをクライアントに送信
+func (w *clientWriter) send(d delivery) {
+	if d.result != nil {
+		if err := w.client.Sender.SendResult(w.ctx, d.result); err != nil {
+			log.Printf("推論結果送信失敗: セッション=%s, エラー=%v", w.client.SessionID, err)
+		}
+		return
+	}
+
+	sender, ok := w.client.Sender.(model.EventSender)
+	if !ok {
+		return
+	}
+	if err := sender.SendEvent(w.ctx, d.event); err != nil {
+		log.Printf("イベント送信失敗: セッション=%s, エラー=%v", w.client.SessionID, err)
+	}
+}
+
+// stop 送信goroutineを終了
+func (w *clientWriter) stop() {
+	w.cancel()
+}
@@ -0,0 +1,209 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"socket_inference/internal/model"
+	"socket_inference/internal/viewmodel/inference"
+
+	"github.com/google/uuid"
+)
+
+// recognitionWindow ファイル推論で同時に処理待ちにできるバッチ数
+// バッチチャネルが溢れてバッチが破棄されないよう送信側を抑制する
+const recognitionWindow = 4
+
+// Recognize 音声チャンク列を合成セッションで推論し、結果をまとめて返す
+// ストリーミングと同じAudioProcessor/InferenceManagerのパイプラインを通す
+func (vm *AudioViewModel) Recognize(ctx context.Context, chunks [][]byte, format model.AudioFormat) (*model.RecognitionResult, error) {
+	format, err := recognitionFormat(chunks, format)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := "recognize-" + uuid.New().String()
+	collector := newResultCollector()
+	client := &model.AudioClient{
+		SessionID: sessionID,
+		Sender:    collector,
+		Format:    format,
+	}
+
+	// バッチ数を数えて完了を待つため、発話単位ではなくチャンク数でバッチ化する
+	if err := vm.registerClient(ctx, client, false); err != nil {
+		return nil, err
+	}
+	defer vm.UnregisterClient(client)
+
+	log.Printf("ファイル推論開始: セッション=%s, チャンク数=%d", sessionID, len(chunks))
+
+	batches := 0
+	for i, chunk := range chunks {
+		vm.ProcessAudioData(sessionID, chunk)
+
+		if (i+1)%vm.batchSize == 0 {
+			batches++
+			if err := collector.waitFor(ctx, batches-recognitionWindow); err != nil {
+				return collector.result(sessionID, batches), err
+			}
+		}
+	}
+
+	// 端数のチャンクをバッチ化
+	if len(chunks)%vm.batchSize != 0 {
+		batches++
+	}
+	vm.audioProcessor.FlushClient(sessionID)
+
+	if err := collector.waitFor(ctx, batches); err != nil {
+		return collector.result(sessionID, batches), fmt.Errorf("推論結果の待機中に中断: %w", err)
+	}
+
+	log.Printf("ファイル推論完了: セッション=%s, バッチ数=%d", sessionID, batches)
+	return collector.result(sessionID, batches), nil
+}
+
+// SubmitRecognitionJob ファイル推論ジョブを非同期で実行
+// 未対応のフォーマット、実行待ちのジョブが上限に達している場合はジョブを登録せずにエラーを返す
+func (vm *AudioViewModel) SubmitRecognitionJob(chunks [][]byte, format model.AudioFormat) (*model.RecognitionJob, error) {
+	if _, err := recognitionFormat(chunks, format); err != nil {
+		return nil, err
+	}
+	return vm.recognitionJobs.Submit(chunks, format)
+}
+
+// recognitionFormat ファイルの音声フォーマットを決定
+// 先頭がWAVヘッダーの場合はヘッダーのフォーマットを優先する（ヘッダー自体は前処理で取り除かれる）
+func recognitionFormat(chunks [][]byte, declared model.AudioFormat) (model.AudioFormat, error) {
+	var head []byte
+	for _, chunk := range chunks {
+		head = append(head, chunk...)
+		if !inference.IsWAV(head) {
+			return declared, nil
+		}
+
+		header, err := inference.ParseWAVHeader(head)
+		if errors.Is(err, inference.ErrWAVHeaderIncomplete) {
+			continue
+		}
+		if err != nil {
+			return declared, err
+		}
+		return header.Format, nil
+	}
+
+	if len(head) == 0 {
+		return declared, nil
+	}
+	return declared, fmt.Errorf("%w: WAVヘッダーの途中でファイルが終了しています", model.ErrUnsupportedAudioFormat)
+}
+
+// GetRecognitionJob ファイル推論ジョブの状態を取得
+func (vm *AudioViewModel) GetRecognitionJob(jobID string) (*model.RecognitionJob, bool) {
+	return vm.recognitionJobs.GetJob(jobID)
+}
+
+// resultCollector 合成セッションの推論結果を蓄積するResultSender
+// 無音として破棄されたバッチも完了したバッチとして数える
+type resultCollector struct {
+	mu          sync.Mutex
+	results     []*model.InferenceResponse
+	skipped     int
+	unavailable *model.SessionEvent // 推論サーバーを利用できずに破棄されたバッチの通知
+	dropped     *model.SessionEvent // 推論の失敗・過負荷で破棄されたバッチの通知
+	notify      chan struct{}
+}
+
+// newResultCollector 新しいresultCollectorを作成
+func newResultCollector() *resultCollector {
+	return &resultCollector{
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// SendResult 推論結果を蓄積
+func (rc *resultCollector) SendResult(ctx context.Context, response *model.InferenceResponse) error {
+	rc.mu.Lock()
+	rc.results = append(rc.results, response)
+	rc.mu.Unlock()
+
+	rc.signal()
+	return nil
+}
+
+// SendEvent 無音バッチの破棄を完了したバッチとして記録
+// 推論サーバーを利用できない、推論の失敗・過負荷でバッチが破棄された場合は待機を打ち切る
+func (rc *resultCollector) SendEvent(ctx context.Context, event *model.SessionEvent) error {
+	rc.mu.Lock()
+	switch event.Type {
+	case model.SessionEventSilenceSkipped:
+		rc.skipped++
+	case model.SessionEventInferenceUnavailable:
+		rc.unavailable = event
+	case model.SessionEventBatchDropped:
+		rc.dropped = event
+	default:
+		rc.mu.Unlock()
+		return nil
+	}
+	rc.mu.Unlock()
+
+	rc.signal()
+	return nil
+}
+
+// signal 待機中のwaitForに通知
+func (rc *resultCollector) signal() {
+	select {
+	case rc.notify <- struct{}{}:
+	default:
+	}
+}
+
+// waitFor 指定件数のバッチが完了（推論結果の受信または無音として破棄）するまで待機
+func (rc *resultCollector) waitFor(ctx context.Context, count int) error {
+	for {
+		rc.mu.Lock()
+		received := len(rc.results) + rc.skipped
+		unavailable := rc.unavailable
+		dropped := rc.dropped
+		rc.mu.Unlock()
+		if unavailable != nil {
+			return &model.InferenceUnavailableError{
+				Reason:     unavailable.Message,
+				RetryAfter: time.Duration(unavailable.RetryAfterMs) * time.Millisecond,
+			}
+		}
+		if dropped != nil {
+			return fmt.Errorf("%w: %s", model.ErrRecognitionIncomplete, dropped.Message)
+		}
+		if received >= count {
+			return nil
+		}
+
+		select {
+		case <-rc.notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// result 蓄積した推論結果をまとめる
+func (rc *resultCollector) result(sessionID string, batches int) *model.RecognitionResult {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	results := make([]*model.InferenceResponse, len(rc.results))
+	copy(results, rc.results)
+	return &model.RecognitionResult{
+		SessionID:  sessionID,
+		Results:    results,
+		BatchCount: batches,
+	}
+}
@@ -1,51 +1,268 @@
 package inference
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"socket_inference/internal/model"
 	"socket_inference/internal/viewmodel/interfaces"
 )
 
 // Preprocessor 音声前処理の実装
+// mu はセッションの表と共通設定のみを保護し、パイプラインの処理はセッション毎のロックで直列化する
 type Preprocessor struct {
-	params map[string]interface{}
+	mu       sync.Mutex
+	config   model.PreprocessingConfig // 全セッション共通の設定
+	sessions map[string]*sessionState
+}
+
+// sessionState セッション毎の前処理状態
+// フィールドは mu で保護する（同じセッションのバッチを順に処理し、別セッションとは並行に処理する）
+type sessionState struct {
+	mu            sync.Mutex
+	format        model.AudioFormat
+	config        *model.PreprocessingConfig // セッション固有の設定（nilの場合は共通設定）
+	pipeline      *Pipeline                  // ヘッダー検出後に作成（バッチを跨いでフィルター状態を保持）
+	headerChecked bool                       // 先頭のWAVヘッダー検出が完了したか
+	headerBuf     []byte                     // WAVヘッダー解析のため保留中のデータ
+	err           error                      // 未対応フォーマット等、以降のバッチも処理できないエラー
+	position      time.Duration              // 前処理済み音声のセッション先頭からの位置（無音で破棄した区間を含む）
+}
+
+// newSessionState 宣言されたフォーマットでセッション状態を作成
+func newSessionState(format model.AudioFormat) (*sessionState, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	return &sessionState{format: format}, nil
+}
+
+// consumeHeader セッション先頭のWAVヘッダーを検出して取り除き、ヘッダーのフォーマットを適用
+// ヘッダーがチャンクを跨ぐ場合はdataチャンクに到達するまでデータを保留する
+func (s *sessionState) consumeHeader(clientID string, chunk []byte) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.headerChecked {
+		return chunk, nil
+	}
+
+	data := append(s.headerBuf, chunk...)
+	if !IsWAV(data) {
+		s.headerChecked = true
+		s.headerBuf = nil
+		return data, nil
+	}
+
+	header, err := ParseWAVHeader(data)
+	if errors.Is(err, ErrWAVHeaderIncomplete) {
+		s.headerBuf = data
+		return nil, nil
+	}
+	s.headerChecked = true
+	s.headerBuf = nil
+	if err != nil {
+		s.err = err
+		return nil, err
+	}
+
+	s.format = header.Format
+	log.Printf("クライアント %s のWAVヘッダーを検出: %s %dHz %dch %dbit",
+		clientID, header.Format.Encoding, header.Format.SampleRate, header.Format.Channels, header.BitsPerSample)
+	return data[header.DataOffset:], nil
 }
 
 // NewPreprocessor 新しい前処理器を作成
 func NewPreprocessor() interfaces.AudioPreprocessor {
 	return &Preprocessor{
-		params: make(map[string]interface{}),
+		config:   model.DefaultPreprocessingConfig(),
+		sessions: make(map[string]*sessionState),
 	}
 }
 
+// RegisterSession セッションが宣言した音声フォーマットを登録
+func (ap *Preprocessor) RegisterSession(clientID string, format model.AudioFormat) error {
+	state, err := newSessionState(format)
+	if err != nil {
+		return err
+	}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	ap.sessions[clientID] = state
+	log.Printf("クライアント %s の音声フォーマットを登録: %s %dHz %dch",
+		clientID, format.Encoding, format.SampleRate, format.Channels)
+	return nil
+}
+
+// UnregisterSession セッションの状態を破棄
+// 切断後に届いたバッチは前処理せずにエラーとする（推論マネージャーは切断済みのセッションのバッチを推論しない）
+func (ap *Preprocessor) UnregisterSession(clientID string) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	delete(ap.sessions, clientID)
+}
+
 // PreprocessBatch 音声バッチの前処理
-func (ap *Preprocessor) PreprocessBatch(batch *model.AudioBatch) (*model.AudioBatch, error) {
+// セッションの前処理パイプラインで復号・変換し、出力エンコーディングのモノラル音声に変換
+// VADで無音と判定したチャンクと、WAVヘッダーの解析のため保留したチャンクは取り除く
+// （全て取り除いた場合はAudioDataが空のバッチを返す）
+// パイプラインの状態を壊さないよう、取り消しはバッチの処理を始める前にのみ確認する
+func (ap *Preprocessor) PreprocessBatch(ctx context.Context, batch *model.AudioBatch) (*model.AudioBatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("クライアント %s の前処理を中止: %w", batch.ClientID, err)
+	}
 	log.Printf("クライアント %s の音声バッチを前処理中: %d チャンク", batch.ClientID, batch.BatchSize)
 
+	ap.mu.Lock()
+	state, err := ap.sessionFor(batch.ClientID)
+	shared := ap.config
+	ap.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
 	// 前処理済みデータの作成
-	processedData := make([][]byte, len(batch.AudioData))
+	processedData := make([][]byte, 0, len(batch.AudioData))
+	var events []model.SessionEvent
+	var frames [][]float32
+	var offset, duration time.Duration
+	offsetSet := false
+	held := 0
 	for i, chunk := range batch.AudioData {
-		processedData[i] = make([]byte, len(chunk))
-		copy(processedData[i], chunk)
+		audio, err := state.consumeHeader(batch.ClientID, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("クライアント %s の音声を処理できません: %w", batch.ClientID, err)
+		}
 
-		// TODO: 実際の前処理をここに追加
-		// - 音声フォーマット変換
-		// - 正規化
-		// - ノイズ除去
-		// - 特徴量抽出
+		// フォーマットが確定するまではパイプラインを作成しない（WAVヘッダーでフォーマットが変わる場合がある）
+		if !state.headerChecked {
+			held++
+			continue
+		}
+		if state.pipeline == nil {
+			state.pipeline, err = NewPipeline(batch.ClientID, state.format, state.configOr(shared))
+			if err != nil {
+				state.err = err
+				return nil, fmt.Errorf("クライアント %s の前処理パイプラインを作成できません: %w", batch.ClientID, err)
+			}
+			log.Printf("クライアント %s の前処理パイプライン: %v", batch.ClientID, state.pipeline.StageNames())
+		}
+
+		result, err := state.pipeline.Process(audio)
+		if err != nil {
+			return nil, fmt.Errorf("クライアント %s のチャンク %d が不正です: %w", batch.ClientID, i, err)
+		}
+		events = append(events, result.Events...)
+		if !result.Silent {
+			processedData = append(processedData, result.Data)
+			frames = append(frames, result.Features...)
+			if !offsetSet {
+				offset, offsetSet = state.position, true
+			}
+			duration += result.Duration
+		}
+		state.position += result.Duration
+	}
+	if !offsetSet {
+		offset = state.position
 	}
 
-	return &model.AudioBatch{
+	if held > 0 {
+		log.Printf("クライアント %s のWAVヘッダーの解析のためチャンクを保留: %d チャンク", batch.ClientID, held)
+	}
+	if dropped := len(batch.AudioData) - len(processedData) - held; dropped > 0 {
+		log.Printf("クライアント %s の無音チャンクを破棄: %d / %d チャンク", batch.ClientID, dropped, len(batch.AudioData))
+	}
+
+	processed := &model.AudioBatch{
 		ClientID:  batch.ClientID,
 		AudioData: processedData,
 		Timestamp: batch.Timestamp,
-		BatchSize: batch.BatchSize,
-	}, nil
+		BatchSize: len(processedData),
+		Events:    events,
+		Format:    state.format,
+		Sequence:  batch.Sequence,
+		// 無音チャンクを破棄した場合は最初に残したチャンクの位置と、残したチャンクの合計時間
+		OffsetMs:   offset.Milliseconds(),
+		DurationMs: duration.Milliseconds(),
+	}
+	if state.pipeline != nil {
+		processed.Format = state.pipeline.OutputFormat()
+	}
+
+	// 特徴量を抽出する場合は（設定により）音声データの代わりに特徴量を送る
+	if state.pipeline != nil && state.pipeline.ExtractsFeatures() && len(processedData) > 0 {
+		processed.Features = state.pipeline.FeatureTensor(frames)
+		if !state.pipeline.IncludesAudio() {
+			processed.AudioData = nil
+		}
+	}
+
+	return processed, nil
+}
+
+// SetPreprocessingParameters 全セッション共通の前処理設定を検証して適用
+// 新しく前処理を開始するセッションから適用される（処理中のセッションはフィルター状態を維持）
+func (ap *Preprocessor) SetPreprocessingParameters(config model.PreprocessingConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	ap.config = config
+	log.Printf("前処理設定を更新しました: %+v", config)
+	return nil
 }
 
-// SetPreprocessingParameters 前処理パラメータを設定
-func (ap *Preprocessor) SetPreprocessingParameters(params map[string]interface{}) {
-	ap.params = params
-	log.Printf("前処理パラメータを更新しました: %+v", params)
+// SetSessionPreprocessingParameters セッション固有の前処理設定を検証して適用
+// 既に前処理を開始している場合はパイプラインを作り直す（フィルター状態はリセットされる）
+func (ap *Preprocessor) SetSessionPreprocessingParameters(clientID string, config model.PreprocessingConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	ap.mu.Lock()
+	state, err := ap.sessionFor(clientID)
+	ap.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.config = &config
+	state.pipeline = nil
+	log.Printf("クライアント %s の前処理設定を更新しました: %+v", clientID, config)
+	return nil
+}
+
+// configOr セッションに適用する前処理設定（セッション固有の設定がない場合は共通設定）
+func (s *sessionState) configOr(shared model.PreprocessingConfig) model.PreprocessingConfig {
+	if s.config != nil {
+		return *s.config
+	}
+	return shared
+}
+
+// sessionFor セッション状態を取得（ap.mu を保持して呼び出す）
+// 登録されていない・切断済みのセッションの状態は作らずにエラーとする
+func (ap *Preprocessor) sessionFor(clientID string) (*sessionState, error) {
+	state, ok := ap.sessions[clientID]
+	if !ok {
+		return nil, fmt.Errorf("クライアント %s のセッションが登録されていません", clientID)
+	}
+	return state, nil
 }
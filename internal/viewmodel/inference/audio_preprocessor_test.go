@@ -0,0 +1,240 @@
+package inference
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"testing"
+
+	"socket_inference/internal/model"
+)
+
+// wavHeader 44バイトのPCM WAVヘッダー（16bit）
+func wavHeader(sampleRate, channels, dataBytes int) []byte {
+	header := make([]byte, 0, 44)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(36+dataBytes))
+	header = append(header, "WAVEfmt "...)
+	header = binary.LittleEndian.AppendUint32(header, 16)
+	header = binary.LittleEndian.AppendUint16(header, 1)
+	header = binary.LittleEndian.AppendUint16(header, uint16(channels))
+	header = binary.LittleEndian.AppendUint32(header, uint32(sampleRate))
+	header = binary.LittleEndian.AppendUint32(header, uint32(sampleRate*channels*2))
+	header = binary.LittleEndian.AppendUint16(header, uint16(channels*2))
+	header = binary.LittleEndian.AppendUint16(header, 16)
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(dataBytes))
+	return header
+}
+
+// pcmChunk 16bitモノラルの正弦波チャンク
+func pcmChunk(sampleRate, samples int) []byte {
+	return s16Chunk(sineWave(440, 0.5, sampleRate, samples))
+}
+
+// s16Chunk サンプル列を16bitリトルエンディアンのPCMに変換
+func s16Chunk(samples []float32) []byte {
+	chunk := make([]byte, 0, len(samples)*2)
+	for _, x := range samples {
+		chunk = binary.LittleEndian.AppendUint16(chunk, uint16(int16(x*32767)))
+	}
+	return chunk
+}
+
+func TestPreprocessBatchHoldsChunksUntilWAVHeaderIsComplete(t *testing.T) {
+	header := wavHeader(16000, 1, 3200)
+	audio := pcmChunk(16000, 1600)
+
+	tests := []struct {
+		name          string
+		batches       [][][]byte
+		wantBatchSize []int
+	}{
+		{
+			name:          "ヘッダーのみのバッチは空",
+			batches:       [][][]byte{{header[:10], header[10:30]}, {append(header[30:], audio...)}},
+			wantBatchSize: []int{0, 1},
+		},
+		{
+			name:          "同じバッチでヘッダーが完成",
+			batches:       [][][]byte{{header[:20], append(header[20:], audio...), audio}},
+			wantBatchSize: []int{2},
+		},
+		{
+			name:          "ヘッダーなし",
+			batches:       [][][]byte{{audio, audio}},
+			wantBatchSize: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preprocessor := NewPreprocessor()
+			if err := preprocessor.RegisterSession("session", model.DefaultAudioFormat()); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, chunks := range tt.batches {
+				processed, err := preprocessor.PreprocessBatch(context.Background(), &model.AudioBatch{
+					ClientID:  "session",
+					AudioData: chunks,
+					BatchSize: len(chunks),
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if processed.BatchSize != tt.wantBatchSize[i] || len(processed.AudioData) != tt.wantBatchSize[i] {
+					t.Fatalf("バッチ %d: BatchSize = %d, チャンク数 = %d（期待値 %d）",
+						i, processed.BatchSize, len(processed.AudioData), tt.wantBatchSize[i])
+				}
+				for j, data := range processed.AudioData {
+					if len(data) == 0 {
+						t.Fatalf("バッチ %d のチャンク %d が空です", i, j)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPreprocessBatchRunsSessionsConcurrently(t *testing.T) {
+	const sessions = 8
+	preprocessor := NewPreprocessor()
+	audio := pcmChunk(16000, 1600)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, sessions)
+	for i := 0; i < sessions; i++ {
+		sessionID := fmt.Sprintf("session-%d", i)
+		if err := preprocessor.RegisterSession(sessionID, model.DefaultAudioFormat()); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var position int64
+			for sequence := uint64(0); sequence < 20; sequence++ {
+				processed, err := preprocessor.PreprocessBatch(context.Background(), &model.AudioBatch{
+					ClientID:  sessionID,
+					AudioData: [][]byte{audio},
+					BatchSize: 1,
+					Sequence:  sequence,
+				})
+				if err != nil {
+					errs <- err
+					return
+				}
+				// セッション毎の位置が他のセッションの処理に影響されず連続していること
+				if processed.OffsetMs != position {
+					errs <- fmt.Errorf("%s のバッチ %d: オフセット = %dms（期待値 %dms）", sessionID, sequence, processed.OffsetMs, position)
+					return
+				}
+				position += processed.DurationMs
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestPreprocessBatchRejectsUnregisteredSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		register bool
+	}{
+		{name: "登録されていないセッション", register: false},
+		{name: "切断済みのセッション", register: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preprocessor := NewPreprocessor().(*Preprocessor)
+			if tt.register {
+				if err := preprocessor.RegisterSession("session", model.DefaultAudioFormat()); err != nil {
+					t.Fatal(err)
+				}
+				preprocessor.UnregisterSession("session")
+			}
+
+			_, err := preprocessor.PreprocessBatch(context.Background(), &model.AudioBatch{
+				ClientID:  "session",
+				AudioData: [][]byte{pcmChunk(16000, 1600)},
+				BatchSize: 1,
+			})
+			if err == nil {
+				t.Fatal("切断後のバッチが前処理されました")
+			}
+
+			// 切断後のバッチでセッションの状態が作られないこと
+			preprocessor.mu.Lock()
+			defer preprocessor.mu.Unlock()
+			if len(preprocessor.sessions) != 0 {
+				t.Fatalf("セッションの状態が残っています: %d 件", len(preprocessor.sessions))
+			}
+		})
+	}
+}
+
+func TestPreprocessBatchDropsSilentChunks(t *testing.T) {
+	preprocessor := NewPreprocessor()
+	if err := preprocessor.RegisterSession("session", model.DefaultAudioFormat()); err != nil {
+		t.Fatal(err)
+	}
+	config := model.DefaultPreprocessingConfig()
+	config.VAD = vadConfig(model.VADModeEnergy)
+	config.DCRemoval.Enabled = false // 正弦波の終わりの過渡応答で発話終了の位置がずれないようにする
+	if err := preprocessor.SetSessionPreprocessingParameters("session", config); err != nil {
+		t.Fatal(err)
+	}
+
+	// 100ms毎のチャンクを5チャンクずつのバッチにする: 無音 500ms → 正弦波 500ms → 無音 1000ms
+	samples := vadSignal(vadSegment{ms: 500}, vadSegment{tone: true, ms: 500}, vadSegment{ms: 1000})
+	tests := []struct {
+		wantBatchSize int
+		wantEvents    []model.SessionEvent
+		wantOffsetMs  int64
+	}{
+		{wantBatchSize: 0, wantOffsetMs: 500},
+		{wantBatchSize: 5, wantOffsetMs: 500, wantEvents: []model.SessionEvent{{Type: model.SessionEventSpeechStart, OffsetMs: 500}}},
+		// 発話終了は hangover 300ms の無音で確定し、確定までのチャンクは残す
+		{wantBatchSize: 3, wantOffsetMs: 1000, wantEvents: []model.SessionEvent{{Type: model.SessionEventSpeechEnd, OffsetMs: 1000}}},
+		{wantBatchSize: 0, wantOffsetMs: 2000},
+	}
+
+	for i, tt := range tests {
+		var chunks [][]byte
+		for j := 0; j < 5; j++ {
+			offset := (i*5 + j) * 1600
+			chunks = append(chunks, s16Chunk(samples[offset:offset+1600]))
+		}
+		processed, err := preprocessor.PreprocessBatch(context.Background(), &model.AudioBatch{
+			ClientID:  "session",
+			AudioData: chunks,
+			BatchSize: len(chunks),
+			Sequence:  uint64(i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if processed.BatchSize != tt.wantBatchSize || len(processed.AudioData) != tt.wantBatchSize {
+			t.Fatalf("バッチ %d: BatchSize = %d, チャンク数 = %d（期待値 %d）",
+				i, processed.BatchSize, len(processed.AudioData), tt.wantBatchSize)
+		}
+		if processed.OffsetMs != tt.wantOffsetMs {
+			t.Fatalf("バッチ %d: オフセット = %dms（期待値 %dms）", i, processed.OffsetMs, tt.wantOffsetMs)
+		}
+		if len(processed.Events) != len(tt.wantEvents) {
+			t.Fatalf("バッチ %d: イベント = %+v（期待値 %+v）", i, processed.Events, tt.wantEvents)
+		}
+		for j, event := range processed.Events {
+			if event.Type != tt.wantEvents[j].Type || event.OffsetMs != tt.wantEvents[j].OffsetMs || event.ClientID != "session" {
+				t.Fatalf("バッチ %d: イベント = %+v（期待値 %+v）", i, event, tt.wantEvents[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package inference
+
+import (
+	"sync"
+
+	"socket_inference/internal/model"
+)
+
+// batchQueue セッション毎に順序を保つ推論待ちバッチの待ち行列
+// 同じセッションのバッチは前のバッチの処理が終わるまで取り出さず、異なるセッションは並行に取り出せる
+type batchQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	pending  map[string][]*model.AudioBatch // clientID -> 推論待ちのバッチ（到着順）
+	busy     map[string]bool                // clientID -> バッチを処理中か
+	ready    []string                       // 取り出し可能なセッション（到着順）
+	depth    int                            // 推論待ちのバッチ数
+	capacity int                            // 推論待ちのバッチ数の上限
+	closed   bool
+}
+
+// newBatchQueue 新しいbatchQueueを作成
+func newBatchQueue(capacity int) *batchQueue {
+	q := &batchQueue{
+		pending:  make(map[string][]*model.AudioBatch),
+		busy:     make(map[string]bool),
+		capacity: capacity,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push バッチを追加（上限に達している場合は空くまで待機）
+// 待ち行列が閉じられた場合はfalseを返す
+func (q *batchQueue) push(batch *model.AudioBatch) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && q.depth >= q.capacity {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return false
+	}
+
+	clientID := batch.ClientID
+	q.pending[clientID] = append(q.pending[clientID], batch)
+	q.depth++
+	if !q.busy[clientID] && len(q.pending[clientID]) == 1 {
+		q.ready = append(q.ready, clientID)
+	}
+	q.cond.Broadcast()
+	return true
+}
+
+// pop 取り出し可能なセッションの先頭のバッチを取り出す（なければ待機）
+// 取り出したセッションはdoneを呼ぶまで次のバッチを取り出さない。待ち行列が閉じられた場合はfalseを返す
+func (q *batchQueue) pop() (*model.AudioBatch, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && len(q.ready) == 0 {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return nil, false
+	}
+
+	clientID := q.ready[0]
+	q.ready = q.ready[1:]
+	batch := q.pending[clientID][0]
+	q.pending[clientID] = q.pending[clientID][1:]
+	q.depth--
+	q.busy[clientID] = true
+	q.cond.Broadcast()
+	return batch, true
+}
+
+// done セッションのバッチの処理完了を記録し、次のバッチを取り出し可能にする
+func (q *batchQueue) done(clientID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.busy, clientID)
+	if len(q.pending[clientID]) > 0 {
+		q.ready = append(q.ready, clientID)
+		q.cond.Broadcast()
+	} else {
+		delete(q.pending, clientID)
+	}
+}
+
+// close 待ち行列を閉じ、待機中のpush/popを終了させる（未処理のバッチは破棄）
+func (q *batchQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// stats 推論待ちのバッチ数と、推論待ちまたは処理中のセッション数
+func (q *batchQueue) stats() (depth, sessions int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	sessions = len(q.busy)
+	for clientID := range q.pending {
+		if !q.busy[clientID] {
+			sessions++
+		}
+	}
+	return q.depth, sessions
+}
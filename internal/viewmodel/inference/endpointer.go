@@ -0,0 +1,108 @@
+package inference
+
+import (
+	"log"
+	"sync"
+
+	"socket_inference/internal/model"
+	"socket_inference/internal/viewmodel/interfaces"
+)
+
+// VADEndpointer 受信チャンクをVADで判定し、発話の終端を検出
+// 前処理パイプラインより前（バッチ化の時点）で判定するため、入力のサンプリングレートのまま判定する
+// mu はセッションの表のみを保護し、復号・VADはセッション毎のロックで直列化する
+type VADEndpointer struct {
+	mu       sync.Mutex
+	config   model.VADConfig
+	sessions map[string]*endpointSession
+}
+
+// endpointSession セッション毎の判定状態（mu で保護する）
+type endpointSession struct {
+	mu      sync.Mutex
+	state   *sessionState // WAVヘッダーの検出とフォーマットの保持
+	decode  *DecodeStage
+	downmix *DownmixStage
+	vad     *VAD
+}
+
+// NewVADEndpointer 新しいVADEndpointerを作成
+func NewVADEndpointer(config model.VADConfig) (interfaces.SpeechEndpointer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &VADEndpointer{
+		config:   config,
+		sessions: make(map[string]*endpointSession),
+	}, nil
+}
+
+// RegisterSession セッションが宣言した音声フォーマットを登録
+func (e *VADEndpointer) RegisterSession(clientID string, format model.AudioFormat) error {
+	state, err := newSessionState(format)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sessions[clientID] = &endpointSession{state: state}
+	return nil
+}
+
+// UnregisterSession セッションの判定状態を破棄
+func (e *VADEndpointer) UnregisterSession(clientID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.sessions, clientID)
+}
+
+// ObserveChunk チャンクを判定し、発話の終端を含む場合はtrueを返す
+// 復号できないチャンクは終端なしとして扱う（エラーは前処理で報告される）
+func (e *VADEndpointer) ObserveChunk(clientID string, chunk []byte) (bool, bool) {
+	e.mu.Lock()
+	session, ok := e.sessions[clientID]
+	e.mu.Unlock()
+	if !ok {
+		return false, false
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	audio, err := session.state.consumeHeader(clientID, chunk)
+	if err != nil || !session.state.headerChecked {
+		return false, true
+	}
+
+	if session.vad == nil {
+		format := session.state.format
+		session.decode, err = NewDecodeStage(format)
+		if err == nil {
+			session.vad, err = NewVAD(format.SampleRate, e.config)
+		}
+		if err != nil {
+			log.Printf("クライアント %s の発話終端検出を開始できません: %v", clientID, err)
+			session.state.err = err
+			return false, true
+		}
+		session.downmix = NewDownmixStage()
+	}
+
+	buf := &AudioBuffer{Raw: audio, SampleRate: session.state.format.SampleRate, Channels: session.state.format.Channels}
+	if err := session.decode.Process(buf); err != nil {
+		return false, true
+	}
+	if err := session.downmix.Process(buf); err != nil {
+		return false, true
+	}
+
+	for _, transition := range session.vad.Process(buf.Samples).Transitions {
+		if transition.Type == model.SessionEventSpeechEnd {
+			return true, true
+		}
+	}
+	return false, true
+}
@@ -0,0 +1,65 @@
+package inference
+
+import (
+	"testing"
+
+	"socket_inference/internal/model"
+)
+
+func TestVADEndpointerDetectsEndOfSpeech(t *testing.T) {
+	tests := []struct {
+		name   string
+		header bool
+	}{
+		{name: "ヘッダーなし"},
+		{name: "WAVヘッダー付き", header: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpointer, err := NewVADEndpointer(vadConfig(model.VADModeEnergy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := endpointer.RegisterSession("session", model.DefaultAudioFormat()); err != nil {
+				t.Fatal(err)
+			}
+
+			// 100ms毎のチャンク: 無音 500ms → 正弦波 1000ms → 無音 1000ms
+			samples := vadSignal(vadSegment{ms: 500}, vadSegment{tone: true, ms: 1000}, vadSegment{ms: 1000})
+			var chunks [][]byte
+			for offset := 0; offset < len(samples); offset += 1600 {
+				chunks = append(chunks, s16Chunk(samples[offset:offset+1600]))
+			}
+			if tt.header {
+				chunks[0] = append(wavHeader(16000, 1, len(samples)*2), chunks[0]...)
+			}
+
+			// 発話終了は最後の音声フレーム（1500ms）から hangover 300ms の無音で確定する
+			for i, chunk := range chunks {
+				end, known := endpointer.ObserveChunk("session", chunk)
+				if !known {
+					t.Fatalf("チャンク %d: 登録したセッションが見つかりません", i)
+				}
+				if want := i == 17; end != want {
+					t.Fatalf("チャンク %d: 発話の終端 = %v（期待値 %v）", i, end, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVADEndpointerIgnoresUnregisteredSession(t *testing.T) {
+	endpointer, err := NewVADEndpointer(vadConfig(model.VADModeEnergy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := endpointer.RegisterSession("session", model.DefaultAudioFormat()); err != nil {
+		t.Fatal(err)
+	}
+	endpointer.UnregisterSession("session")
+
+	if end, known := endpointer.ObserveChunk("session", pcmChunk(16000, 1600)); end || known {
+		t.Fatalf("切断したセッションのチャンクを判定しました: 終端 = %v, 登録済み = %v", end, known)
+	}
+}
@@ -0,0 +1,218 @@
+package inference
+
+import (
+	"math"
+	"math/cmplx"
+
+	"socket_inference/internal/model"
+)
+
+// 特徴量抽出の定数
+const (
+	logMelFloor       = 1e-10 // 対数を取る前のエネルギーの下限
+	cmvnWindowFrames  = 3000  // セッションCMVNの統計量が追従するフレーム数（約30秒）
+	cmvnVarianceFloor = 1e-6  // 分散の下限
+)
+
+// LogMelExtractor 短時間フーリエ変換とメルフィルターバンクによる対数メルスペクトログラム抽出
+// フレームに満たない端数とCMVNの統計量をチャンク間で保持する
+type LogMelExtractor struct {
+	config      model.FeatureConfig
+	sampleRate  int
+	frameLength int
+	hop         int
+	fftSize     int
+	window      []float64
+	filterbank  []melFilter
+	pending     []float32
+	cmvnCount   int
+	cmvnMean    []float64
+	cmvnVar     []float64
+}
+
+// melFilter 1つの三角フィルター（開始ビンと重み）
+type melFilter struct {
+	start   int
+	weights []float64
+}
+
+// NewLogMelExtractor 新しいLogMelExtractorを作成
+func NewLogMelExtractor(sampleRate int, config model.FeatureConfig) *LogMelExtractor {
+	frameLength := sampleRate * config.FrameLengthMs / 1000
+	fftSize := 1
+	for fftSize < frameLength {
+		fftSize <<= 1
+	}
+
+	highFreq := config.HighFreq
+	if highFreq == 0 {
+		highFreq = float64(sampleRate) / 2
+	}
+
+	return &LogMelExtractor{
+		config:      config,
+		sampleRate:  sampleRate,
+		frameLength: frameLength,
+		hop:         sampleRate * config.HopMs / 1000,
+		fftSize:     fftSize,
+		window:      hannWindow(frameLength),
+		filterbank:  melFilterbank(config.MelBins, fftSize, sampleRate, config.LowFreq, highFreq),
+		cmvnMean:    make([]float64, config.MelBins),
+		cmvnVar:     make([]float64, config.MelBins),
+	}
+}
+
+// MelBins メルフィルターバンクの数
+func (e *LogMelExtractor) MelBins() int {
+	return e.config.MelBins
+}
+
+// Process サンプル列から抽出できた全フレームの特徴量を返す
+func (e *LogMelExtractor) Process(samples []float32) [][]float32 {
+	data := samples
+	if len(e.pending) > 0 {
+		data = append(e.pending, samples...)
+		e.pending = nil
+	}
+
+	var frames [][]float32
+	offset := 0
+	for ; offset+e.frameLength <= len(data); offset += e.hop {
+		frames = append(frames, e.extractFrame(data[offset:offset+e.frameLength]))
+	}
+	if offset < len(data) {
+		e.pending = append([]float32(nil), data[offset:]...)
+	}
+	return frames
+}
+
+// extractFrame 1フレームの対数メルエネルギーを計算
+func (e *LogMelExtractor) extractFrame(frame []float32) []float32 {
+	spectrum := make([]complex128, e.fftSize)
+	for i, x := range frame {
+		spectrum[i] = complex(float64(x)*e.window[i], 0)
+	}
+	fft(spectrum)
+
+	power := make([]float64, e.fftSize/2+1)
+	for k := range power {
+		magnitude := cmplx.Abs(spectrum[k])
+		power[k] = magnitude * magnitude
+	}
+
+	features := make([]float32, len(e.filterbank))
+	for m, filter := range e.filterbank {
+		var energy float64
+		for i, w := range filter.weights {
+			energy += w * power[filter.start+i]
+		}
+		value := math.Log(math.Max(energy, logMelFloor))
+		if e.config.CMVN == model.CMVNSession {
+			value = e.normalize(m, value)
+		}
+		features[m] = float32(value)
+	}
+	if e.config.CMVN == model.CMVNSession {
+		e.cmvnCount++
+	}
+	return features
+}
+
+// normalize セッションの累積統計量を更新して平均・分散正規化
+// 統計量は直近 cmvnWindowFrames フレーム程度に追従する
+func (e *LogMelExtractor) normalize(bin int, value float64) float64 {
+	alpha := 1 / float64(min(e.cmvnCount+1, cmvnWindowFrames))
+	d := value - e.cmvnMean[bin]
+	e.cmvnMean[bin] += alpha * d
+	e.cmvnVar[bin] += alpha * (d*(value-e.cmvnMean[bin]) - e.cmvnVar[bin])
+	if e.cmvnCount == 0 {
+		return 0
+	}
+	return (value - e.cmvnMean[bin]) / math.Sqrt(math.Max(e.cmvnVar[bin], cmvnVarianceFloor))
+}
+
+// hannWindow ハン窓
+func hannWindow(length int) []float64 {
+	window := make([]float64, length)
+	if length == 1 {
+		window[0] = 1
+		return window
+	}
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(length-1))
+	}
+	return window
+}
+
+// melScale 周波数（Hz）をメル尺度に変換
+func melScale(freq float64) float64 {
+	return 1127 * math.Log(1+freq/700)
+}
+
+// melFilterbank メル尺度で等間隔に並べた三角フィルターを作成
+// 重みはメル尺度上で線形に補間する
+func melFilterbank(bins, fftSize, sampleRate int, lowFreq, highFreq float64) []melFilter {
+	lowMel, highMel := melScale(lowFreq), melScale(highFreq)
+	delta := (highMel - lowMel) / float64(bins+1)
+	binHz := float64(sampleRate) / float64(fftSize)
+
+	filters := make([]melFilter, bins)
+	for m := range filters {
+		left := lowMel + float64(m)*delta
+		center := left + delta
+		right := center + delta
+
+		filter := melFilter{start: -1}
+		for k := 0; k <= fftSize/2; k++ {
+			mel := melScale(float64(k) * binHz)
+			if mel <= left || mel >= right {
+				if filter.start >= 0 {
+					break
+				}
+				continue
+			}
+			if filter.start < 0 {
+				filter.start = k
+			}
+			if mel <= center {
+				filter.weights = append(filter.weights, (mel-left)/(center-left))
+			} else {
+				filter.weights = append(filter.weights, (right-mel)/(right-center))
+			}
+		}
+		if filter.start < 0 {
+			filter.start = 0
+		}
+		filters[m] = filter
+	}
+	return filters
+}
+
+// fft 基数2の高速フーリエ変換（長さは2のべき乗、インプレース）
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even := x[start+k]
+				odd := w * x[start+k+size/2]
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
@@ -2,7 +2,11 @@ package inference
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"socket_inference/internal/infrastructure/interfaces"
@@ -10,73 +14,441 @@ import (
 	vmInterfaces "socket_inference/internal/viewmodel/interfaces"
 )
 
+// ManagerOptions 推論処理のワーカープールの設定
+type ManagerOptions struct {
+	Workers        int           // 並行してバッチを処理するワーカー数
+	MaxInFlight    int           // 推論サーバーへの同時リクエスト数の上限
+	QueueSize      int           // 推論待ちのバッチ数の上限（超えるとバッチの受け取りを待機）
+	RequestTimeout time.Duration // 1バッチの推論のタイムアウト（セッションが宣言しない場合）
+}
+
+// DefaultManagerOptions ワーカープールのデフォルト設定
+func DefaultManagerOptions() ManagerOptions {
+	return ManagerOptions{
+		Workers:        4,
+		MaxInFlight:    8,
+		QueueSize:      256,
+		RequestTimeout: 30 * time.Second,
+	}
+}
+
 // Manager 推論処理管理の実装
+// セッション毎のバッチの順序を保ちつつ、異なるセッションのバッチをワーカープールで並行に処理する
 type Manager struct {
 	preprocessor    vmInterfaces.AudioPreprocessor
 	inferenceClient interfaces.InferenceClient // Infrastructure依存を注入
 	resultChannel   chan *model.InferenceResponse
+	eventChannel    chan *model.SessionEvent
+	sessionsMu      sync.RWMutex
+	sessions        map[string]*session // セッションID -> セッションのコンテキストと宣言
+	options         ManagerOptions
+	queue           *batchQueue
+	inFlight        chan struct{} // 推論サーバーへの送信中リクエストのセマフォ
+	processed       atomic.Uint64
+	failed          atomic.Uint64
+	workers         sync.WaitGroup
+	shutdownOnce    sync.Once
 	ctx             context.Context
 	cancel          context.CancelFunc
 }
 
-// NewManager 新しい推論マネージャーを作成
+// session 登録中のセッションのコンテキストと宣言
+type session struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	task    vmInterfaces.TaskHandler
+	timeout time.Duration // 0の場合は ManagerOptions.RequestTimeout
+
+	model  model.ModelInfo            // 推論モデル（ゼロ値の場合はリクエストにモデルを指定しない）
+	client interfaces.InferenceClient // 推論モデルの送信先（nilの場合はマネージャーの推論クライアント）
+}
+
+// NewManager デフォルトのワーカープール設定で新しい推論マネージャーを作成
 func NewManager(inferenceClient interfaces.InferenceClient) vmInterfaces.InferenceManager {
+	return NewManagerWithOptions(inferenceClient, DefaultManagerOptions())
+}
+
+// NewManagerWithOptions ワーカープールを設定して新しい推論マネージャーを作成
+// 0以下の設定値はデフォルト値を使用する
+func NewManagerWithOptions(inferenceClient interfaces.InferenceClient, options ManagerOptions) vmInterfaces.InferenceManager {
+	defaults := DefaultManagerOptions()
+	if options.Workers <= 0 {
+		options.Workers = defaults.Workers
+	}
+	if options.MaxInFlight <= 0 {
+		options.MaxInFlight = defaults.MaxInFlight
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaults.QueueSize
+	}
+	if options.RequestTimeout <= 0 {
+		options.RequestTimeout = defaults.RequestTimeout
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Manager{
 		preprocessor:    NewPreprocessor(),
 		inferenceClient: inferenceClient,
 		resultChannel:   make(chan *model.InferenceResponse, 100),
+		eventChannel:    make(chan *model.SessionEvent, 100),
+		sessions:        make(map[string]*session),
+		options:         options,
+		queue:           newBatchQueue(options.QueueSize),
+		inFlight:        make(chan struct{}, options.MaxInFlight),
 		ctx:             ctx,
 		cancel:          cancel,
 	}
 }
 
 // ProcessBatch バッチを推論処理
-func (im *Manager) ProcessBatch(batch *model.AudioBatch) (*model.InferenceResponse, error) {
+// 無音のみのバッチは推論せず、silence_skipped イベントを通知してnilを返す
+// 推論はセッションが宣言したタイムアウト（未宣言の場合は RequestTimeout）で打ち切る
+// ctx はセッションのコンテキストで、バッチの完了を表すイベントはその取り消しまで破棄せずに通知する
+// 登録されていない（切断済みの）セッションのバッチは推論せずに破棄する
+func (im *Manager) ProcessBatch(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	settings, ok := im.sessionSettings(batch.ClientID)
+	if !ok {
+		return nil, fmt.Errorf("クライアント %s のセッションが登録されていないためバッチを破棄", batch.ClientID)
+	}
+
 	// 前処理を実行
-	processedBatch, err := im.preprocessor.PreprocessBatch(batch)
+	processedBatch, err := im.preprocessor.PreprocessBatch(ctx, batch)
 	if err != nil {
 		return nil, err
 	}
 
+	for i := range processedBatch.Events {
+		im.publishEvent(&processedBatch.Events[i])
+	}
+	if processedBatch.BatchSize == 0 {
+		im.notifyEvent(ctx, &model.SessionEvent{
+			Type:      model.SessionEventSilenceSkipped,
+			ClientID:  batch.ClientID,
+			Timestamp: time.Now(),
+		})
+		return nil, nil
+	}
+
 	// Infrastructure層のクライアントを使用して推論実行
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	task := settings.task
+	requestCtx, cancel := context.WithTimeout(ctx, settings.timeout)
 	defer cancel()
 
-	response, err := im.inferenceClient.SendBatchInferenceRequest(ctx, processedBatch)
+	// セッションのタスクに応じてリクエストを作成し、セッションの推論モデルに送信
+	request := task.BuildRequest(processedBatch)
+	request.Model = settings.model.Name
+	request.ModelVersion = settings.model.Version
+	response, err := im.sendRequest(requestCtx, settings.client, request)
 	if err != nil {
 		log.Printf("推論リクエスト失敗: %v", err)
+		// 推論サーバーを利用できない場合はクライアントに通知（バッチは破棄）
+		var unavailable *model.InferenceUnavailableError
+		if errors.As(err, &unavailable) {
+			im.notifyEvent(ctx, &model.SessionEvent{
+				Type:         model.SessionEventInferenceUnavailable,
+				ClientID:     batch.ClientID,
+				OffsetMs:     processedBatch.OffsetMs,
+				Timestamp:    time.Now(),
+				Message:      "推論サーバーを一時的に利用できません",
+				RetryAfterMs: unavailable.RetryAfter.Milliseconds(),
+			})
+		}
 		return nil, err
 	}
 
+	// 推論サーバーはバッチ先頭からの時刻を返すため、セッション先頭からの時刻に変換
+	response.Task = task.Task()
+	response.Sequence = processedBatch.Sequence
+	response.ShiftTimes(processedBatch.OffsetMs)
+
+	events, err := task.HandleResponse(response)
+	if err != nil {
+		return nil, fmt.Errorf("クライアント %s の %s の推論結果が不正です: %w", batch.ClientID, task.Task(), err)
+	}
+	for i := range events {
+		im.publishEvent(&events[i])
+	}
+
 	return response, nil
 }
 
+// sendRequest 同時リクエスト数の上限内で推論サーバーにリクエストを送信
+func (im *Manager) sendRequest(ctx context.Context, client interfaces.InferenceClient, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	select {
+	case im.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-im.inFlight }()
+
+	// 送信枠を待つ間にセッションが終了した場合は送信しない
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return client.SendInferenceRequest(ctx, request)
+}
+
 // StartProcessing バックグラウンド推論処理を開始
+// 受け取ったバッチをセッション毎の待ち行列に入れ、ワーカーが並行に処理する
 func (im *Manager) StartProcessing(ctx context.Context, batchChan <-chan *model.AudioBatch) {
+	// 停止時は待ち行列を閉じて、受け取り待ち・取り出し待ちを終了させる
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-im.ctx.Done():
+		}
+		im.queue.close()
+	}()
+
 	go func() {
 		for {
 			select {
 			case batch := <-batchChan:
-				response, err := im.ProcessBatch(batch)
-				if err != nil {
-					log.Printf("推論処理エラー: %v", err)
-					continue
-				}
-
-				select {
-				case im.resultChannel <- response:
-					log.Printf("推論結果送信完了: クライアント=%s", response.ClientID)
-				case <-ctx.Done():
+				if !im.queue.push(batch) {
 					return
 				}
-
 			case <-ctx.Done():
 				return
+			case <-im.ctx.Done():
+				return
 			}
 		}
 	}()
-	log.Println("推論処理マネージャーを開始しました")
+
+	for i := 0; i < im.options.Workers; i++ {
+		im.workers.Add(1)
+		go im.runWorker(ctx)
+	}
+	log.Printf("推論処理マネージャーを開始しました: ワーカー数=%d, 同時リクエスト上限=%d, 待ち行列上限=%d",
+		im.options.Workers, im.options.MaxInFlight, im.options.QueueSize)
+}
+
+// runWorker 待ち行列からバッチを取り出して推論し、結果を送信
+// セッションの次のバッチは結果を送信してから取り出し可能にする（セッション内の結果の順序を保証）
+func (im *Manager) runWorker(ctx context.Context) {
+	defer im.workers.Done()
+
+	for {
+		batch, ok := im.queue.pop()
+		if !ok {
+			return
+		}
+
+		sessionCtx := im.sessionContext(batch.ClientID)
+		response, err := im.ProcessBatch(sessionCtx, batch)
+		im.processed.Add(1)
+		if err != nil && sessionCtx.Err() != nil {
+			log.Printf("クライアント %s のセッション終了により推論を中止: %v", batch.ClientID, err)
+		} else if err != nil {
+			im.failed.Add(1)
+			log.Printf("推論処理エラー: %v", err)
+			im.notifyFailure(sessionCtx, batch, err)
+		} else if response != nil {
+			select {
+			case im.resultChannel <- response:
+				log.Printf("推論結果送信完了: クライアント=%s", response.ClientID)
+			case <-ctx.Done():
+			case <-im.ctx.Done():
+			}
+		}
+		im.queue.done(batch.ClientID)
+	}
+}
+
+// Stats 推論処理の稼働状況を取得
+func (im *Manager) Stats() model.InferenceStats {
+	depth, sessions := im.queue.stats()
+	return model.InferenceStats{
+		Workers:        im.options.Workers,
+		MaxInFlight:    im.options.MaxInFlight,
+		InFlight:       len(im.inFlight),
+		QueueDepth:     depth,
+		QueueCapacity:  im.options.QueueSize,
+		ActiveSessions: sessions,
+		Processed:      im.processed.Load(),
+		Failed:         im.failed.Load(),
+	}
+}
+
+// RegisterSession セッションが宣言した音声フォーマットを登録
+// セッションのコンテキストは ctx の取り消し、登録の解除、マネージャーの停止のいずれかで取り消される
+func (im *Manager) RegisterSession(ctx context.Context, clientID string, format model.AudioFormat) error {
+	if err := im.preprocessor.RegisterSession(clientID, format); err != nil {
+		return err
+	}
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(im.ctx, cancel)
+
+	im.sessionsMu.Lock()
+	if previous, ok := im.sessions[clientID]; ok {
+		previous.cancel()
+	}
+	im.sessions[clientID] = &session{
+		ctx: sessionCtx,
+		cancel: func() {
+			stop()
+			cancel()
+		},
+		task: &transcriptionTask{},
+	}
+	im.sessionsMu.Unlock()
+	return nil
+}
+
+// UnregisterSession セッションの切断を通知し、送信中の推論を中止
+func (im *Manager) UnregisterSession(clientID string) {
+	im.preprocessor.UnregisterSession(clientID)
+
+	im.sessionsMu.Lock()
+	if s, ok := im.sessions[clientID]; ok {
+		s.cancel()
+		delete(im.sessions, clientID)
+	}
+	im.sessionsMu.Unlock()
+}
+
+// SetSessionTask セッションが宣言した推論タスクを検証して適用
+func (im *Manager) SetSessionTask(clientID string, config model.TaskConfig) error {
+	handler, err := NewTaskHandler(config)
+	if err != nil {
+		return err
+	}
+
+	im.sessionsMu.Lock()
+	defer im.sessionsMu.Unlock()
+	s, ok := im.sessions[clientID]
+	if !ok {
+		return fmt.Errorf("クライアント %s のセッションが登録されていません", clientID)
+	}
+	s.task = handler
+
+	log.Printf("クライアント %s の推論タスク: %s", clientID, handler.Task())
+	return nil
+}
+
+// SetSessionTimeout セッションが宣言した1バッチの推論のタイムアウトを適用（0の場合はデフォルト）
+func (im *Manager) SetSessionTimeout(clientID string, timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("クライアント %s の推論のタイムアウトが不正です: %s", clientID, timeout)
+	}
+
+	im.sessionsMu.Lock()
+	defer im.sessionsMu.Unlock()
+	s, ok := im.sessions[clientID]
+	if !ok {
+		return fmt.Errorf("クライアント %s のセッションが登録されていません", clientID)
+	}
+	s.timeout = timeout
+	return nil
+}
+
+// SetSessionModel セッションが宣言した推論モデルと送信先の推論クライアントを適用
+func (im *Manager) SetSessionModel(clientID string, info model.ModelInfo, client interfaces.InferenceClient) error {
+	if client == nil {
+		return fmt.Errorf("クライアント %s の推論モデル %s の推論クライアントがありません", clientID, info.ID())
+	}
+
+	im.sessionsMu.Lock()
+	defer im.sessionsMu.Unlock()
+	s, ok := im.sessions[clientID]
+	if !ok {
+		return fmt.Errorf("クライアント %s のセッションが登録されていません", clientID)
+	}
+	s.model = info
+	s.client = client
+
+	log.Printf("クライアント %s の推論モデル: %s", clientID, info.ID())
+	return nil
+}
+
+// canceledContext 登録されていないセッションのコンテキスト（取り消し済み）
+var canceledContext = func() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}()
+
+// sessionContext セッションのコンテキストを取得
+// 未登録（切断済み）の場合は取り消し済みのコンテキストを返し、待ち行列に残ったバッチを推論しない
+func (im *Manager) sessionContext(clientID string) context.Context {
+	im.sessionsMu.RLock()
+	defer im.sessionsMu.RUnlock()
+	if s, ok := im.sessions[clientID]; ok {
+		return s.ctx
+	}
+	return canceledContext
+}
+
+// sessionSettings セッションのタスクハンドラー・推論のタイムアウト・推論モデルを取得
+// タイムアウト・推論モデルが未宣言の場合はデフォルトのタイムアウト、マネージャーの推論クライアントを使用する
+// セッションが登録されていない場合は ok=false を返す
+func (im *Manager) sessionSettings(clientID string) (session, bool) {
+	im.sessionsMu.RLock()
+	defer im.sessionsMu.RUnlock()
+
+	s, ok := im.sessions[clientID]
+	if !ok {
+		return session{}, false
+	}
+	settings := *s
+	if settings.timeout <= 0 {
+		settings.timeout = im.options.RequestTimeout
+	}
+	if settings.client == nil {
+		settings.client = im.inferenceClient
+	}
+	return settings, true
+}
+
+// SetPreprocessingConfig 全セッション共通の前処理設定を検証して適用
+func (im *Manager) SetPreprocessingConfig(config model.PreprocessingConfig) error {
+	return im.preprocessor.SetPreprocessingParameters(config)
+}
+
+// SetSessionPreprocessingConfig セッション固有の前処理設定を検証して適用
+func (im *Manager) SetSessionPreprocessingConfig(clientID string, config model.PreprocessingConfig) error {
+	return im.preprocessor.SetSessionPreprocessingParameters(clientID, config)
+}
+
+// publishEvent セッションイベントを通知（チャネルが満杯の場合は破棄）
+func (im *Manager) publishEvent(event *model.SessionEvent) {
+	select {
+	case im.eventChannel <- event:
+	default:
+		log.Printf("イベントチャネルが満杯、クライアント %s の %s イベントを破棄", event.ClientID, event.Type)
+	}
+}
+
+// notifyEvent バッチの完了を表すセッションイベントを通知
+// ファイル推論はこれらのイベントでバッチの完了を数えるため、チャネルが満杯でも破棄せずに
+// セッションの終了またはマネージャーの停止まで待機する
+func (im *Manager) notifyEvent(ctx context.Context, event *model.SessionEvent) {
+	select {
+	case im.eventChannel <- event:
+	case <-ctx.Done():
+	case <-im.ctx.Done():
+	}
+}
+
+// notifyFailure 推論に失敗したバッチを batch_dropped イベントで通知
+// 推論サーバーを利用できない場合は ProcessBatch が inference_unavailable を通知済みのため除く
+func (im *Manager) notifyFailure(ctx context.Context, batch *model.AudioBatch, err error) {
+	var unavailable *model.InferenceUnavailableError
+	if errors.As(err, &unavailable) {
+		return
+	}
+	im.notifyEvent(ctx, &model.SessionEvent{
+		Type:      model.SessionEventBatchDropped,
+		ClientID:  batch.ClientID,
+		Timestamp: time.Now(),
+		Message:   "推論に失敗したため、バッチの推論結果を返せませんでした",
+	})
+}
+
+// GetEventChannel セッションイベントのチャネルを取得
+func (im *Manager) GetEventChannel() <-chan *model.SessionEvent {
+	return im.eventChannel
 }
 
 // GetResultChannel 推論結果のチャネルを取得
@@ -85,8 +457,14 @@ func (im *Manager) GetResultChannel() <-chan *model.InferenceResponse {
 }
 
 // Shutdown 推論処理を停止
+// 処理中のリクエストを取り消し、ワーカーの終了を待ってからチャネルを閉じる（2回目以降の呼び出しは何もしない）
 func (im *Manager) Shutdown() {
-	im.cancel()
-	close(im.resultChannel)
-	log.Println("推論処理マネージャーを停止しました")
+	im.shutdownOnce.Do(func() {
+		im.cancel()
+		im.queue.close()
+		im.workers.Wait()
+		close(im.resultChannel)
+		close(im.eventChannel)
+		log.Println("推論処理マネージャーを停止しました")
+	})
 }
@@ -0,0 +1,153 @@
+package inference
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+)
+
+// gatedClient 送信したセッションを記録し、gated のセッションは gate が閉じるまで応答しないテスト用の推論クライアント
+type gatedClient struct {
+	mu      sync.Mutex
+	calls   map[string]int
+	gated   string
+	gate    chan struct{}
+	started chan string
+}
+
+func (c *gatedClient) SendInferenceRequest(ctx context.Context, request *model.InferenceRequest) (*model.InferenceResponse, error) {
+	c.mu.Lock()
+	c.calls[request.ClientID]++
+	c.mu.Unlock()
+	c.started <- request.ClientID
+
+	if request.ClientID == c.gated {
+		<-c.gate
+	}
+	return &model.InferenceResponse{ClientID: request.ClientID, Result: "ok"}, nil
+}
+
+func (c *gatedClient) SendBatchInferenceRequest(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error) {
+	return c.SendInferenceRequest(ctx, &model.InferenceRequest{ClientID: batch.ClientID})
+}
+
+func (c *gatedClient) Connect(ctx context.Context) error { return nil }
+func (c *gatedClient) Disconnect() error                 { return nil }
+func (c *gatedClient) IsConnected() bool                 { return true }
+func (c *gatedClient) GetServerStatus() (string, error)  { return "connected", nil }
+
+// waitFor 条件が満たされるまで待機
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s を待機中にタイムアウトしました", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestManagerDropsQueuedBatchOfUnregisteredSession(t *testing.T) {
+	client := &gatedClient{calls: map[string]int{}, gated: "busy", gate: make(chan struct{}), started: make(chan string, 4)}
+	im := NewManagerWithOptions(client, ManagerOptions{Workers: 1, MaxInFlight: 1}).(*Manager)
+	defer im.Shutdown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	batches := make(chan *model.AudioBatch, 4)
+	im.StartProcessing(ctx, batches)
+
+	for _, sessionID := range []string{"busy", "closed"} {
+		if err := im.RegisterSession(ctx, sessionID, model.DefaultAudioFormat()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	batch := func(sessionID string) *model.AudioBatch {
+		return &model.AudioBatch{ClientID: sessionID, AudioData: [][]byte{pcmChunk(16000, 1600)}, BatchSize: 1}
+	}
+
+	// 唯一のワーカーが推論中の間に、切断するセッションのバッチを待ち行列に入れる
+	batches <- batch("busy")
+	select {
+	case <-client.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("推論が開始されません")
+	}
+	batches <- batch("closed")
+	waitFor(t, "待ち行列への追加", func() bool { return im.Stats().QueueDepth == 1 })
+
+	im.UnregisterSession("closed")
+	close(client.gate)
+
+	select {
+	case response := <-im.GetResultChannel():
+		if response.ClientID != "busy" {
+			t.Fatalf("推論結果のセッション = %s（期待値 busy）", response.ClientID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("推論結果を受信できません")
+	}
+	waitFor(t, "待ち行列のバッチの処理", func() bool { return im.Stats().Processed == 2 })
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if calls := client.calls["closed"]; calls != 0 {
+		t.Fatalf("切断したセッションのバッチが推論サーバーに送信されました: %d 回", calls)
+	}
+	if failed := im.Stats().Failed; failed != 0 {
+		t.Fatalf("切断したセッションのバッチが推論の失敗として数えられました: %d 件", failed)
+	}
+}
+
+func TestManagerShutdownIsIdempotent(t *testing.T) {
+	im := NewManagerWithOptions(&gatedClient{calls: map[string]int{}}, ManagerOptions{})
+	im.StartProcessing(context.Background(), make(chan *model.AudioBatch))
+
+	// ViewModel の停止とシグナルによる停止の両方から呼び出されてもパニックしないこと
+	im.Shutdown()
+	im.Shutdown()
+}
+
+func TestManagerSkipsSilentBatch(t *testing.T) {
+	client := &gatedClient{calls: map[string]int{}, started: make(chan string, 4)}
+	im := NewManagerWithOptions(client, ManagerOptions{})
+	defer im.Shutdown()
+
+	ctx := context.Background()
+	if err := im.RegisterSession(ctx, "session", model.DefaultAudioFormat()); err != nil {
+		t.Fatal(err)
+	}
+	config := model.DefaultPreprocessingConfig()
+	config.VAD = vadConfig(model.VADModeEnergy)
+	if err := im.SetSessionPreprocessingConfig("session", config); err != nil {
+		t.Fatal(err)
+	}
+
+	silence := vadSignal(vadSegment{ms: 500})
+	response, err := im.ProcessBatch(ctx, &model.AudioBatch{
+		ClientID:  "session",
+		AudioData: [][]byte{s16Chunk(silence[:4000]), s16Chunk(silence[4000:])},
+		BatchSize: 2,
+	})
+	if err != nil || response != nil {
+		t.Fatalf("推論結果 = %+v, エラー = %v（無音のバッチは結果なしを期待）", response, err)
+	}
+
+	select {
+	case event := <-im.GetEventChannel():
+		if event.Type != model.SessionEventSilenceSkipped || event.ClientID != "session" {
+			t.Fatalf("イベント = %+v（期待値 %s）", event, model.SessionEventSilenceSkipped)
+		}
+	default:
+		t.Fatal("silence_skipped イベントが通知されません")
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if calls := client.calls["session"]; calls != 0 {
+		t.Fatalf("無音のバッチが推論サーバーに送信されました: %d 回", calls)
+	}
+}
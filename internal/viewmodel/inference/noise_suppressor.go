@@ -0,0 +1,171 @@
+package inference
+
+import (
+	"math"
+	"math/cmplx"
+
+	"socket_inference/internal/model"
+)
+
+// 雑音抑圧の定数
+const (
+	noiseSuppressionFrameMs = 32  // 分析フレーム長（ミリ秒、FFTサイズは2のべき乗に切り上げ）
+	noiseUpdateRatio        = 6.0 // ビンのパワーが雑音推定値のこの倍率未満なら雑音とみなして推定値を更新
+	noisePowerFloor         = 1e-12
+)
+
+// NoiseSuppressor スペクトル減算による雑音抑圧
+// 50%オーバーラップの平方根ハン窓で分析・合成し、セッション毎の雑音スペクトル推定値を保持する
+// 出力は入力と同じサンプル数で、フレーム長分だけ遅延する
+type NoiseSuppressor struct {
+	config     model.NoiseSuppressionConfig
+	frameSize  int
+	hop        int
+	window     []float64
+	initFrames int       // セッション先頭で無条件に雑音とみなすフレーム数
+	frames     int       // 処理済みフレーム数
+	noise      []float64 // 周波数ビン毎の雑音パワー推定値
+	analysis   []float64 // 直近 frameSize サンプルの入力
+	pending    []float32 // hopに満たない入力
+	overlap    []float64 // オーバーラップ加算中の出力
+	output     []float32 // 出力待ちのサンプル
+	spectrum   []complex128
+	binGains   []float64
+	binPower   []float64
+}
+
+// NewNoiseSuppressor 新しいNoiseSuppressorを作成
+func NewNoiseSuppressor(sampleRate int, config model.NoiseSuppressionConfig) *NoiseSuppressor {
+	frameSize := 1
+	for frameSize < sampleRate*noiseSuppressionFrameMs/1000 {
+		frameSize <<= 1
+	}
+	hop := frameSize / 2
+
+	// 平方根ハン窓（周期版）は分析・合成の両方に掛けると50%オーバーラップで和が1になる
+	window := make([]float64, frameSize)
+	for i := range window {
+		window[i] = math.Sqrt(0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(frameSize)))
+	}
+
+	return &NoiseSuppressor{
+		config:     config,
+		frameSize:  frameSize,
+		hop:        hop,
+		window:     window,
+		initFrames: sampleRate * config.InitialNoiseMs / 1000 / hop,
+		noise:      make([]float64, frameSize/2+1),
+		analysis:   make([]float64, frameSize),
+		overlap:    make([]float64, frameSize),
+		output:     make([]float32, hop),
+		spectrum:   make([]complex128, frameSize),
+		binGains:   make([]float64, frameSize/2+1),
+		binPower:   make([]float64, frameSize/2+1),
+	}
+}
+
+// LatencySamples 入力に対する出力の遅延（サンプル数）
+func (ns *NoiseSuppressor) LatencySamples() int {
+	return ns.frameSize
+}
+
+// Process サンプル列の雑音を抑圧し、入力と同じ長さのサンプル列を返す
+func (ns *NoiseSuppressor) Process(samples []float32) []float32 {
+	data := samples
+	if len(ns.pending) > 0 {
+		data = append(ns.pending, samples...)
+		ns.pending = nil
+	}
+
+	offset := 0
+	for ; offset+ns.hop <= len(data); offset += ns.hop {
+		copy(ns.analysis, ns.analysis[ns.hop:])
+		for i, x := range data[offset : offset+ns.hop] {
+			ns.analysis[ns.frameSize-ns.hop+i] = float64(x)
+		}
+		ns.processFrame()
+	}
+	if offset < len(data) {
+		ns.pending = append([]float32(nil), data[offset:]...)
+	}
+
+	out := make([]float32, len(samples))
+	copy(out, ns.output)
+	ns.output = append(ns.output[:0], ns.output[len(samples):]...)
+	return out
+}
+
+// processFrame 1フレームを分析・減算・合成し、hop分の出力を確定
+func (ns *NoiseSuppressor) processFrame() {
+	for i, x := range ns.analysis {
+		ns.spectrum[i] = complex(x*ns.window[i], 0)
+	}
+	fft(ns.spectrum)
+
+	for k := range ns.binPower {
+		magnitude := cmplx.Abs(ns.spectrum[k])
+		ns.binPower[k] = magnitude * magnitude
+	}
+	ns.updateNoise()
+
+	floor := ns.config.SpectralFloor
+	for k, power := range ns.binPower {
+		gain := floor
+		if power > noisePowerFloor {
+			gain = math.Max(math.Sqrt(math.Max(1-ns.config.OverSubtraction*ns.noise[k]/power, 0)), floor)
+		}
+		ns.binGains[k] = gain
+	}
+
+	// 実信号なので共役対称なビンに同じゲインを掛ける
+	for k := range ns.spectrum {
+		bin := k
+		if bin > ns.frameSize/2 {
+			bin = ns.frameSize - k
+		}
+		ns.spectrum[k] *= complex(ns.binGains[bin], 0)
+	}
+	inverseFFT(ns.spectrum)
+
+	for i := range ns.overlap {
+		ns.overlap[i] += real(ns.spectrum[i]) * ns.window[i]
+	}
+	for _, y := range ns.overlap[:ns.hop] {
+		ns.output = append(ns.output, float32(y))
+	}
+	copy(ns.overlap, ns.overlap[ns.hop:])
+	clear(ns.overlap[ns.frameSize-ns.hop:])
+	ns.frames++
+}
+
+// updateNoise 雑音スペクトル推定値を更新
+// セッション先頭の区間は平均を取り、以降はパワーが雑音推定値に近いビンでのみ追従する
+// フレーム全体で判定すると低SNRの定常音が雑音推定値に取り込まれて消えるため、ビン毎に判定する
+func (ns *NoiseSuppressor) updateNoise() {
+	if ns.frames < ns.initFrames {
+		weight := 1 / float64(ns.frames+1)
+		for k, power := range ns.binPower {
+			ns.noise[k] += weight * (power - ns.noise[k])
+		}
+		return
+	}
+	rate := ns.config.NoiseAdaptRate
+	for k, power := range ns.binPower {
+		if power >= noiseUpdateRatio*ns.noise[k] && ns.noise[k] > 0 {
+			continue
+		}
+		ns.noise[k] += rate * (power - ns.noise[k])
+	}
+}
+
+// inverseFFT 逆フーリエ変換（インプレース）
+func inverseFFT(x []complex128) {
+	for i := range x {
+		x[i] = cmplx.Conj(x[i])
+	}
+	fft(x)
+	scale := 1 / float64(len(x))
+	for i := range x {
+		x[i] = cmplx.Conj(x[i]) * complex(scale, 0)
+	}
+}
@@ -0,0 +1,166 @@
+package inference
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"socket_inference/internal/model"
+)
+
+// whiteNoise 標準偏差 sigma の白色雑音（乱数は固定シード）
+func whiteNoise(sigma float64, n int, seed uint64) []float32 {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(sigma * rng.NormFloat64())
+	}
+	return samples
+}
+
+// sineSNR freq の正弦波成分を最小二乗で当てはめ、正弦波と残差のパワー比（dB）を返す
+func sineSNR(samples []float32, freq float64, sampleRate int) float64 {
+	var ss, sc, cc, ys, yc float64
+	for i, y := range samples {
+		phase := 2 * math.Pi * freq * float64(i) / float64(sampleRate)
+		s, c := math.Sin(phase), math.Cos(phase)
+		ss += s * s
+		sc += s * c
+		cc += c * c
+		ys += float64(y) * s
+		yc += float64(y) * c
+	}
+	det := ss*cc - sc*sc
+	a := (ys*cc - yc*sc) / det
+	b := (yc*ss - ys*sc) / det
+
+	var signal, residual float64
+	for i, y := range samples {
+		phase := 2 * math.Pi * freq * float64(i) / float64(sampleRate)
+		fit := a*math.Sin(phase) + b*math.Cos(phase)
+		signal += fit * fit
+		residual += (float64(y) - fit) * (float64(y) - fit)
+	}
+	return 10 * math.Log10(signal/residual)
+}
+
+// processInChunks 段にチャンク単位でサンプル列を通す
+func processInChunks(t *testing.T, stage Stage, samples []float32, sampleRate, chunkSize int) []float32 {
+	t.Helper()
+	var out []float32
+	for start := 0; start < len(samples); start += chunkSize {
+		buf := &AudioBuffer{
+			Samples:    append([]float32(nil), samples[start:min(start+chunkSize, len(samples))]...),
+			SampleRate: sampleRate,
+			Channels:   1,
+		}
+		if err := stage.Process(buf); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, buf.Samples...)
+	}
+	return out
+}
+
+func TestNoiseSuppressionImprovesSNR(t *testing.T) {
+	const (
+		sampleRate = 16000
+		freq       = 1000.0
+		chunkSize  = 320 // 20ms
+	)
+	config := model.DefaultPreprocessingConfig().NoiseSuppression
+
+	tests := []struct {
+		name          string
+		amplitude     float64
+		noiseSigma    float64
+		minImprovedDB float64
+	}{
+		{name: "SNR 10dB", amplitude: 0.3, noiseSigma: 0.067, minImprovedDB: 6},
+		{name: "SNR 0dB", amplitude: 0.1, noiseSigma: 0.071, minImprovedDB: 6},
+		{name: "SNR 20dB", amplitude: 0.5, noiseSigma: 0.035, minImprovedDB: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage := NewNoiseSuppressionStage(sampleRate, config)
+			latency := stage.suppressor.LatencySamples()
+
+			// 先頭は雑音のみ（初期の雑音推定区間）、その後に正弦波が重なる
+			leadIn := sampleRate * config.InitialNoiseMs / 1000
+			total := leadIn + 2*sampleRate
+			input := whiteNoise(tt.noiseSigma, total, 1)
+			tone := sineWave(freq, tt.amplitude, sampleRate, total)
+			for i := leadIn; i < total; i++ {
+				input[i] += tone[i]
+			}
+
+			output := processInChunks(t, stage, input, sampleRate, chunkSize)
+			if len(output) != len(input) {
+				t.Fatalf("出力サンプル数 = %d（期待値 %d）", len(output), len(input))
+			}
+
+			// 遅延と推定の収束を除いた区間で比較
+			start := leadIn + sampleRate/4
+			before := sineSNR(input[start:total-latency], freq, sampleRate)
+			after := sineSNR(output[start+latency:], freq, sampleRate)
+			if after-before < tt.minImprovedDB {
+				t.Fatalf("SNR %.1fdB -> %.1fdB（%.1fdB以上の改善を期待）", before, after, tt.minImprovedDB)
+			}
+		})
+	}
+}
+
+func TestNoiseSuppressionAttenuatesNoiseOnly(t *testing.T) {
+	const sampleRate = 16000
+	config := model.DefaultPreprocessingConfig().NoiseSuppression
+	stage := NewNoiseSuppressionStage(sampleRate, config)
+
+	input := whiteNoise(0.05, 2*sampleRate, 2)
+	output := processInChunks(t, stage, input, sampleRate, 512)
+
+	before := rmsDBFS(input[sampleRate:])
+	after := rmsDBFS(output[sampleRate:])
+	if before-after < 10 {
+		t.Fatalf("雑音のみの区間のレベル %.1fdBFS -> %.1fdBFS（10dB以上の減衰を期待）", before, after)
+	}
+}
+
+func TestAGCKeepsSNRWhileNormalizing(t *testing.T) {
+	const (
+		sampleRate = 16000
+		freq       = 440.0
+	)
+	config := model.DefaultPreprocessingConfig().AGC
+
+	tests := []struct {
+		name      string
+		amplitude float64
+	}{
+		{name: "小さい入力", amplitude: 0.02},
+		{name: "大きい入力", amplitude: 0.7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage := NewAGCStage(sampleRate, config)
+			input := whiteNoise(tt.amplitude/10, 2*sampleRate, 3)
+			tone := sineWave(freq, tt.amplitude, sampleRate, len(input))
+			for i := range input {
+				input[i] += tone[i]
+			}
+
+			output := processInChunks(t, stage, input, sampleRate, 320)
+
+			steady := output[sampleRate:]
+			if got := rmsDBFS(steady); !closeTo(got, config.TargetDBFS, 1) {
+				t.Fatalf("出力レベル = %.2fdBFS（期待値 %.2fdBFS）", got, config.TargetDBFS)
+			}
+			before := sineSNR(input[sampleRate:], freq, sampleRate)
+			after := sineSNR(steady, freq, sampleRate)
+			if before-after > 1 {
+				t.Fatalf("AGCでSNRが劣化しました: %.1fdB -> %.1fdB", before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package inference
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"socket_inference/internal/model"
+)
+
+// PCMDecoder セッションの音声フォーマットに従ってチャンクをfloat32に変換
+// チャンク境界で分割されたサンプルは次のチャンクと連結して復号する
+type PCMDecoder struct {
+	format  model.AudioFormat
+	pending []byte // 前チャンクから持ち越した未完成フレーム
+}
+
+// NewPCMDecoder 新しいPCMDecoderを作成
+func NewPCMDecoder(format model.AudioFormat) (*PCMDecoder, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	return &PCMDecoder{format: format}, nil
+}
+
+// Decode チャンクを復号し、インターリーブのfloat32サンプル列（-1.0〜1.0）を返す
+func (d *PCMDecoder) Decode(chunk []byte) ([]float32, error) {
+	data := chunk
+	if len(d.pending) > 0 {
+		data = append(d.pending, chunk...)
+		d.pending = nil
+	}
+
+	frameSize := d.format.FrameSize()
+	complete := len(data) - len(data)%frameSize
+	if complete < len(data) {
+		d.pending = append([]byte(nil), data[complete:]...)
+	}
+
+	sampleSize := d.format.Encoding.BytesPerSample()
+	samples := make([]float32, complete/sampleSize)
+
+	for i := range samples {
+		value, err := decodeSample(d.format.Encoding, data[i*sampleSize:(i+1)*sampleSize])
+		if err != nil {
+			d.pending = nil
+			return nil, fmt.Errorf("サンプル %d の復号に失敗: %w", i, err)
+		}
+		samples[i] = value
+	}
+
+	return samples, nil
+}
+
+// Pending 次のチャンクに持ち越している未完成フレームのバイト数
+func (d *PCMDecoder) Pending() int {
+	return len(d.pending)
+}
+
+// decodeSample 1サンプルをfloat32に変換
+func decodeSample(encoding model.AudioEncoding, b []byte) (float32, error) {
+	switch encoding {
+	case model.EncodingS16LE:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / 32768, nil
+	case model.EncodingS32LE:
+		return float32(float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648), nil
+	case model.EncodingF32LE:
+		value := math.Float32frombits(binary.LittleEndian.Uint32(b))
+		if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+			return 0, fmt.Errorf("不正なfloat32サンプル: %v", value)
+		}
+		return value, nil
+	case model.EncodingU8:
+		return (float32(b[0]) - 128) / 128, nil
+	case model.EncodingMulaw:
+		return float32(mulawToLinear(b[0])) / 32768, nil
+	case model.EncodingAlaw:
+		return float32(alawToLinear(b[0])) / 32768, nil
+	default:
+		return 0, fmt.Errorf("未対応の音声エンコーディング: %q", encoding)
+	}
+}
+
+// mulawToLinear G.711 μ-lawを16bitリニアPCMに変換
+func mulawToLinear(u byte) int16 {
+	u = ^u
+	sign := u & 0x80
+	exponent := (u >> 4) & 0x07
+	mantissa := u & 0x0F
+
+	magnitude := ((int16(mantissa) << 3) + 0x84) << exponent
+	magnitude -= 0x84
+	if sign != 0 {
+		return -magnitude
+	}
+	return magnitude
+}
+
+// alawToLinear G.711 A-lawを16bitリニアPCMに変換
+func alawToLinear(a byte) int16 {
+	a ^= 0x55
+	sign := a & 0x80
+	exponent := (a >> 4) & 0x07
+	mantissa := a & 0x0F
+
+	var magnitude int16
+	if exponent == 0 {
+		magnitude = (int16(mantissa) << 4) + 8
+	} else {
+		magnitude = ((int16(mantissa) << 4) + 0x108) << (exponent - 1)
+	}
+	if sign == 0 {
+		return -magnitude
+	}
+	return magnitude
+}
+
+// encodeSamples float32サンプル列を出力エンコーディングのバイト列に変換
+func encodeSamples(samples []float32, encoding model.AudioEncoding) ([]byte, error) {
+	switch encoding {
+	case model.EncodingF32LE:
+		out := make([]byte, len(samples)*4)
+		for i, s := range samples {
+			binary.LittleEndian.PutUint32(out[i*4:], math.Float32bits(s))
+		}
+		return out, nil
+	case model.EncodingS16LE:
+		out := make([]byte, len(samples)*2)
+		for i, s := range samples {
+			v := math.Round(float64(s) * 32768)
+			v = math.Max(-32768, math.Min(32767, v))
+			binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(v)))
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("未対応の出力エンコーディング: %q（f32le または s16le）", encoding)
+	}
+}
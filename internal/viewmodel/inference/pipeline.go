@@ -0,0 +1,174 @@
+package inference
+
+import (
+	"fmt"
+	"time"
+
+	"socket_inference/internal/model"
+)
+
+// AudioBuffer パイプラインの段の間で受け渡す音声データ
+type AudioBuffer struct {
+	Raw        []byte    // 復号前のバイト列（復号段で消費）
+	Samples    []float32 // インターリーブのfloat32サンプル列（-1.0〜1.0）
+	SampleRate int       // Samplesのサンプリングレート（Hz）
+	Channels   int       // Samplesのチャンネル数
+
+	Silent   bool                 // VADで無音と判定され、推論に送らなくてよい
+	Events   []model.SessionEvent // 段が検出したセッションイベント
+	Features [][]float32          // 段が抽出した特徴量（フレーム毎）
+}
+
+// ChunkResult パイプラインで処理した1チャンクの結果
+type ChunkResult struct {
+	Data     []byte               // 出力エンコーディングの音声データ
+	Silent   bool                 // VADで無音と判定され、推論に送らなくてよい
+	Events   []model.SessionEvent // 検出したセッションイベント
+	Features [][]float32          // 抽出した特徴量（フレーム毎）
+	Duration time.Duration        // 出力した音声の長さ
+}
+
+// Stage 前処理パイプラインの1段
+// 各段はセッション毎に作成され、チャンクを跨ぐフィルター状態を保持する
+type Stage interface {
+	// Name 段の名前
+	Name() string
+
+	// Process バッファを処理して書き換える
+	Process(buf *AudioBuffer) error
+}
+
+// Pipeline セッション毎の前処理パイプライン
+// チャンクを順序付きの段に通し、出力エンコーディングのバイト列に変換する
+type Pipeline struct {
+	format         model.AudioFormat
+	stages         []Stage
+	outputEncoding model.AudioEncoding
+	sampleRate     int                 // 出力のサンプリングレート
+	features       model.FeatureConfig // 特徴量抽出の設定（無効の場合はEnabled=false）
+}
+
+// NewPipeline 音声フォーマットと設定から前処理パイプラインを構築
+func NewPipeline(clientID string, format model.AudioFormat, config model.PreprocessingConfig) (*Pipeline, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	decode, err := NewDecodeStage(format)
+	if err != nil {
+		return nil, err
+	}
+	resample, err := NewResampleStage(format.SampleRate, config.Resample)
+	if err != nil {
+		return nil, err
+	}
+
+	stages := []Stage{decode, NewDownmixStage(), resample}
+	if config.DCRemoval.Enabled {
+		stages = append(stages, NewDCRemovalStage(config.DCRemoval))
+	}
+	if config.NoiseSuppression.Enabled {
+		stages = append(stages, NewNoiseSuppressionStage(config.Resample.TargetSampleRate, config.NoiseSuppression))
+	}
+	if config.VAD.Enabled {
+		vad, err := NewVADStage(clientID, config.Resample.TargetSampleRate, config.VAD)
+		if err != nil {
+			return nil, err
+		}
+		stages = append(stages, vad)
+	}
+	if config.PreEmphasis.Enabled {
+		stages = append(stages, NewPreEmphasisStage(config.PreEmphasis))
+	}
+	if config.Gain.Enabled {
+		stages = append(stages, NewGainStage(config.Gain))
+	}
+	if config.AGC.Enabled {
+		stages = append(stages, NewAGCStage(config.Resample.TargetSampleRate, config.AGC))
+	}
+	if config.Clipping.Enabled {
+		stages = append(stages, NewClippingStage(clientID, config.Clipping))
+	}
+	if config.Features.Enabled {
+		stages = append(stages, NewLogMelStage(config.Resample.TargetSampleRate, config.Features))
+	}
+
+	return &Pipeline{
+		format:         format,
+		stages:         stages,
+		outputEncoding: config.OutputEncoding,
+		sampleRate:     config.Resample.TargetSampleRate,
+		features:       config.Features,
+	}, nil
+}
+
+// Process チャンクを全ての段に通し、出力エンコーディングのバイト列と検出したイベントを返す
+func (p *Pipeline) Process(chunk []byte) (*ChunkResult, error) {
+	buf := &AudioBuffer{
+		Raw:        chunk,
+		SampleRate: p.format.SampleRate,
+		Channels:   p.format.Channels,
+	}
+	for _, stage := range p.stages {
+		if err := stage.Process(buf); err != nil {
+			return nil, fmt.Errorf("前処理段 %s でエラー: %w", stage.Name(), err)
+		}
+	}
+
+	data, err := encodeSamples(buf.Samples, p.outputEncoding)
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkResult{
+		Data:     data,
+		Silent:   buf.Silent,
+		Events:   buf.Events,
+		Features: buf.Features,
+		Duration: time.Duration(len(buf.Samples)) * time.Second / time.Duration(buf.SampleRate),
+	}, nil
+}
+
+// OutputFormat パイプラインが出力する音声フォーマット
+func (p *Pipeline) OutputFormat() model.AudioFormat {
+	return model.AudioFormat{
+		Encoding:   p.outputEncoding,
+		SampleRate: p.sampleRate,
+		Channels:   1,
+	}
+}
+
+// ExtractsFeatures 特徴量抽出が有効か
+func (p *Pipeline) ExtractsFeatures() bool {
+	return p.features.Enabled
+}
+
+// IncludesAudio 推論サーバーに音声データを送信するか（特徴量のみを送る場合はfalse）
+func (p *Pipeline) IncludesAudio() bool {
+	return !p.features.Enabled || p.features.IncludeAudio
+}
+
+// FeatureTensor フレーム毎の特徴量を推論サーバーに送るテンソルにまとめる
+func (p *Pipeline) FeatureTensor(frames [][]float32) *model.FeatureTensor {
+	data := make([]float32, 0, len(frames)*p.features.MelBins)
+	for _, frame := range frames {
+		data = append(data, frame...)
+	}
+	return &model.FeatureTensor{
+		Type:          model.FeatureTypeLogMel,
+		Shape:         []int{len(frames), p.features.MelBins},
+		Data:          data,
+		SampleRate:    p.sampleRate,
+		FrameLengthMs: p.features.FrameLengthMs,
+		HopMs:         p.features.HopMs,
+		CMVN:          p.features.CMVN,
+	}
+}
+
+// StageNames パイプラインを構成する段の名前（処理順）
+func (p *Pipeline) StageNames() []string {
+	names := make([]string, len(p.stages))
+	for i, stage := range p.stages {
+		names[i] = stage.Name()
+	}
+	return names
+}
@@ -0,0 +1,165 @@
+package inference
+
+import (
+	"fmt"
+	"math"
+
+	"socket_inference/internal/model"
+)
+
+// resampleFilterSpec 品質プリセット毎のフィルター設計パラメータ
+type resampleFilterSpec struct {
+	tapsPerPhase int     // 1位相あたりのタップ数（ダウンサンプリング時は変換比に応じて拡大）
+	kaiserBeta   float64 // Kaiser窓のβ（大きいほど阻止域減衰が大きい）
+	rolloff      float64 // 通過帯域端（ナイキスト周波数に対する比）
+}
+
+// resampleFilterSpecs 品質プリセットとフィルター設計の対応
+var resampleFilterSpecs = map[model.ResampleQuality]resampleFilterSpec{
+	model.ResampleQualityLow:    {tapsPerPhase: 8, kaiserBeta: 5.0, rolloff: 0.85},
+	model.ResampleQualityMedium: {tapsPerPhase: 16, kaiserBeta: 7.0, rolloff: 0.90},
+	model.ResampleQualityHigh:   {tapsPerPhase: 32, kaiserBeta: 9.0, rolloff: 0.945},
+}
+
+// Resampler 窓関数付きsincのポリフェーズフィルターによるサンプリングレート変換
+// 直前の入力サンプルとフィルター位相を保持し、バッチ境界でも連続した出力を生成する
+type Resampler struct {
+	inRate   int
+	outRate  int
+	up       int         // 補間倍率 L
+	down     int         // 間引き倍率 M
+	phases   [][]float32 // phases[位相][タップ] のフィルター係数
+	history  []float32   // 前回の入力の末尾（タップ数-1サンプル）
+	position int         // 次の出力サンプルの位置（補間後の時間軸、historyの先頭基準）
+}
+
+// NewResampler 新しいResamplerを作成
+func NewResampler(inRate, outRate int, quality model.ResampleQuality) (*Resampler, error) {
+	if inRate <= 0 || outRate <= 0 {
+		return nil, fmt.Errorf("サンプリングレートが不正です: %d -> %d", inRate, outRate)
+	}
+	spec, ok := resampleFilterSpecs[quality]
+	if !ok {
+		return nil, fmt.Errorf("未対応のリサンプリング品質: %q", quality)
+	}
+
+	g := gcd(inRate, outRate)
+	r := &Resampler{
+		inRate:  inRate,
+		outRate: outRate,
+		up:      outRate / g,
+		down:    inRate / g,
+	}
+	if inRate == outRate {
+		return r, nil
+	}
+
+	// ダウンサンプリング時はカットオフが下がる分だけフィルター長を伸ばし、遷移帯域幅を出力レート基準で保つ
+	taps := spec.tapsPerPhase
+	if r.down > r.up {
+		taps = (spec.tapsPerPhase*r.down + r.up - 1) / r.up
+	}
+
+	r.phases = designPolyphaseFilter(r.up, r.down, taps, spec)
+	r.history = make([]float32, taps-1)
+	r.position = len(r.history) * r.up
+	return r, nil
+}
+
+// InputRate 入力サンプリングレート
+func (r *Resampler) InputRate() int {
+	return r.inRate
+}
+
+// OutputRate 出力サンプリングレート
+func (r *Resampler) OutputRate() int {
+	return r.outRate
+}
+
+// Process 入力サンプル列を変換し、出力サンプリングレートのサンプル列を返す
+func (r *Resampler) Process(samples []float32) []float32 {
+	if r.inRate == r.outRate {
+		return samples
+	}
+
+	taps := len(r.history) + 1
+	buffer := make([]float32, 0, len(r.history)+len(samples))
+	buffer = append(buffer, r.history...)
+	buffer = append(buffer, samples...)
+
+	out := make([]float32, 0, len(samples)*r.up/r.down+1)
+	for {
+		base := r.position / r.up
+		if base >= len(buffer) {
+			break
+		}
+		coeffs := r.phases[r.position%r.up]
+
+		var acc float32
+		for k := 0; k < taps; k++ {
+			acc += coeffs[k] * buffer[base-k]
+		}
+		out = append(out, acc)
+		r.position += r.down
+	}
+
+	// 次回のために末尾を保持し、位置をhistoryの先頭基準に戻す
+	consumed := len(buffer) - len(r.history)
+	copy(r.history, buffer[consumed:])
+	r.position -= consumed * r.up
+
+	return out
+}
+
+// designPolyphaseFilter Kaiser窓付きsincの低域通過フィルターを設計し位相毎に分解
+func designPolyphaseFilter(up, down, taps int, spec resampleFilterSpec) [][]float32 {
+	length := up * taps
+	center := float64(length-1) / 2
+
+	// 補間後のサンプリングレートに対するカットオフ周波数（サイクル/サンプル）
+	cutoff := spec.rolloff * 0.5 / float64(max(up, down))
+	i0Beta := besselI0(spec.kaiserBeta)
+
+	phases := make([][]float32, up)
+	for p := range phases {
+		phases[p] = make([]float32, taps)
+	}
+
+	for n := 0; n < length; n++ {
+		t := float64(n) - center
+		sinc := 2 * cutoff
+		if t != 0 {
+			sinc = math.Sin(2*math.Pi*cutoff*t) / (math.Pi * t)
+		}
+
+		ratio := 2*float64(n)/float64(length-1) - 1
+		window := besselI0(spec.kaiserBeta*math.Sqrt(1-ratio*ratio)) / i0Beta
+
+		// 補間でゼロ挿入した分の振幅をup倍で補償
+		phases[n%up][n/up] = float32(float64(up) * sinc * window)
+	}
+
+	return phases
+}
+
+// besselI0 第1種変形ベッセル関数 I0（Kaiser窓用）
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	half := x / 2
+	for k := 1; k < 50; k++ {
+		term *= (half / float64(k)) * (half / float64(k))
+		sum += term
+		if term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+// gcd 最大公約数
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
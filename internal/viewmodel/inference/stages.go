@@ -0,0 +1,464 @@
+package inference
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"socket_inference/internal/model"
+)
+
+// 段の名前
+const (
+	StageDecode      = "decode"
+	StageDownmix     = "downmix"
+	StageResample    = "resample"
+	StageDCRemoval   = "dc_removal"
+	StageNoise       = "noise_suppression"
+	StageVAD         = "vad"
+	StagePreEmphasis = "pre_emphasis"
+	StageGain        = "gain"
+	StageAGC         = "agc"
+	StageClipping    = "clipping"
+	StageLogMel      = "log_mel"
+)
+
+// DecodeStage バイト列をインターリーブのfloat32サンプル列に復号する段
+type DecodeStage struct {
+	decoder *PCMDecoder
+}
+
+// NewDecodeStage 新しいDecodeStageを作成
+func NewDecodeStage(format model.AudioFormat) (*DecodeStage, error) {
+	decoder, err := NewPCMDecoder(format)
+	if err != nil {
+		return nil, err
+	}
+	return &DecodeStage{decoder: decoder}, nil
+}
+
+// Name 段の名前
+func (s *DecodeStage) Name() string {
+	return StageDecode
+}
+
+// Process Rawを復号してSamplesに設定
+func (s *DecodeStage) Process(buf *AudioBuffer) error {
+	samples, err := s.decoder.Decode(buf.Raw)
+	if err != nil {
+		return err
+	}
+	buf.Samples = samples
+	buf.Raw = nil
+	return nil
+}
+
+// DownmixStage 複数チャンネルを平均してモノラルに変換する段
+type DownmixStage struct{}
+
+// NewDownmixStage 新しいDownmixStageを作成
+func NewDownmixStage() *DownmixStage {
+	return &DownmixStage{}
+}
+
+// Name 段の名前
+func (s *DownmixStage) Name() string {
+	return StageDownmix
+}
+
+// Process インターリーブのサンプル列をモノラルに変換
+func (s *DownmixStage) Process(buf *AudioBuffer) error {
+	channels := buf.Channels
+	if channels <= 1 {
+		return nil
+	}
+
+	mono := make([]float32, len(buf.Samples)/channels)
+	for i := range mono {
+		var sum float32
+		for _, value := range buf.Samples[i*channels : (i+1)*channels] {
+			sum += value
+		}
+		mono[i] = sum / float32(channels)
+	}
+	buf.Samples = mono
+	buf.Channels = 1
+	return nil
+}
+
+// ResampleStage モノラルのサンプル列を目標サンプリングレートに変換する段
+type ResampleStage struct {
+	resampler *Resampler
+}
+
+// NewResampleStage 新しいResampleStageを作成
+func NewResampleStage(inputRate int, config model.ResampleConfig) (*ResampleStage, error) {
+	resampler, err := NewResampler(inputRate, config.TargetSampleRate, config.Quality)
+	if err != nil {
+		return nil, err
+	}
+	return &ResampleStage{resampler: resampler}, nil
+}
+
+// Name 段の名前
+func (s *ResampleStage) Name() string {
+	return StageResample
+}
+
+// Process サンプリングレートを変換
+func (s *ResampleStage) Process(buf *AudioBuffer) error {
+	if buf.Channels != 1 {
+		return fmt.Errorf("モノラル以外は変換できません（%dch）", buf.Channels)
+	}
+	if buf.SampleRate != s.resampler.InputRate() {
+		return fmt.Errorf("入力サンプリングレートが一致しません: %dHz（期待値 %dHz）", buf.SampleRate, s.resampler.InputRate())
+	}
+	buf.Samples = s.resampler.Process(buf.Samples)
+	buf.SampleRate = s.resampler.OutputRate()
+	return nil
+}
+
+// DCRemovalStage 1次ハイパスフィルターで直流成分を除去する段
+// y[n] = x[n] - x[n-1] + pole * y[n-1]
+type DCRemovalStage struct {
+	pole      float32
+	prevInput float32
+	prevOut   float32
+}
+
+// NewDCRemovalStage 新しいDCRemovalStageを作成
+func NewDCRemovalStage(config model.DCRemovalConfig) *DCRemovalStage {
+	return &DCRemovalStage{pole: float32(config.Pole)}
+}
+
+// Name 段の名前
+func (s *DCRemovalStage) Name() string {
+	return StageDCRemoval
+}
+
+// Process 直流成分を除去
+func (s *DCRemovalStage) Process(buf *AudioBuffer) error {
+	for i, x := range buf.Samples {
+		y := x - s.prevInput + s.pole*s.prevOut
+		s.prevInput = x
+		s.prevOut = y
+		buf.Samples[i] = y
+	}
+	return nil
+}
+
+// NoiseSuppressionStage スペクトル減算で定常雑音を抑圧する段
+type NoiseSuppressionStage struct {
+	suppressor *NoiseSuppressor
+}
+
+// NewNoiseSuppressionStage 新しいNoiseSuppressionStageを作成
+func NewNoiseSuppressionStage(sampleRate int, config model.NoiseSuppressionConfig) *NoiseSuppressionStage {
+	return &NoiseSuppressionStage{suppressor: NewNoiseSuppressor(sampleRate, config)}
+}
+
+// Name 段の名前
+func (s *NoiseSuppressionStage) Name() string {
+	return StageNoise
+}
+
+// Process 雑音を抑圧
+func (s *NoiseSuppressionStage) Process(buf *AudioBuffer) error {
+	if buf.Channels != 1 {
+		return fmt.Errorf("モノラル以外は雑音抑圧できません（%dch）", buf.Channels)
+	}
+	buf.Samples = s.suppressor.Process(buf.Samples)
+	return nil
+}
+
+// VADStage 発話区間を検出し、発話開始・終了のイベントと無音チャンクの判定を付与する段
+// サンプルは変更しない
+type VADStage struct {
+	clientID    string
+	vad         *VAD
+	dropSilence bool
+}
+
+// NewVADStage 新しいVADStageを作成
+func NewVADStage(clientID string, sampleRate int, config model.VADConfig) (*VADStage, error) {
+	vad, err := NewVAD(sampleRate, config)
+	if err != nil {
+		return nil, err
+	}
+	return &VADStage{
+		clientID:    clientID,
+		vad:         vad,
+		dropSilence: config.DropSilence,
+	}, nil
+}
+
+// Name 段の名前
+func (s *VADStage) Name() string {
+	return StageVAD
+}
+
+// Process 発話区間を判定
+func (s *VADStage) Process(buf *AudioBuffer) error {
+	if buf.Channels != 1 {
+		return fmt.Errorf("モノラル以外は判定できません（%dch）", buf.Channels)
+	}
+
+	result := s.vad.Process(buf.Samples)
+	for _, transition := range result.Transitions {
+		buf.Events = append(buf.Events, model.SessionEvent{
+			Type:      transition.Type,
+			ClientID:  s.clientID,
+			OffsetMs:  transition.OffsetMs,
+			Timestamp: time.Now(),
+		})
+	}
+	if s.dropSilence && !result.Active {
+		buf.Silent = true
+	}
+	return nil
+}
+
+// PreEmphasisStage 高域を強調する段
+// y[n] = x[n] - coefficient * x[n-1]
+type PreEmphasisStage struct {
+	coefficient float32
+	prevInput   float32
+}
+
+// NewPreEmphasisStage 新しいPreEmphasisStageを作成
+func NewPreEmphasisStage(config model.PreEmphasisConfig) *PreEmphasisStage {
+	return &PreEmphasisStage{coefficient: float32(config.Coefficient)}
+}
+
+// Name 段の名前
+func (s *PreEmphasisStage) Name() string {
+	return StagePreEmphasis
+}
+
+// Process 高域を強調
+func (s *PreEmphasisStage) Process(buf *AudioBuffer) error {
+	for i, x := range buf.Samples {
+		buf.Samples[i] = x - s.coefficient*s.prevInput
+		s.prevInput = x
+	}
+	return nil
+}
+
+// gainSilenceDBFS これより小さいレベルのチャンクではゲインを更新しない（無音の過増幅を防止）
+const gainSilenceDBFS = -60
+
+// GainStage チャンクのRMSレベルを目標レベルに近づける段
+// ゲインはチャンク間で平滑化し、最大増幅量で制限する
+type GainStage struct {
+	targetDBFS float64
+	maxGain    float64
+	smoothing  float64
+	gain       float64
+}
+
+// NewGainStage 新しいGainStageを作成
+func NewGainStage(config model.GainConfig) *GainStage {
+	return &GainStage{
+		targetDBFS: config.TargetDBFS,
+		maxGain:    dbToLinear(config.MaxGainDB),
+		smoothing:  config.Smoothing,
+		gain:       1,
+	}
+}
+
+// Name 段の名前
+func (s *GainStage) Name() string {
+	return StageGain
+}
+
+// Gain 現在のゲイン（線形）
+func (s *GainStage) Gain() float64 {
+	return s.gain
+}
+
+// Process ゲインを更新して適用
+func (s *GainStage) Process(buf *AudioBuffer) error {
+	if len(buf.Samples) == 0 {
+		return nil
+	}
+
+	if level := rmsDBFS(buf.Samples); level > gainSilenceDBFS {
+		desired := math.Min(dbToLinear(s.targetDBFS-level), s.maxGain)
+		s.gain = s.smoothing*s.gain + (1-s.smoothing)*desired
+	}
+
+	gain := float32(s.gain)
+	for i := range buf.Samples {
+		buf.Samples[i] *= gain
+	}
+	return nil
+}
+
+// agcDetectorMs AGCのレベル検出の時定数（ミリ秒）
+const agcDetectorMs = 10
+
+// AGCStage サンプル単位でレベルを追従し、アタック・リリースの時定数でゲインを制御する段
+// GainStageがチャンク単位の正規化なのに対し、チャンク内の急なレベル変化にも追従する
+type AGCStage struct {
+	targetPower float64
+	maxGain     float64
+	gatePower   float64
+	detector    float64 // レベル検出の平滑化係数
+	attack      float64 // ゲインを下げる際の平滑化係数
+	release     float64 // ゲインを上げる際の平滑化係数
+	level       float64 // 平滑化したパワー
+	gain        float64
+}
+
+// NewAGCStage 新しいAGCStageを作成
+func NewAGCStage(sampleRate int, config model.AGCConfig) *AGCStage {
+	return &AGCStage{
+		targetPower: dbToPower(config.TargetDBFS),
+		maxGain:     dbToLinear(config.MaxGainDB),
+		gatePower:   dbToPower(config.GateDBFS),
+		detector:    smoothingCoefficient(agcDetectorMs, sampleRate),
+		attack:      smoothingCoefficient(config.AttackMs, sampleRate),
+		release:     smoothingCoefficient(config.ReleaseMs, sampleRate),
+		gain:        1,
+	}
+}
+
+// Name 段の名前
+func (s *AGCStage) Name() string {
+	return StageAGC
+}
+
+// Gain 現在のゲイン（線形）
+func (s *AGCStage) Gain() float64 {
+	return s.gain
+}
+
+// Process ゲインを更新しながら適用
+func (s *AGCStage) Process(buf *AudioBuffer) error {
+	for i, x := range buf.Samples {
+		power := float64(x) * float64(x)
+		s.level += s.detector * (power - s.level)
+
+		if s.level > s.gatePower {
+			desired := math.Min(math.Sqrt(s.targetPower/s.level), s.maxGain)
+			if desired < s.gain {
+				s.gain += s.attack * (desired - s.gain)
+			} else {
+				s.gain += s.release * (desired - s.gain)
+			}
+		}
+		buf.Samples[i] = x * float32(s.gain)
+	}
+	return nil
+}
+
+// clippingWarnInterval クリッピング警告ログの最小間隔
+const clippingWarnInterval = 5 * time.Second
+
+// ClippingStage 振幅が閾値に達したサンプルを検出して警告し、出力を-1.0〜1.0に制限する段
+type ClippingStage struct {
+	clientID  string
+	threshold float32
+	warnRatio float64
+	clipped   int64
+	total     int64
+	lastWarn  time.Time
+}
+
+// NewClippingStage 新しいClippingStageを作成
+func NewClippingStage(clientID string, config model.ClippingConfig) *ClippingStage {
+	return &ClippingStage{
+		clientID:  clientID,
+		threshold: float32(config.Threshold),
+		warnRatio: config.WarnRatio,
+	}
+}
+
+// Name 段の名前
+func (s *ClippingStage) Name() string {
+	return StageClipping
+}
+
+// Counts これまでに検出したクリップサンプル数と処理したサンプル数
+func (s *ClippingStage) Counts() (clipped, total int64) {
+	return s.clipped, s.total
+}
+
+// Process クリッピングを検出して振幅を制限
+func (s *ClippingStage) Process(buf *AudioBuffer) error {
+	if len(buf.Samples) == 0 {
+		return nil
+	}
+
+	clipped := 0
+	for i, x := range buf.Samples {
+		if x >= s.threshold || x <= -s.threshold {
+			clipped++
+		}
+		buf.Samples[i] = max(-1, min(1, x))
+	}
+	s.clipped += int64(clipped)
+	s.total += int64(len(buf.Samples))
+
+	ratio := float64(clipped) / float64(len(buf.Samples))
+	if clipped > 0 && ratio >= s.warnRatio && time.Since(s.lastWarn) >= clippingWarnInterval {
+		s.lastWarn = time.Now()
+		log.Printf("クライアント %s の音声でクリッピングを検出: %.1f%%（累計 %d / %d サンプル）",
+			s.clientID, ratio*100, s.clipped, s.total)
+	}
+	return nil
+}
+
+// LogMelStage 対数メルスペクトログラムを抽出する段
+// サンプルは変更せず、抽出したフレームをFeaturesに追加する
+type LogMelStage struct {
+	extractor *LogMelExtractor
+}
+
+// NewLogMelStage 新しいLogMelStageを作成
+func NewLogMelStage(sampleRate int, config model.FeatureConfig) *LogMelStage {
+	return &LogMelStage{extractor: NewLogMelExtractor(sampleRate, config)}
+}
+
+// Name 段の名前
+func (s *LogMelStage) Name() string {
+	return StageLogMel
+}
+
+// Process 特徴量を抽出
+func (s *LogMelStage) Process(buf *AudioBuffer) error {
+	if buf.Channels != 1 {
+		return fmt.Errorf("モノラル以外は特徴量を抽出できません（%dch）", buf.Channels)
+	}
+	buf.Features = append(buf.Features, s.extractor.Process(buf.Samples)...)
+	return nil
+}
+
+// rmsDBFS サンプル列のRMSレベル（dBFS）
+func rmsDBFS(samples []float32) float64 {
+	var sum float64
+	for _, x := range samples {
+		sum += float64(x) * float64(x)
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+	if rms == 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(rms)
+}
+
+// dbToPower デシベルをパワーの倍率に変換
+func dbToPower(db float64) float64 {
+	return math.Pow(10, db/10)
+}
+
+// smoothingCoefficient 時定数（ミリ秒）を1次平滑化の係数に変換
+func smoothingCoefficient(ms, sampleRate int) float64 {
+	return 1 - math.Exp(-1000/(float64(ms)*float64(sampleRate)))
+}
+
+// dbToLinear デシベルを線形の倍率に変換
+func dbToLinear(db float64) float64 {
+	return math.Pow(10, db/20)
+}
@@ -0,0 +1,420 @@
+package inference
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"socket_inference/internal/model"
+)
+
+// sineWave 振幅 amplitude、周波数 freq のモノラル正弦波
+func sineWave(freq, amplitude float64, sampleRate, n int) []float32 {
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+	}
+	return samples
+}
+
+// rms サンプル列のRMS振幅
+func rms(samples []float32) float64 {
+	var sum float64
+	for _, x := range samples {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// closeTo 許容誤差 tolerance で一致するか
+func closeTo(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestDecodeStage(t *testing.T) {
+	s16 := func(values ...int16) []byte {
+		out := make([]byte, 0, len(values)*2)
+		for _, v := range values {
+			out = binary.LittleEndian.AppendUint16(out, uint16(v))
+		}
+		return out
+	}
+	f32 := func(values ...float32) []byte {
+		out := make([]byte, 0, len(values)*4)
+		for _, v := range values {
+			out = binary.LittleEndian.AppendUint32(out, math.Float32bits(v))
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		format  model.AudioFormat
+		chunks  [][]byte
+		want    []float32
+		wantErr bool
+	}{
+		{
+			name:   "s16le",
+			format: model.AudioFormat{Encoding: model.EncodingS16LE, SampleRate: 16000, Channels: 1},
+			chunks: [][]byte{s16(0, 16384, -32768, 32767)},
+			want:   []float32{0, 0.5, -1, 32767.0 / 32768},
+		},
+		{
+			name:   "チャンク境界で分割されたサンプル",
+			format: model.AudioFormat{Encoding: model.EncodingS16LE, SampleRate: 16000, Channels: 1},
+			chunks: [][]byte{s16(16384)[:1], append(s16(16384)[1:], s16(-16384)...)},
+			want:   []float32{0.5, -0.5},
+		},
+		{
+			name:   "ステレオのフレーム境界",
+			format: model.AudioFormat{Encoding: model.EncodingS16LE, SampleRate: 16000, Channels: 2},
+			chunks: [][]byte{s16(16384, -16384, 8192)[:5], s16(16384, -16384, 8192, 8192)[5:]},
+			want:   []float32{0.5, -0.5, 0.25, 0.25},
+		},
+		{
+			name:   "s32le",
+			format: model.AudioFormat{Encoding: model.EncodingS32LE, SampleRate: 16000, Channels: 1},
+			chunks: [][]byte{binary.LittleEndian.AppendUint32(nil, uint32(1<<30))},
+			want:   []float32{0.5},
+		},
+		{
+			name:   "f32le",
+			format: model.AudioFormat{Encoding: model.EncodingF32LE, SampleRate: 16000, Channels: 1},
+			chunks: [][]byte{f32(0.25, -0.75)},
+			want:   []float32{0.25, -0.75},
+		},
+		{
+			name:   "u8",
+			format: model.AudioFormat{Encoding: model.EncodingU8, SampleRate: 8000, Channels: 1},
+			chunks: [][]byte{{128, 0, 192}},
+			want:   []float32{0, -1, 0.5},
+		},
+		{
+			name:   "μ-law",
+			format: model.AudioFormat{Encoding: model.EncodingMulaw, SampleRate: 8000, Channels: 1},
+			chunks: [][]byte{{0xFF, 0x00, 0x80}},
+			want:   []float32{0, -32124.0 / 32768, 32124.0 / 32768},
+		},
+		{
+			name:   "A-law",
+			format: model.AudioFormat{Encoding: model.EncodingAlaw, SampleRate: 8000, Channels: 1},
+			chunks: [][]byte{{0xD5, 0x55, 0xAA}},
+			want:   []float32{8.0 / 32768, -8.0 / 32768, 32256.0 / 32768},
+		},
+		{
+			name:    "float32のNaN",
+			format:  model.AudioFormat{Encoding: model.EncodingF32LE, SampleRate: 16000, Channels: 1},
+			chunks:  [][]byte{f32(float32(math.NaN()))},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage, err := NewDecodeStage(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []float32
+			for _, chunk := range tt.chunks {
+				buf := &AudioBuffer{Raw: chunk}
+				err = stage.Process(buf)
+				if err != nil {
+					break
+				}
+				if buf.Raw != nil {
+					t.Fatal("復号後もRawが残っています")
+				}
+				got = append(got, buf.Samples...)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("エラーになるはずが成功しました")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("サンプル数 = %d（期待値 %d）: %v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !closeTo(float64(got[i]), float64(tt.want[i]), 1e-6) {
+					t.Fatalf("サンプル %d = %v（期待値 %v）", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDownmixStage(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels int
+		samples  []float32
+		want     []float32
+	}{
+		{name: "モノラルはそのまま", channels: 1, samples: []float32{0.1, -0.2}, want: []float32{0.1, -0.2}},
+		{name: "ステレオの平均", channels: 2, samples: []float32{0.5, -0.5, 1, 0, 0.2, 0.4}, want: []float32{0, 0.5, 0.3}},
+		{name: "4チャンネルの平均", channels: 4, samples: []float32{1, 1, 0, 0, -1, 0, 0, 0}, want: []float32{0.5, -0.25}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &AudioBuffer{Samples: tt.samples, Channels: tt.channels}
+			if err := NewDownmixStage().Process(buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.Channels != 1 {
+				t.Fatalf("チャンネル数 = %d（期待値 1）", buf.Channels)
+			}
+			if len(buf.Samples) != len(tt.want) {
+				t.Fatalf("サンプル数 = %d（期待値 %d）", len(buf.Samples), len(tt.want))
+			}
+			for i := range tt.want {
+				if !closeTo(float64(buf.Samples[i]), float64(tt.want[i]), 1e-6) {
+					t.Fatalf("サンプル %d = %v（期待値 %v）", i, buf.Samples[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestResampleStage(t *testing.T) {
+	const (
+		targetRate = 16000
+		freq       = 440.0
+		amplitude  = 0.5
+		chunkSize  = 317 // 変換比で割り切れないチャンク長でバッチ境界の連続性を確認
+	)
+
+	tests := []struct {
+		name      string
+		inputRate int
+		quality   model.ResampleQuality
+	}{
+		{name: "同一レート", inputRate: 16000, quality: model.ResampleQualityMedium},
+		{name: "48kHzからのダウンサンプリング", inputRate: 48000, quality: model.ResampleQualityMedium},
+		{name: "44.1kHzからのダウンサンプリング", inputRate: 44100, quality: model.ResampleQualityHigh},
+		{name: "8kHzからのアップサンプリング", inputRate: 8000, quality: model.ResampleQualityLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage, err := NewResampleStage(tt.inputRate, model.ResampleConfig{TargetSampleRate: targetRate, Quality: tt.quality})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			input := sineWave(freq, amplitude, tt.inputRate, tt.inputRate) // 1秒
+			var output []float32
+			for start := 0; start < len(input); start += chunkSize {
+				buf := &AudioBuffer{
+					Samples:    append([]float32(nil), input[start:min(start+chunkSize, len(input))]...),
+					SampleRate: tt.inputRate,
+					Channels:   1,
+				}
+				if err := stage.Process(buf); err != nil {
+					t.Fatal(err)
+				}
+				if buf.SampleRate != targetRate {
+					t.Fatalf("サンプリングレート = %d（期待値 %d）", buf.SampleRate, targetRate)
+				}
+				output = append(output, buf.Samples...)
+			}
+
+			if !closeTo(float64(len(output)), targetRate, 1) {
+				t.Fatalf("出力サンプル数 = %d（期待値 約 %d）", len(output), targetRate)
+			}
+
+			// フィルターの立ち上がりを除いた区間が同じ正弦波になっているか
+			steady := output[targetRate/10:]
+			if got, want := rms(steady), amplitude/math.Sqrt2; !closeTo(got, want, want*0.02) {
+				t.Fatalf("RMS = %.4f（期待値 %.4f）", got, want)
+			}
+			var maxStep float64
+			for i := 1; i < len(steady); i++ {
+				maxStep = math.Max(maxStep, math.Abs(float64(steady[i]-steady[i-1])))
+			}
+			if limit := 2 * math.Pi * freq / targetRate * amplitude * 1.05; maxStep > limit {
+				t.Fatalf("隣接サンプルの差 %.4f が正弦波の最大傾き %.4f を超えています（不連続）", maxStep, limit)
+			}
+		})
+	}
+}
+
+func TestResampleStageRejectsMismatchedInput(t *testing.T) {
+	stage, err := NewResampleStage(48000, model.ResampleConfig{TargetSampleRate: 16000, Quality: model.ResampleQualityMedium})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		buf  *AudioBuffer
+	}{
+		{name: "ステレオ", buf: &AudioBuffer{Samples: make([]float32, 4), SampleRate: 48000, Channels: 2}},
+		{name: "宣言と異なるレート", buf: &AudioBuffer{Samples: make([]float32, 4), SampleRate: 44100, Channels: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := stage.Process(tt.buf); err == nil {
+				t.Fatal("エラーになるはずが成功しました")
+			}
+		})
+	}
+}
+
+func TestGainStage(t *testing.T) {
+	const sampleRate = 16000
+
+	tests := []struct {
+		name      string
+		amplitude float64
+		config    model.GainConfig
+		wantDBFS  float64 // 収束後の出力レベル
+	}{
+		{
+			name:      "小さい音を目標レベルまで増幅",
+			amplitude: 0.01,
+			config:    model.GainConfig{TargetDBFS: -20, MaxGainDB: 30, Smoothing: 0.5},
+			wantDBFS:  -20,
+		},
+		{
+			name:      "大きい音を目標レベルまで減衰",
+			amplitude: 0.9,
+			config:    model.GainConfig{TargetDBFS: -20, MaxGainDB: 30, Smoothing: 0.5},
+			wantDBFS:  -20,
+		},
+		{
+			name:      "最大増幅量で制限",
+			amplitude: 0.005,
+			config:    model.GainConfig{TargetDBFS: -20, MaxGainDB: 20, Smoothing: 0},
+			wantDBFS:  20*math.Log10(0.005/math.Sqrt2) + 20,
+		},
+		{
+			name:      "無音付近ではゲインを更新しない",
+			amplitude: 0.0001,
+			config:    model.GainConfig{TargetDBFS: -20, MaxGainDB: 30, Smoothing: 0},
+			wantDBFS:  20 * math.Log10(0.0001/math.Sqrt2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage := NewGainStage(tt.config)
+
+			var out []float32
+			for i := 0; i < 30; i++ {
+				buf := &AudioBuffer{Samples: sineWave(440, tt.amplitude, sampleRate, sampleRate/50), SampleRate: sampleRate, Channels: 1}
+				if err := stage.Process(buf); err != nil {
+					t.Fatal(err)
+				}
+				out = buf.Samples
+			}
+
+			if got := rmsDBFS(out); !closeTo(got, tt.wantDBFS, 0.5) {
+				t.Fatalf("出力レベル = %.2fdBFS（期待値 %.2fdBFS）", got, tt.wantDBFS)
+			}
+		})
+	}
+}
+
+func TestAGCStageTracksLevelChange(t *testing.T) {
+	const sampleRate = 16000
+	config := model.DefaultPreprocessingConfig().AGC
+	stage := NewAGCStage(sampleRate, config)
+
+	// 小さい音が続いた後に急に大きくなっても、アタックで目標レベルに戻る
+	tests := []struct {
+		name      string
+		amplitude float64
+	}{
+		{name: "小さい音", amplitude: 0.02},
+		{name: "急に大きい音", amplitude: 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &AudioBuffer{Samples: sineWave(440, tt.amplitude, sampleRate, 2*sampleRate), SampleRate: sampleRate, Channels: 1}
+			if err := stage.Process(buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := rmsDBFS(buf.Samples[sampleRate:]); !closeTo(got, config.TargetDBFS, 1) {
+				t.Fatalf("出力レベル = %.2fdBFS（期待値 %.2fdBFS）", got, config.TargetDBFS)
+			}
+		})
+	}
+}
+
+func TestDCRemovalStage(t *testing.T) {
+	const sampleRate = 16000
+	stage := NewDCRemovalStage(model.DefaultPreprocessingConfig().DCRemoval)
+
+	input := sineWave(440, 0.3, sampleRate, sampleRate)
+	for i := range input {
+		input[i] += 0.2
+	}
+	buf := &AudioBuffer{Samples: input, SampleRate: sampleRate, Channels: 1}
+	if err := stage.Process(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var mean float64
+	steady := buf.Samples[sampleRate/2:]
+	for _, x := range steady {
+		mean += float64(x)
+	}
+	mean /= float64(len(steady))
+	if math.Abs(mean) > 1e-3 {
+		t.Fatalf("直流成分 = %.5f（期待値 0）", mean)
+	}
+	if got, want := rms(steady), 0.3/math.Sqrt2; !closeTo(got, want, want*0.02) {
+		t.Fatalf("正弦波のRMS = %.4f（期待値 %.4f）", got, want)
+	}
+}
+
+func TestPipelineProcess(t *testing.T) {
+	format := model.AudioFormat{Encoding: model.EncodingS16LE, SampleRate: 48000, Channels: 2}
+	config := model.DefaultPreprocessingConfig()
+	config.Gain.Enabled = true
+	pipeline, err := NewPipeline("client", format, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{StageDecode, StageDownmix, StageResample, StageDCRemoval, StageGain, StageClipping}
+	if got := pipeline.StageNames(); len(got) != len(want) {
+		t.Fatalf("段 = %v（期待値 %v）", got, want)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("段 = %v（期待値 %v）", got, want)
+			}
+		}
+	}
+
+	// 100msのステレオs16le
+	tone := sineWave(440, 0.5, format.SampleRate, format.SampleRate/10)
+	chunk := make([]byte, 0, len(tone)*4)
+	for _, x := range tone {
+		v := uint16(int16(x * 32767))
+		chunk = binary.LittleEndian.AppendUint16(chunk, v)
+		chunk = binary.LittleEndian.AppendUint16(chunk, v)
+	}
+
+	result, err := pipeline.Process(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputSamples := len(result.Data) / model.EncodingF32LE.BytesPerSample()
+	if !closeTo(float64(outputSamples), 1600, 1) {
+		t.Fatalf("出力サンプル数 = %d（期待値 約 1600）", outputSamples)
+	}
+	if result.Duration.Milliseconds() < 99 || result.Duration.Milliseconds() > 100 {
+		t.Fatalf("出力の長さ = %s（期待値 100ms）", result.Duration)
+	}
+}
@@ -0,0 +1,154 @@
+package inference
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"socket_inference/internal/model"
+	vmInterfaces "socket_inference/internal/viewmodel/interfaces"
+)
+
+// NewTaskHandler セッションが宣言した推論タスクのハンドラーを作成
+// タスクが未指定（ゼロ値）の場合は音声認識
+func NewTaskHandler(config model.TaskConfig) (vmInterfaces.TaskHandler, error) {
+	if config.Type == "" {
+		config = model.DefaultTaskConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch config.Type {
+	case model.TaskClassification:
+		return &classificationTask{}, nil
+	case model.TaskSpeakerEmbedding:
+		return &embeddingTask{}, nil
+	case model.TaskKeywordSpotting:
+		return &keywordTask{keywords: config.Keywords}, nil
+	default:
+		return &transcriptionTask{}, nil
+	}
+}
+
+// newTaskRequest 全タスク共通のフィールドで推論リクエストを作成
+func newTaskRequest(task model.TaskType, batch *model.AudioBatch) *model.InferenceRequest {
+	return &model.InferenceRequest{
+		ClientID:   batch.ClientID,
+		AudioData:  batch.AudioData,
+		Timestamp:  batch.Timestamp,
+		BatchSize:  batch.BatchSize,
+		Features:   batch.Features,
+		Format:     batch.Format,
+		Sequence:   batch.Sequence,
+		OffsetMs:   batch.OffsetMs,
+		DurationMs: batch.DurationMs,
+		Task:       task,
+	}
+}
+
+// transcriptionTask 音声認識
+type transcriptionTask struct{}
+
+// Task 対応する推論タスク
+func (t *transcriptionTask) Task() model.TaskType {
+	return model.TaskTranscription
+}
+
+// BuildRequest 前処理済みのバッチから推論リクエストを作成
+func (t *transcriptionTask) BuildRequest(batch *model.AudioBatch) *model.InferenceRequest {
+	return newTaskRequest(model.TaskTranscription, batch)
+}
+
+// HandleResponse 音声認識の結果はそのまま配信する
+func (t *transcriptionTask) HandleResponse(response *model.InferenceResponse) ([]model.SessionEvent, error) {
+	return nil, nil
+}
+
+// classificationTask 音響イベント等の分類
+type classificationTask struct{}
+
+// Task 対応する推論タスク
+func (t *classificationTask) Task() model.TaskType {
+	return model.TaskClassification
+}
+
+// BuildRequest 前処理済みのバッチから推論リクエストを作成
+func (t *classificationTask) BuildRequest(batch *model.AudioBatch) *model.InferenceRequest {
+	return newTaskRequest(model.TaskClassification, batch)
+}
+
+// HandleResponse ラベルをスコアの降順に並べ、最上位のラベルを従来形式の結果にする
+func (t *classificationTask) HandleResponse(response *model.InferenceResponse) ([]model.SessionEvent, error) {
+	if len(response.Labels) == 0 {
+		return nil, errors.New("分類結果にラベルがありません")
+	}
+	sort.SliceStable(response.Labels, func(i, j int) bool {
+		return response.Labels[i].Score > response.Labels[j].Score
+	})
+	if response.Result == "" {
+		response.Result = response.Labels[0].Label
+		response.Confidence = response.Labels[0].Score
+	}
+	return nil, nil
+}
+
+// embeddingTask 話者埋め込み
+type embeddingTask struct{}
+
+// Task 対応する推論タスク
+func (t *embeddingTask) Task() model.TaskType {
+	return model.TaskSpeakerEmbedding
+}
+
+// BuildRequest 前処理済みのバッチから推論リクエストを作成
+func (t *embeddingTask) BuildRequest(batch *model.AudioBatch) *model.InferenceRequest {
+	return newTaskRequest(model.TaskSpeakerEmbedding, batch)
+}
+
+// HandleResponse 埋め込みベクトルが含まれることを検証
+func (t *embeddingTask) HandleResponse(response *model.InferenceResponse) ([]model.SessionEvent, error) {
+	if len(response.Embedding) == 0 {
+		return nil, errors.New("話者埋め込みの結果にベクトルがありません")
+	}
+	return nil, nil
+}
+
+// keywordTask キーワード検出
+type keywordTask struct {
+	keywords []string
+}
+
+// Task 対応する推論タスク
+func (t *keywordTask) Task() model.TaskType {
+	return model.TaskKeywordSpotting
+}
+
+// BuildRequest 検出対象のキーワードを付けて推論リクエストを作成
+func (t *keywordTask) BuildRequest(batch *model.AudioBatch) *model.InferenceRequest {
+	request := newTaskRequest(model.TaskKeywordSpotting, batch)
+	request.Keywords = t.keywords
+	return request
+}
+
+// HandleResponse 検出したキーワード毎に keyword イベントを通知
+func (t *keywordTask) HandleResponse(response *model.InferenceResponse) ([]model.SessionEvent, error) {
+	events := make([]model.SessionEvent, 0, len(response.Detected))
+	keywords := make([]string, 0, len(response.Detected))
+	for _, detection := range response.Detected {
+		events = append(events, model.SessionEvent{
+			Type:      model.SessionEventKeyword,
+			ClientID:  response.ClientID,
+			OffsetMs:  detection.StartMs,
+			Timestamp: time.Now(),
+			Keyword:   detection.Keyword,
+			Score:     detection.Score,
+		})
+		keywords = append(keywords, detection.Keyword)
+	}
+	if response.Result == "" {
+		response.Result = strings.Join(keywords, ",")
+	}
+	return events, nil
+}
@@ -0,0 +1,261 @@
+package inference
+
+import (
+	"fmt"
+	"math"
+
+	"socket_inference/internal/model"
+)
+
+// VAD判定の定数
+const (
+	vadMinSpeechDBFS     = -50.0  // これより小さいレベルのフレームは常に無音
+	vadInitialNoiseDBFS  = -60.0  // 雑音レベル推定の初期値
+	vadFricativeZCRate   = 3000.0 // 無声子音とみなすゼロ交差率（回/秒）
+	vadGMMAdaptRate      = 0.05   // 混合ガウスモデルの適応速度
+	vadGMMMinVariance    = 1.0    // 分散の下限（エネルギー次元, dB^2）
+	vadGMMMinSeparation  = 6.0    // 音声モデルと雑音モデルの平均エネルギーの最小差（dB）
+	vadZCRNormalization  = 1000.0 // ゼロ交差率を特徴量にする際の単位（回/秒）
+	vadGMMMinZCRVariance = 0.25   // 分散の下限（ゼロ交差率次元）
+)
+
+// vadEnergyThresholdsDB 積極度毎の雑音レベルに対するエネルギー閾値（dB）
+var vadEnergyThresholdsDB = [4]float64{6, 9, 12, 15}
+
+// vadLikelihoodThresholds 積極度毎の対数尤度比の閾値（GMM方式）
+var vadLikelihoodThresholds = [4]float64{-1, 0.5, 2, 3.5}
+
+// VADTransition 発話区間の開始・終了
+type VADTransition struct {
+	Type     model.SessionEventType // speech_start または speech_end
+	OffsetMs int64                  // セッション先頭からの位置（ミリ秒）
+}
+
+// VADResult 1チャンク分の判定結果
+type VADResult struct {
+	Frames       int             // 判定したフレーム数
+	SpeechFrames int             // 音声と判定したフレーム数
+	Active       bool            // チャンク内に音声フレームまたは発話区間を含むか
+	Transitions  []VADTransition // チャンク内で確定した発話区間の開始・終了
+}
+
+// VAD エネルギーとゼロ交差率（またはGMM）による発話区間検出
+// 雑音レベルの推定値とフレームの端数をチャンク間で保持する
+type VAD struct {
+	config       model.VADConfig
+	sampleRate   int
+	frameSize    int
+	minSpeech    int // 発話開始に必要な連続音声フレーム数
+	hangover     int // 発話終了に必要な連続無音フレーム数
+	pending      []float32
+	frameIndex   int64 // 判定済みフレーム数（セッション先頭から）
+	noiseDB      float64
+	gmm          *vadGMM
+	inSpeech     bool
+	speechRun    int
+	silenceRun   int
+	speechStart  int64 // 発話開始候補のフレーム番号
+	lastSpeechAt int64 // 最後に音声と判定したフレーム番号
+}
+
+// NewVAD 新しいVADを作成
+func NewVAD(sampleRate int, config model.VADConfig) (*VAD, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	frameSize := sampleRate * config.FrameMs / 1000
+	if frameSize <= 0 {
+		return nil, fmt.Errorf("サンプリングレートが不正です: %d", sampleRate)
+	}
+
+	v := &VAD{
+		config:     config,
+		sampleRate: sampleRate,
+		frameSize:  frameSize,
+		minSpeech:  max(1, config.MinSpeechMs/config.FrameMs),
+		hangover:   max(1, config.HangoverMs/config.FrameMs),
+		noiseDB:    vadInitialNoiseDBFS,
+	}
+	if config.Mode == model.VADModeGMM {
+		v.gmm = newVADGMM()
+	}
+	return v, nil
+}
+
+// InSpeech 発話区間中か
+func (v *VAD) InSpeech() bool {
+	return v.inSpeech
+}
+
+// Process サンプル列をフレーム毎に判定し、発話区間の状態を更新
+// フレームに満たない端数は次のチャンクと連結して判定する
+func (v *VAD) Process(samples []float32) VADResult {
+	result := VADResult{Active: v.inSpeech}
+
+	data := samples
+	if len(v.pending) > 0 {
+		data = append(v.pending, samples...)
+		v.pending = nil
+	}
+
+	offset := 0
+	for ; offset+v.frameSize <= len(data); offset += v.frameSize {
+		speech := v.classify(data[offset : offset+v.frameSize])
+		result.Frames++
+		if speech {
+			result.SpeechFrames++
+			result.Active = true
+		}
+		if transition, ok := v.update(speech); ok {
+			result.Transitions = append(result.Transitions, transition)
+		}
+		if v.inSpeech {
+			result.Active = true
+		}
+		v.frameIndex++
+	}
+	if offset < len(data) {
+		v.pending = append([]float32(nil), data[offset:]...)
+	}
+
+	return result
+}
+
+// classify 1フレームが音声かを判定
+func (v *VAD) classify(frame []float32) bool {
+	energyDB := rmsDBFS(frame)
+	if math.IsInf(energyDB, -1) {
+		energyDB = -120
+	}
+	zcRate := zeroCrossingRate(frame) * float64(v.sampleRate)
+
+	if v.gmm != nil {
+		return v.gmm.classify(energyDB, zcRate/vadZCRNormalization, vadLikelihoodThresholds[v.config.Aggressiveness])
+	}
+
+	threshold := vadEnergyThresholdsDB[v.config.Aggressiveness]
+	speech := energyDB > vadMinSpeechDBFS && (energyDB > v.noiseDB+threshold ||
+		(energyDB > v.noiseDB+threshold/2 && zcRate >= vadFricativeZCRate))
+
+	// 雑音レベルは下降に速く、上昇に遅く追従する（発話中は更新しない）
+	if !speech {
+		if energyDB < v.noiseDB {
+			v.noiseDB = 0.7*v.noiseDB + 0.3*energyDB
+		} else {
+			v.noiseDB = 0.98*v.noiseDB + 0.02*energyDB
+		}
+	}
+	return speech
+}
+
+// update フレームの判定結果から発話区間の状態を更新し、開始・終了を確定
+func (v *VAD) update(speech bool) (VADTransition, bool) {
+	if !v.inSpeech {
+		if !speech {
+			v.speechRun = 0
+			return VADTransition{}, false
+		}
+		if v.speechRun == 0 {
+			v.speechStart = v.frameIndex
+		}
+		v.speechRun++
+		v.lastSpeechAt = v.frameIndex
+		if v.speechRun < v.minSpeech {
+			return VADTransition{}, false
+		}
+		v.inSpeech = true
+		v.silenceRun = 0
+		return VADTransition{Type: model.SessionEventSpeechStart, OffsetMs: v.frameOffsetMs(v.speechStart)}, true
+	}
+
+	if speech {
+		v.silenceRun = 0
+		v.lastSpeechAt = v.frameIndex
+		return VADTransition{}, false
+	}
+	v.silenceRun++
+	if v.silenceRun < v.hangover {
+		return VADTransition{}, false
+	}
+	v.inSpeech = false
+	v.speechRun = 0
+	return VADTransition{Type: model.SessionEventSpeechEnd, OffsetMs: v.frameOffsetMs(v.lastSpeechAt + 1)}, true
+}
+
+// frameOffsetMs フレーム番号をセッション先頭からの位置（ミリ秒）に変換
+func (v *VAD) frameOffsetMs(frame int64) int64 {
+	return frame * int64(v.config.FrameMs)
+}
+
+// zeroCrossingRate 1サンプルあたりのゼロ交差の割合
+func zeroCrossingRate(frame []float32) float64 {
+	if len(frame) < 2 {
+		return 0
+	}
+	crossings := 0
+	for i := 1; i < len(frame); i++ {
+		if (frame[i-1] >= 0) != (frame[i] >= 0) {
+			crossings++
+		}
+	}
+	return float64(crossings) / float64(len(frame)-1)
+}
+
+// vadGMM 音声・雑音それぞれを対角共分散のガウス分布でモデル化した判定器
+// 特徴量は（エネルギー dBFS, ゼロ交差率）で、判定されたクラスのモデルを逐次適応させる
+type vadGMM struct {
+	noise  vadGaussian
+	speech vadGaussian
+}
+
+// vadGaussian 2次元の対角共分散ガウス分布
+type vadGaussian struct {
+	mean     [2]float64
+	variance [2]float64
+}
+
+// newVADGMM 初期モデルを作成
+func newVADGMM() *vadGMM {
+	return &vadGMM{
+		noise:  vadGaussian{mean: [2]float64{vadInitialNoiseDBFS, 1.0}, variance: [2]float64{25, 4}},
+		speech: vadGaussian{mean: [2]float64{-25, 2.0}, variance: [2]float64{100, 4}},
+	}
+}
+
+// classify 対数尤度比で判定し、判定されたクラスのモデルを更新
+func (g *vadGMM) classify(energyDB, zcr, threshold float64) bool {
+	x := [2]float64{energyDB, zcr}
+	speech := energyDB > vadMinSpeechDBFS && g.speech.logLikelihood(x)-g.noise.logLikelihood(x) > threshold
+
+	if speech {
+		g.speech.adapt(x)
+	} else {
+		g.noise.adapt(x)
+	}
+	// 雑音モデルに引きずられて音声モデルが縮退しないよう平均エネルギーの差を保つ
+	if g.speech.mean[0] < g.noise.mean[0]+vadGMMMinSeparation {
+		g.speech.mean[0] = g.noise.mean[0] + vadGMMMinSeparation
+	}
+	return speech
+}
+
+// logLikelihood 対数尤度（定数項を除く）
+func (n *vadGaussian) logLikelihood(x [2]float64) float64 {
+	var ll float64
+	for i := range x {
+		d := x[i] - n.mean[i]
+		ll -= 0.5 * (d*d/n.variance[i] + math.Log(n.variance[i]))
+	}
+	return ll
+}
+
+// adapt 観測値に向けて平均と分散を更新
+func (n *vadGaussian) adapt(x [2]float64) {
+	minVariance := [2]float64{vadGMMMinVariance, vadGMMMinZCRVariance}
+	for i := range x {
+		d := x[i] - n.mean[i]
+		n.mean[i] += vadGMMAdaptRate * d
+		n.variance[i] += vadGMMAdaptRate * (d*d - n.variance[i])
+		n.variance[i] = math.Max(n.variance[i], minVariance[i])
+	}
+}
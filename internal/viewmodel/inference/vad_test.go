@@ -0,0 +1,131 @@
+package inference
+
+import (
+	"testing"
+
+	"socket_inference/internal/model"
+)
+
+// vadSegment 合成信号の区間（tone が true なら正弦波、false なら微小な雑音）
+type vadSegment struct {
+	tone bool
+	ms   int
+}
+
+// vadSignal 区間を連結した16kHzモノラルの合成信号
+// 雑音は -60dBFS 程度で常に無音、正弦波は -13dBFS 程度で音声と判定される
+func vadSignal(segments ...vadSegment) []float32 {
+	const sampleRate = 16000
+	var samples []float32
+	for i, segment := range segments {
+		n := sampleRate * segment.ms / 1000
+		if segment.tone {
+			samples = append(samples, sineWave(440, 0.3, sampleRate, n)...)
+		} else {
+			samples = append(samples, whiteNoise(0.001, n, uint64(i+1))...)
+		}
+	}
+	return samples
+}
+
+// vadConfig テスト用のVAD設定（20msフレーム、発話開始60ms、発話終了300ms）
+func vadConfig(mode model.VADMode) model.VADConfig {
+	config := model.DefaultPreprocessingConfig().VAD
+	config.Enabled = true
+	config.Mode = mode
+	return config
+}
+
+func TestVADTransitions(t *testing.T) {
+	silence := func(ms int) vadSegment { return vadSegment{ms: ms} }
+	tone := func(ms int) vadSegment { return vadSegment{tone: true, ms: ms} }
+	start := func(ms int64) VADTransition {
+		return VADTransition{Type: model.SessionEventSpeechStart, OffsetMs: ms}
+	}
+	end := func(ms int64) VADTransition {
+		return VADTransition{Type: model.SessionEventSpeechEnd, OffsetMs: ms}
+	}
+
+	tests := []struct {
+		name     string
+		segments []vadSegment
+		want     []VADTransition
+	}{
+		{
+			name:     "発話区間",
+			segments: []vadSegment{silence(500), tone(1000), silence(1000)},
+			want:     []VADTransition{start(500), end(1500)},
+		},
+		{
+			name:     "minSpeech 未満の短い音は発話としない",
+			segments: []vadSegment{silence(500), tone(40), silence(1000)},
+		},
+		{
+			name:     "hangover 未満の途切れは発話を継続",
+			segments: []vadSegment{silence(500), tone(400), silence(200), tone(400), silence(1000)},
+			want:     []VADTransition{start(500), end(1500)},
+		},
+		{
+			name:     "hangover 以上の途切れで発話を分割",
+			segments: []vadSegment{silence(500), tone(400), silence(400), tone(400), silence(1000)},
+			want:     []VADTransition{start(500), end(900), start(1300), end(1700)},
+		},
+		{
+			name:     "無音のみ",
+			segments: []vadSegment{silence(2000)},
+		},
+	}
+
+	for _, mode := range []model.VADMode{model.VADModeEnergy, model.VADModeGMM} {
+		for _, tt := range tests {
+			t.Run(string(mode)+"/"+tt.name, func(t *testing.T) {
+				vad, err := NewVAD(16000, vadConfig(mode))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// フレーム長（320サンプル）の倍数でないチャンクに分けて、端数の持ち越しも確認する
+				samples := vadSignal(tt.segments...)
+				var got []VADTransition
+				for offset := 0; offset < len(samples); offset += 1000 {
+					result := vad.Process(samples[offset:min(offset+1000, len(samples))])
+					got = append(got, result.Transitions...)
+				}
+
+				if len(got) != len(tt.want) {
+					t.Fatalf("発話区間の開始・終了 = %+v（期待値 %+v）", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Fatalf("%d 番目の発話区間の開始・終了 = %+v（期待値 %+v）", i, got[i], tt.want[i])
+					}
+				}
+				if vad.InSpeech() {
+					t.Fatal("末尾の無音の後も発話区間中です")
+				}
+			})
+		}
+	}
+}
+
+func TestVADProcessMarksSilentChunksInactive(t *testing.T) {
+	vad, err := NewVAD(16000, vadConfig(model.VADModeEnergy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 100ms毎のチャンク: 無音 500ms → 正弦波 500ms → 無音 1000ms
+	samples := vadSignal(vadSegment{ms: 500}, vadSegment{tone: true, ms: 500}, vadSegment{ms: 1000})
+	for i := 0; i*1600 < len(samples); i++ {
+		result := vad.Process(samples[i*1600 : (i+1)*1600])
+		if result.Frames != 5 {
+			t.Fatalf("チャンク %d: 判定したフレーム数 = %d（期待値 5）", i, result.Frames)
+		}
+
+		// 発話終了（1000ms + hangover 300ms）を確定するまでは発話区間中として扱う
+		wantActive := i >= 5 && i < 13
+		if result.Active != wantActive {
+			t.Fatalf("チャンク %d: Active = %v（期待値 %v）", i, result.Active, wantActive)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package inference
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"socket_inference/internal/model"
+)
+
+// maxWAVHeaderBytes dataチャンクが見つかるまでに許容するヘッダーの最大バイト数
+const maxWAVHeaderBytes = 64 * 1024
+
+// WAVフォーマットタグ
+const (
+	wavFormatPCM        = 0x0001
+	wavFormatMSADPCM    = 0x0002
+	wavFormatIEEEFloat  = 0x0003
+	wavFormatALaw       = 0x0006
+	wavFormatMuLaw      = 0x0007
+	wavFormatIMAADPCM   = 0x0011
+	wavFormatExtensible = 0xFFFE
+)
+
+// ErrWAVHeaderIncomplete ヘッダーの解析にさらにデータが必要
+var ErrWAVHeaderIncomplete = errors.New("WAVヘッダーが不完全です")
+
+// WAVHeader WAVヘッダーから得られる情報
+type WAVHeader struct {
+	Format        model.AudioFormat // 音声フォーマット
+	BitsPerSample int               // サンプルあたりのビット数
+	DataOffset    int               // 音声データ（dataチャンク本体）の開始位置
+}
+
+// IsWAV データがRIFF/WAVEヘッダーで始まるか
+// 判定に必要な12バイトに満たない場合はプレフィックスが一致するかで判定する
+func IsWAV(data []byte) bool {
+	if len(data) >= 12 {
+		return bytes.Equal(data[0:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WAVE"))
+	}
+	n := min(len(data), 4)
+	return bytes.Equal(data[:n], []byte("RIFF")[:n])
+}
+
+// ParseWAVHeader RIFF/WAVEヘッダーを解析してフォーマットと音声データの開始位置を返す
+// dataチャンクまで読み込めていない場合は ErrWAVHeaderIncomplete を返す
+func ParseWAVHeader(data []byte) (*WAVHeader, error) {
+	if len(data) < 12 {
+		return nil, ErrWAVHeaderIncomplete
+	}
+	if !IsWAV(data) {
+		return nil, fmt.Errorf("RIFF/WAVEヘッダーではありません")
+	}
+
+	var header *WAVHeader
+	pos := 12
+	for {
+		if pos+8 > len(data) {
+			return nil, incompleteOrTooLarge(pos)
+		}
+
+		chunkID := string(data[pos : pos+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := pos + 8
+
+		switch chunkID {
+		case "fmt ":
+			if body+chunkSize > len(data) {
+				return nil, incompleteOrTooLarge(body + chunkSize)
+			}
+			parsed, err := parseFmtChunk(data[body : body+chunkSize])
+			if err != nil {
+				return nil, err
+			}
+			header = parsed
+
+		case "data":
+			if header == nil {
+				return nil, fmt.Errorf("%w: fmtチャンクより前にdataチャンクがあります", model.ErrUnsupportedAudioFormat)
+			}
+			// ストリーミングではdataチャンクのサイズが未確定（0や0xFFFFFFFF）のため参照しない
+			header.DataOffset = body
+			return header, nil
+		}
+
+		// チャンクは2バイト境界に整列される
+		pos = body + chunkSize + chunkSize%2
+	}
+}
+
+// incompleteOrTooLarge ヘッダーが上限を超えていればエラー、そうでなければ追加データを要求
+func incompleteOrTooLarge(required int) error {
+	if required > maxWAVHeaderBytes {
+		return fmt.Errorf("%w: WAVヘッダーが大きすぎます（%dバイト超）", model.ErrUnsupportedAudioFormat, maxWAVHeaderBytes)
+	}
+	return ErrWAVHeaderIncomplete
+}
+
+// parseFmtChunk fmtチャンクを解析
+func parseFmtChunk(chunk []byte) (*WAVHeader, error) {
+	if len(chunk) < 16 {
+		return nil, fmt.Errorf("%w: fmtチャンクが短すぎます（%dバイト）", model.ErrUnsupportedAudioFormat, len(chunk))
+	}
+
+	formatTag := binary.LittleEndian.Uint16(chunk[0:2])
+	channels := int(binary.LittleEndian.Uint16(chunk[2:4]))
+	sampleRate := int(binary.LittleEndian.Uint32(chunk[4:8]))
+	bitsPerSample := int(binary.LittleEndian.Uint16(chunk[14:16]))
+
+	// WAVE_FORMAT_EXTENSIBLEはサブフォーマットGUIDの先頭2バイトが実際のフォーマットタグ
+	if formatTag == wavFormatExtensible {
+		if len(chunk) < 26 {
+			return nil, fmt.Errorf("%w: WAVE_FORMAT_EXTENSIBLEのfmtチャンクが短すぎます", model.ErrUnsupportedAudioFormat)
+		}
+		formatTag = binary.LittleEndian.Uint16(chunk[24:26])
+	}
+
+	encoding, err := wavEncoding(formatTag, bitsPerSample)
+	if err != nil {
+		return nil, err
+	}
+
+	format := model.AudioFormat{
+		Encoding:   encoding,
+		SampleRate: sampleRate,
+		Channels:   channels,
+	}
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &WAVHeader{
+		Format:        format,
+		BitsPerSample: bitsPerSample,
+	}, nil
+}
+
+// wavEncoding WAVのフォーマットタグとビット深度から音声エンコーディングを決定
+func wavEncoding(formatTag uint16, bitsPerSample int) (model.AudioEncoding, error) {
+	switch formatTag {
+	case wavFormatPCM:
+		switch bitsPerSample {
+		case 8:
+			return model.EncodingU8, nil
+		case 16:
+			return model.EncodingS16LE, nil
+		case 32:
+			return model.EncodingS32LE, nil
+		}
+		return "", fmt.Errorf("%w: %dbitのリニアPCMには対応していません", model.ErrUnsupportedAudioFormat, bitsPerSample)
+	case wavFormatIEEEFloat:
+		if bitsPerSample == 32 {
+			return model.EncodingF32LE, nil
+		}
+		return "", fmt.Errorf("%w: %dbitの浮動小数点PCMには対応していません", model.ErrUnsupportedAudioFormat, bitsPerSample)
+	case wavFormatALaw:
+		return model.EncodingAlaw, nil
+	case wavFormatMuLaw:
+		return model.EncodingMulaw, nil
+	case wavFormatMSADPCM, wavFormatIMAADPCM:
+		return "", fmt.Errorf("%w: ADPCM圧縮WAV（フォーマットタグ 0x%04X）には対応していません。リニアPCMで送信してください",
+			model.ErrUnsupportedAudioFormat, formatTag)
+	default:
+		return "", fmt.Errorf("%w: WAVフォーマットタグ 0x%04X には対応していません", model.ErrUnsupportedAudioFormat, formatTag)
+	}
+}
@@ -0,0 +1,166 @@
+package inference
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"socket_inference/internal/model"
+)
+
+// riffChunk チャンクIDとサイズのヘッダーを付けたRIFFチャンク（奇数サイズは1バイトの詰め物を付ける）
+func riffChunk(id string, body []byte) []byte {
+	chunk := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	chunk = append(chunk, body...)
+	if len(body)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// wavFile チャンクを連結したRIFF/WAVEデータ（RIFFのサイズはストリーミングと同様に参照しない値）
+func wavFile(chunks ...[]byte) []byte {
+	data := append([]byte("RIFF"), 0xFF, 0xFF, 0xFF, 0xFF)
+	data = append(data, "WAVE"...)
+	for _, chunk := range chunks {
+		data = append(data, chunk...)
+	}
+	return data
+}
+
+// fmtBody 16バイトのfmtチャンク本体
+func fmtBody(formatTag uint16, channels, sampleRate, bitsPerSample int) []byte {
+	blockAlign := channels * bitsPerSample / 8
+	body := binary.LittleEndian.AppendUint16(nil, formatTag)
+	body = binary.LittleEndian.AppendUint16(body, uint16(channels))
+	body = binary.LittleEndian.AppendUint32(body, uint32(sampleRate))
+	body = binary.LittleEndian.AppendUint32(body, uint32(sampleRate*blockAlign))
+	body = binary.LittleEndian.AppendUint16(body, uint16(blockAlign))
+	body = binary.LittleEndian.AppendUint16(body, uint16(bitsPerSample))
+	return body
+}
+
+// extensibleFmtBody WAVE_FORMAT_EXTENSIBLEの40バイトのfmtチャンク本体
+func extensibleFmtBody(subFormat uint16, channels, sampleRate, bitsPerSample int) []byte {
+	body := fmtBody(wavFormatExtensible, channels, sampleRate, bitsPerSample)
+	body = binary.LittleEndian.AppendUint16(body, 22)                    // 拡張部のサイズ
+	body = binary.LittleEndian.AppendUint16(body, uint16(bitsPerSample)) // 有効ビット数
+	body = binary.LittleEndian.AppendUint32(body, 0x3)                   // チャンネルマスク
+	// サブフォーマットGUID（先頭2バイトがフォーマットタグ、残りはKSDATAFORMAT_SUBTYPEの共通部分）
+	body = binary.LittleEndian.AppendUint16(body, subFormat)
+	body = append(body, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71)
+	return body
+}
+
+func TestParseWAVHeader(t *testing.T) {
+	pcm16 := riffChunk("fmt ", fmtBody(wavFormatPCM, 1, 16000, 16))
+	data := riffChunk("data", nil)
+
+	tests := []struct {
+		name           string
+		data           []byte
+		wantFormat     model.AudioFormat
+		wantBits       int
+		wantDataOffset int
+	}{
+		{
+			name:           "44バイトのヘッダー",
+			data:           wavHeader(16000, 1, 3200),
+			wantFormat:     model.AudioFormat{Encoding: model.EncodingS16LE, SampleRate: 16000, Channels: 1},
+			wantBits:       16,
+			wantDataOffset: 44,
+		},
+		{
+			name:           "奇数サイズのLISTチャンクの詰め物を読み飛ばす",
+			data:           wavFile(pcm16, riffChunk("LIST", []byte("INFOx")), data),
+			wantFormat:     model.AudioFormat{Encoding: model.EncodingS16LE, SampleRate: 16000, Channels: 1},
+			wantBits:       16,
+			wantDataOffset: 12 + 24 + 14 + 8,
+		},
+		{
+			name:           "fmtチャンクより前のLISTチャンク",
+			data:           wavFile(riffChunk("LIST", []byte("INF")), pcm16, data),
+			wantFormat:     model.AudioFormat{Encoding: model.EncodingS16LE, SampleRate: 16000, Channels: 1},
+			wantBits:       16,
+			wantDataOffset: 12 + 12 + 24 + 8,
+		},
+		{
+			name:           "WAVE_FORMAT_EXTENSIBLEのリニアPCM",
+			data:           wavFile(riffChunk("fmt ", extensibleFmtBody(wavFormatPCM, 2, 48000, 16)), data),
+			wantFormat:     model.AudioFormat{Encoding: model.EncodingS16LE, SampleRate: 48000, Channels: 2},
+			wantBits:       16,
+			wantDataOffset: 12 + 48 + 8,
+		},
+		{
+			name:           "WAVE_FORMAT_EXTENSIBLEの浮動小数点PCM",
+			data:           wavFile(riffChunk("fmt ", extensibleFmtBody(wavFormatIEEEFloat, 1, 16000, 32)), data),
+			wantFormat:     model.AudioFormat{Encoding: model.EncodingF32LE, SampleRate: 16000, Channels: 1},
+			wantBits:       32,
+			wantDataOffset: 12 + 48 + 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := ParseWAVHeader(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if header.Format != tt.wantFormat || header.BitsPerSample != tt.wantBits {
+				t.Fatalf("フォーマット = %+v, %dbit（期待値 %+v, %dbit）", header.Format, header.BitsPerSample, tt.wantFormat, tt.wantBits)
+			}
+			if header.DataOffset != tt.wantDataOffset {
+				t.Fatalf("音声データの開始位置 = %d（期待値 %d）", header.DataOffset, tt.wantDataOffset)
+			}
+		})
+	}
+}
+
+func TestParseWAVHeaderRejectsUnsupportedFormat(t *testing.T) {
+	data := riffChunk("data", nil)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "MS ADPCM", data: wavFile(riffChunk("fmt ", fmtBody(wavFormatMSADPCM, 1, 16000, 4)), data)},
+		{name: "IMA ADPCM", data: wavFile(riffChunk("fmt ", fmtBody(wavFormatIMAADPCM, 1, 16000, 4)), data)},
+		{name: "WAVE_FORMAT_EXTENSIBLEのADPCM", data: wavFile(riffChunk("fmt ", extensibleFmtBody(wavFormatIMAADPCM, 1, 16000, 4)), data)},
+		{name: "24bitのリニアPCM", data: wavFile(riffChunk("fmt ", fmtBody(wavFormatPCM, 1, 16000, 24)), data)},
+		{name: "短すぎるWAVE_FORMAT_EXTENSIBLE", data: wavFile(riffChunk("fmt ", fmtBody(wavFormatExtensible, 1, 16000, 16)), data)},
+		{name: "fmtチャンクより前のdataチャンク", data: wavFile(data, riffChunk("fmt ", fmtBody(wavFormatPCM, 1, 16000, 16)))},
+		{name: "上限を超えるヘッダー", data: wavFile(riffChunk("LIST", make([]byte, maxWAVHeaderBytes)))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWAVHeader(tt.data)
+			if !errors.Is(err, model.ErrUnsupportedAudioFormat) {
+				t.Fatalf("エラー = %v（期待値 %v）", err, model.ErrUnsupportedAudioFormat)
+			}
+		})
+	}
+}
+
+func TestParseWAVHeaderIncomplete(t *testing.T) {
+	// ヘッダーが複数のフレームに分かれて届く場合、dataチャンクのヘッダーまで揃うまでは追加データを要求する
+	full := wavFile(
+		riffChunk("LIST", []byte("INFOx")),
+		riffChunk("fmt ", extensibleFmtBody(wavFormatPCM, 1, 16000, 16)),
+		riffChunk("data", nil),
+	)
+
+	for n := 0; n < len(full); n++ {
+		if _, err := ParseWAVHeader(full[:n]); !errors.Is(err, ErrWAVHeaderIncomplete) {
+			t.Fatalf("先頭 %d バイト: エラー = %v（期待値 %v）", n, err, ErrWAVHeaderIncomplete)
+		}
+	}
+
+	header, err := ParseWAVHeader(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.DataOffset != len(full) {
+		t.Fatalf("音声データの開始位置 = %d（期待値 %d）", header.DataOffset, len(full))
+	}
+}
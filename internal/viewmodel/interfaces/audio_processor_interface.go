@@ -10,9 +10,21 @@ type AudioProcessor interface {
 	// ProcessAudioData 音声データを処理してバッチ化
 	ProcessAudioData(clientID string, audioData []byte)
 
+	// FlushClient 指定クライアントのバッファ済みデータを即座にバッチ化し、バッファを解放
+	FlushClient(clientID string)
+
+	// RemoveClient 指定クライアントのバッファ済みデータを破棄し、状態を解放（切断時に呼び出す）
+	RemoveClient(clientID string)
+
 	// GetBatchReady 完成したバッチを受信するチャネルを取得
 	GetBatchReady() <-chan *model.AudioBatch
 
+	// SetEndpointer 発話の終端でバッチを区切るよう設定（maxChunksは終端が来ない場合の上限）
+	SetEndpointer(endpointer SpeechEndpointer, maxChunks int)
+
+	// SetDropHandler チャネルが満杯で破棄したバッチの通知先を設定
+	SetDropHandler(handler func(batch *model.AudioBatch))
+
 	// StartProcessing バックグラウンド処理を開始
 	StartProcessing(ctx context.Context)
 
@@ -25,9 +37,21 @@ type AudioBatcher interface {
 	// AddAudioData 音声データをバッファに追加
 	AddAudioData(clientID string, audioData []byte)
 
+	// FlushClient 指定クライアントのバッファ済みデータを即座にバッチ化し、バッファを解放
+	FlushClient(clientID string)
+
+	// RemoveClient 指定クライアントのバッファ済みデータを破棄し、状態を解放（切断時に呼び出す）
+	RemoveClient(clientID string)
+
 	// GetBatchReady 完成したバッチのチャネルを取得
 	GetBatchReady() <-chan *model.AudioBatch
 
+	// SetEndpointer 発話の終端でバッチを区切るよう設定（maxChunksは終端が来ない場合の上限）
+	SetEndpointer(endpointer SpeechEndpointer, maxChunks int)
+
+	// SetDropHandler チャネルが満杯で破棄したバッチの通知先を設定（ロックの外で呼び出される）
+	SetDropHandler(handler func(batch *model.AudioBatch))
+
 	// StartPeriodicFlush 定期フラッシュを開始
 	StartPeriodicFlush(ctx context.Context)
 }
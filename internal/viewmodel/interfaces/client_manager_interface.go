@@ -13,6 +13,9 @@ type ClientManager interface {
 	// GetConnectedClients 接続中のクライアント一覧を取得
 	GetConnectedClients() []*model.AudioClient
 
+	// GetClientsBySession 指定セッションの接続中のクライアントを取得
+	GetClientsBySession(sessionID string) []*model.AudioClient
+
 	// GetClientCount 接続中のクライアント数を取得
 	GetClientCount() int
 }
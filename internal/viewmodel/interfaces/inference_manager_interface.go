@@ -2,29 +2,70 @@ package interfaces
 
 import (
 	"context"
+	"time"
+
+	"socket_inference/internal/infrastructure/interfaces"
 	"socket_inference/internal/model"
 )
 
 // InferenceManager 推論処理管理のインターフェース
 type InferenceManager interface {
-	// ProcessBatch バッチを推論処理
-	ProcessBatch(batch *model.AudioBatch) (*model.InferenceResponse, error)
+	// ProcessBatch バッチを推論処理（無音のみのバッチは推論せずnilを返す）
+	// ctx の取り消しで送信中の推論を中止する
+	ProcessBatch(ctx context.Context, batch *model.AudioBatch) (*model.InferenceResponse, error)
 
 	// StartProcessing バックグラウンド推論処理を開始
 	StartProcessing(ctx context.Context, batchChan <-chan *model.AudioBatch)
 
+	// RegisterSession セッションが宣言した音声フォーマットを登録
+	// ctx が取り消されるか登録を解除すると、セッションの推論を中止する
+	RegisterSession(ctx context.Context, clientID string, format model.AudioFormat) error
+
+	// UnregisterSession セッションの切断を通知し、送信中の推論を中止
+	UnregisterSession(clientID string)
+
+	// SetPreprocessingConfig 全セッション共通の前処理設定を検証して適用
+	SetPreprocessingConfig(config model.PreprocessingConfig) error
+
+	// SetSessionPreprocessingConfig セッション固有の前処理設定を検証して適用
+	SetSessionPreprocessingConfig(clientID string, config model.PreprocessingConfig) error
+
+	// SetSessionTask セッションが宣言した推論タスクを検証して適用
+	SetSessionTask(clientID string, config model.TaskConfig) error
+
+	// SetSessionTimeout セッションが宣言した1バッチの推論のタイムアウトを適用（0の場合はデフォルト）
+	SetSessionTimeout(clientID string, timeout time.Duration) error
+
+	// SetSessionModel セッションが宣言した推論モデルと送信先の推論クライアントを適用
+	SetSessionModel(clientID string, info model.ModelInfo, client interfaces.InferenceClient) error
+
 	// GetResultChannel 推論結果のチャネルを取得
 	GetResultChannel() <-chan *model.InferenceResponse
 
+	// GetEventChannel セッションイベント（発話開始・終了、無音バッチの破棄、キーワード検出）のチャネルを取得
+	GetEventChannel() <-chan *model.SessionEvent
+
+	// Stats 推論処理の稼働状況（待ち行列の長さ、送信中のリクエスト数等）を取得
+	Stats() model.InferenceStats
+
 	// Shutdown 推論処理を停止
 	Shutdown()
 }
 
 // AudioPreprocessor 音声前処理のインターフェース
 type AudioPreprocessor interface {
-	// PreprocessBatch 音声バッチの前処理
-	PreprocessBatch(batch *model.AudioBatch) (*model.AudioBatch, error)
+	// PreprocessBatch 音声バッチの前処理（ctx が取り消されている場合は処理しない）
+	PreprocessBatch(ctx context.Context, batch *model.AudioBatch) (*model.AudioBatch, error)
+
+	// RegisterSession セッションが宣言した音声フォーマットを登録
+	RegisterSession(clientID string, format model.AudioFormat) error
+
+	// UnregisterSession セッションの状態を破棄（以降のバッチはエラー）
+	UnregisterSession(clientID string)
+
+	// SetPreprocessingParameters 全セッション共通の前処理設定を検証して適用
+	SetPreprocessingParameters(config model.PreprocessingConfig) error
 
-	// SetPreprocessingParameters 前処理パラメータを設定
-	SetPreprocessingParameters(params map[string]interface{})
+	// SetSessionPreprocessingParameters セッション固有の前処理設定を検証して適用
+	SetSessionPreprocessingParameters(clientID string, config model.PreprocessingConfig) error
 }
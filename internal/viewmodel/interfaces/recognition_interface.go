@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+
+	"socket_inference/internal/model"
+)
+
+// Recognizer 録音ファイルの一括推論インターフェース
+type Recognizer interface {
+	// Recognize 音声チャンク列を合成セッションで推論し、結果をまとめて返す
+	Recognize(ctx context.Context, chunks [][]byte, format model.AudioFormat) (*model.RecognitionResult, error)
+}
+
+// RecognitionJobManager 非同期ファイル推論ジョブ管理のインターフェース
+type RecognitionJobManager interface {
+	// Submit 推論ジョブを登録してバックグラウンドで実行
+	// 実行待ちのジョブが上限に達している場合は model.ErrRecognitionQueueFull を返す
+	Submit(chunks [][]byte, format model.AudioFormat) (*model.RecognitionJob, error)
+
+	// GetJob ジョブの現在の状態を取得
+	GetJob(jobID string) (*model.RecognitionJob, bool)
+
+	// Shutdown 実行中のジョブを中断して停止
+	Shutdown()
+}
@@ -0,0 +1,24 @@
+package interfaces
+
+import "socket_inference/internal/model"
+
+// ResultBroker セッション毎の推論結果の保持と購読者への配信インターフェース
+type ResultBroker interface {
+	// Open セッションの接続を登録し、推論結果の保持を開始（ownerID のクライアントのみが購読できる）
+	Open(sessionID, ownerID string)
+
+	// Close セッションの切断を登録（保持期間の間は再送用に推論結果を保持）
+	Close(sessionID string)
+
+	// Publish 推論結果をセッションのバッファに追加し、購読者に配信
+	Publish(response *model.InferenceResponse) model.ResultEvent
+
+	// Subscribe セッションの推論結果を購読
+	// afterID より後のバッファ済みイベントと、以降のイベントを受信するチャネルを返す
+	// チャネルは購読解除時、または購読者の受信が追いつかない場合に閉じられる
+	// セッションが存在しない場合は model.ErrSessionNotFound、所有者以外は model.ErrSessionForbidden を返す
+	Subscribe(sessionID, subscriberID string, afterID uint64) (replay []model.ResultEvent, events <-chan model.ResultEvent, unsubscribe func(), err error)
+
+	// Shutdown 全ての購読を終了
+	Shutdown()
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import "socket_inference/internal/model"
+
+// SpeechEndpointer 受信した音声チャンクから発話の終端を検出するインターフェース
+// AudioBatcherがチャンク数の代わりに発話単位でバッチを区切るために使用する
+type SpeechEndpointer interface {
+	// RegisterSession セッションが宣言した音声フォーマットを登録
+	RegisterSession(clientID string, format model.AudioFormat) error
+
+	// UnregisterSession セッションの判定状態を破棄
+	UnregisterSession(clientID string)
+
+	// ObserveChunk チャンクを判定し、発話の終端を含む場合はendOfSpeech=trueを返す
+	// 登録されていないセッションはtracked=falseを返す
+	ObserveChunk(clientID string, chunk []byte) (endOfSpeech bool, tracked bool)
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import "socket_inference/internal/model"
+
+// TaskHandler 推論タスク毎のリクエスト・レスポンスの対応付けのインターフェース
+// InferenceManagerがセッションの宣言したタスクに応じて選択する
+type TaskHandler interface {
+	// Task 対応する推論タスク
+	Task() model.TaskType
+
+	// BuildRequest 前処理済みのバッチから推論リクエストを作成
+	BuildRequest(batch *model.AudioBatch) *model.InferenceRequest
+
+	// HandleResponse 推論サーバーのレスポンスを検証・整形し、クライアントに通知するセッションイベントを返す
+	// レスポンスの時刻はセッション先頭からの位置に変換済み
+	HandleResponse(response *model.InferenceResponse) ([]model.SessionEvent, error)
+}
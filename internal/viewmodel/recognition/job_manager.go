@@ -0,0 +1,189 @@
+package recognition
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"socket_inference/internal/model"
+	"socket_inference/internal/viewmodel/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// JobOptions 非同期ファイル推論ジョブの実行設定
+type JobOptions struct {
+	Workers   int           // 同時に実行するジョブ数
+	QueueSize int           // 実行枠を待てるジョブ数の上限（超えると model.ErrRecognitionQueueFull）
+	Timeout   time.Duration // ジョブ毎の推論タイムアウト
+	Retention time.Duration // 完了ジョブの保持期間
+}
+
+// DefaultJobOptions ジョブの実行設定のデフォルト値
+func DefaultJobOptions() JobOptions {
+	return JobOptions{
+		Workers:   4,
+		QueueSize: 64,
+		Timeout:   5 * time.Minute,
+		Retention: time.Hour,
+	}
+}
+
+// JobManager 非同期ファイル推論ジョブ管理の実装
+type JobManager struct {
+	mu         sync.RWMutex
+	recognizer interfaces.Recognizer
+	jobs       map[string]*model.RecognitionJob
+	queued     int           // 実行枠を待っているジョブ数
+	maxQueued  int           // 実行枠を待てるジョブ数の上限（音声データを保持したまま待つため制限する）
+	timeout    time.Duration // ジョブ毎の推論タイムアウト
+	retention  time.Duration // 完了ジョブの保持期間
+	slots      chan struct{} // 同時実行数を制限するセマフォ
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// NewJobManager 新しいジョブマネージャーを作成
+// options.Workers 件まで同時に実行し、さらに options.QueueSize 件まで実行枠を待たせる
+// 0以下の設定値はデフォルト値を使用する
+func NewJobManager(recognizer interfaces.Recognizer, options JobOptions) interfaces.RecognitionJobManager {
+	defaults := DefaultJobOptions()
+	if options.Workers <= 0 {
+		options.Workers = defaults.Workers
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaults.QueueSize
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaults.Timeout
+	}
+	if options.Retention <= 0 {
+		options.Retention = defaults.Retention
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jm := &JobManager{
+		recognizer: recognizer,
+		jobs:       make(map[string]*model.RecognitionJob),
+		maxQueued:  options.QueueSize,
+		timeout:    options.Timeout,
+		retention:  options.Retention,
+		slots:      make(chan struct{}, options.Workers),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	go jm.cleanupLoop()
+	return jm
+}
+
+// Submit 推論ジョブを登録してバックグラウンドで実行
+// 実行枠を待っているジョブが上限に達している場合は model.ErrRecognitionQueueFull を返す
+func (jm *JobManager) Submit(chunks [][]byte, format model.AudioFormat) (*model.RecognitionJob, error) {
+	job := &model.RecognitionJob{
+		ID:        uuid.New().String(),
+		Status:    model.RecognitionJobPending,
+		CreatedAt: time.Now(),
+	}
+
+	jm.mu.Lock()
+	if jm.queued >= jm.maxQueued {
+		jm.mu.Unlock()
+		return nil, fmt.Errorf("%w (%d 件)", model.ErrRecognitionQueueFull, jm.maxQueued)
+	}
+	jm.queued++
+	jm.jobs[job.ID] = job
+	snapshot := *job
+	jm.mu.Unlock()
+
+	go jm.run(job.ID, chunks, format)
+	log.Printf("ファイル推論ジョブを登録: %s (%d チャンク)", job.ID, len(chunks))
+
+	return &snapshot, nil
+}
+
+// GetJob ジョブの現在の状態を取得
+func (jm *JobManager) GetJob(jobID string) (*model.RecognitionJob, bool) {
+	jm.mu.RLock()
+	defer jm.mu.RUnlock()
+
+	job, ok := jm.jobs[jobID]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *job
+	return &snapshot, true
+}
+
+// run 実行枠を確保してジョブを実行
+func (jm *JobManager) run(jobID string, chunks [][]byte, format model.AudioFormat) {
+	select {
+	case jm.slots <- struct{}{}:
+		defer func() { <-jm.slots }()
+	case <-jm.ctx.Done():
+		jm.mu.Lock()
+		jm.queued--
+		jm.mu.Unlock()
+		jm.finish(jobID, nil, jm.ctx.Err())
+		return
+	}
+
+	jm.mu.Lock()
+	jm.queued--
+	jm.jobs[jobID].Status = model.RecognitionJobRunning
+	jm.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(jm.ctx, jm.timeout)
+	defer cancel()
+
+	result, err := jm.recognizer.Recognize(ctx, chunks, format)
+	jm.finish(jobID, result, err)
+}
+
+// finish ジョブの完了状態を記録
+func (jm *JobManager) finish(jobID string, result *model.RecognitionResult, err error) {
+	jm.mu.Lock()
+	defer jm.mu.Unlock()
+
+	job := jm.jobs[jobID]
+	now := time.Now()
+	job.CompletedAt = &now
+	job.Result = result
+	if err != nil {
+		job.Status = model.RecognitionJobFailed
+		job.Error = err.Error()
+		log.Printf("ファイル推論ジョブ失敗: %s: %v", jobID, err)
+		return
+	}
+	job.Status = model.RecognitionJobCompleted
+	log.Printf("ファイル推論ジョブ完了: %s", jobID)
+}
+
+// cleanupLoop 保持期間を過ぎた完了ジョブを定期的に削除
+func (jm *JobManager) cleanupLoop() {
+	ticker := time.NewTicker(jm.retention / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			jm.mu.Lock()
+			for id, job := range jm.jobs {
+				if job.CompletedAt != nil && time.Since(*job.CompletedAt) > jm.retention {
+					delete(jm.jobs, id)
+				}
+			}
+			jm.mu.Unlock()
+		case <-jm.ctx.Done():
+			return
+		}
+	}
+}
+
+// Shutdown 実行中のジョブを中断して停止
+func (jm *JobManager) Shutdown() {
+	jm.cancel()
+	log.Println("ファイル推論ジョブマネージャーを停止しました")
+}
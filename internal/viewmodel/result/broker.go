@@ -0,0 +1,196 @@
+package result
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"socket_inference/internal/model"
+	"socket_inference/internal/viewmodel/interfaces"
+)
+
+// subscriberBuffer 購読者毎の未受信イベントの上限
+const subscriberBuffer = 64
+
+// Broker セッション毎の推論結果バッファと購読者への配信の実装
+type Broker struct {
+	mu         sync.Mutex
+	sessions   map[string]*session
+	bufferSize int           // セッション毎に保持する推論結果の件数
+	retention  time.Duration // 購読者のいないセッションのバッファ保持期間
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// session セッション毎のリングバッファと購読者
+type session struct {
+	ownerID     string // セッションを開いたクライアントの識別ID（空の場合は匿名）
+	open        bool   // 接続中（接続中のセッションは保持期間を過ぎても削除しない）
+	events      []model.ResultEvent
+	nextID      uint64
+	subscribers map[chan model.ResultEvent]struct{}
+	lastActive  time.Time
+}
+
+// NewBroker 新しい推論結果ブローカーを作成
+func NewBroker(bufferSize int, retention time.Duration) interfaces.ResultBroker {
+	if bufferSize <= 0 {
+		bufferSize = 100
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Broker{
+		sessions:   make(map[string]*session),
+		bufferSize: bufferSize,
+		retention:  retention,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	go b.cleanupLoop()
+	return b
+}
+
+// Open セッションの接続を登録し、推論結果の保持を開始
+// ownerID のクライアントのみが購読できる（空の場合はセッションIDを知っていれば購読できる）
+func (b *Broker) Open(sessionID, ownerID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.sessions[sessionID]
+	if !ok {
+		s = &session{
+			ownerID:     ownerID,
+			subscribers: make(map[chan model.ResultEvent]struct{}),
+		}
+		b.sessions[sessionID] = s
+	}
+	s.open = true
+	s.lastActive = time.Now()
+}
+
+// Close セッションの切断を登録（保持期間の間は再送用に推論結果を保持）
+func (b *Broker) Close(sessionID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if s, ok := b.sessions[sessionID]; ok {
+		s.open = false
+		s.lastActive = time.Now()
+	}
+}
+
+// Publish 推論結果をセッションのバッファに追加し、購読者に配信
+// 開かれていない（または保持期間を過ぎて削除された）セッションの推論結果は保持しない
+func (b *Broker) Publish(response *model.InferenceResponse) model.ResultEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.sessions[response.ClientID]
+	if !ok {
+		return model.ResultEvent{Response: response}
+	}
+	s.nextID++
+	event := model.ResultEvent{ID: s.nextID, Response: response}
+
+	s.events = append(s.events, event)
+	if len(s.events) > b.bufferSize {
+		s.events = s.events[len(s.events)-b.bufferSize:]
+	}
+	s.lastActive = time.Now()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 受信が追いつかない購読者は切断し、Last-Event-IDでの再接続に任せる
+			log.Printf("購読者の受信が追いつかないため切断: セッション=%s", response.ClientID)
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return event
+}
+
+// Subscribe セッションの推論結果を購読
+// セッションが存在しない場合は model.ErrSessionNotFound、
+// subscriberID がセッションを開いたクライアントと異なる場合は model.ErrSessionForbidden を返す
+func (b *Broker) Subscribe(sessionID, subscriberID string, afterID uint64) ([]model.ResultEvent, <-chan model.ResultEvent, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.sessions[sessionID]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("%w: %s", model.ErrSessionNotFound, sessionID)
+	}
+	if s.ownerID != "" && s.ownerID != subscriberID {
+		return nil, nil, nil, fmt.Errorf("%w: %s", model.ErrSessionForbidden, sessionID)
+	}
+	s.lastActive = time.Now()
+
+	var replay []model.ResultEvent
+	for _, event := range s.events {
+		if event.ID > afterID {
+			replay = append(replay, event)
+		}
+	}
+
+	ch := make(chan model.ResultEvent, subscriberBuffer)
+	s.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if _, ok := s.subscribers[ch]; ok {
+				delete(s.subscribers, ch)
+				close(ch)
+			}
+			s.lastActive = time.Now()
+		})
+	}
+
+	return replay, ch, unsubscribe, nil
+}
+
+// cleanupLoop 切断済みで購読者がおらず、保持期間を過ぎたセッションを定期的に削除
+func (b *Broker) cleanupLoop() {
+	ticker := time.NewTicker(b.retention / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.mu.Lock()
+			for id, s := range b.sessions {
+				if !s.open && len(s.subscribers) == 0 && time.Since(s.lastActive) > b.retention {
+					delete(b.sessions, id)
+				}
+			}
+			b.mu.Unlock()
+		case <-b.ctx.Done():
+			return
+		}
+	}
+}
+
+// Shutdown 全ての購読を終了
+func (b *Broker) Shutdown() {
+	b.cancel()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range b.sessions {
+		for ch := range s.subscribers {
+			close(ch)
+		}
+		s.subscribers = make(map[chan model.ResultEvent]struct{})
+	}
+	log.Println("推論結果ブローカーを停止しました")
+}
@@ -0,0 +1,78 @@
+package result
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"socket_inference/internal/model"
+)
+
+func TestBrokerSubscribe(t *testing.T) {
+	tests := []struct {
+		name         string
+		ownerID      string
+		open         bool
+		subscriberID string
+		wantErr      error
+	}{
+		{name: "セッションを開いたクライアント", ownerID: "client-a", open: true, subscriberID: "client-a"},
+		{name: "他のクライアント", ownerID: "client-a", open: true, subscriberID: "client-b", wantErr: model.ErrSessionForbidden},
+		{name: "識別IDのない購読者", ownerID: "client-a", open: true, subscriberID: "", wantErr: model.ErrSessionForbidden},
+		{name: "匿名のセッション", ownerID: "", open: true, subscriberID: "client-b"},
+		{name: "開かれていないセッション", subscriberID: "client-a", wantErr: model.ErrSessionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := NewBroker(10, time.Minute)
+			defer broker.Shutdown()
+
+			if tt.open {
+				broker.Open("session", tt.ownerID)
+			}
+			broker.Publish(&model.InferenceResponse{ClientID: "session", Result: "1"})
+			broker.Publish(&model.InferenceResponse{ClientID: "session", Result: "2"})
+
+			replay, _, unsubscribe, err := broker.Subscribe("session", tt.subscriberID, 0)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("エラー = %v（期待値 %v）", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer unsubscribe()
+			if len(replay) != 2 || replay[0].ID != 1 || replay[1].ID != 2 {
+				t.Fatalf("再送 = %+v（ID 1, 2 を期待）", replay)
+			}
+		})
+	}
+}
+
+func TestBrokerSubscribeDoesNotCreateSession(t *testing.T) {
+	broker := NewBroker(10, time.Minute)
+	defer broker.Shutdown()
+
+	// 購読の試行でセッションが作られないこと（作られるとイベントIDの採番がやり直しになる）
+	if _, _, _, err := broker.Subscribe("session", "", 0); !errors.Is(err, model.ErrSessionNotFound) {
+		t.Fatalf("エラー = %v（期待値 %v）", err, model.ErrSessionNotFound)
+	}
+	if _, _, _, err := broker.Subscribe("session", "", 0); !errors.Is(err, model.ErrSessionNotFound) {
+		t.Fatalf("2回目の購読のエラー = %v（期待値 %v）", err, model.ErrSessionNotFound)
+	}
+
+	broker.Open("session", "")
+	broker.Publish(&model.InferenceResponse{ClientID: "session"})
+	broker.Close("session")
+	broker.Publish(&model.InferenceResponse{ClientID: "session"})
+
+	// 切断後も保持期間の間は再送でき、採番が続くこと
+	replay, _, unsubscribe, err := broker.Subscribe("session", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+	if len(replay) != 1 || replay[0].ID != 2 {
+		t.Fatalf("再送 = %+v（ID 2 を期待）", replay)
+	}
+}
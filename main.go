@@ -1,29 +1,175 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
+	"socket_inference/internal/config"
 	"socket_inference/internal/infrastructure/grpc"
+	httpbackend "socket_inference/internal/infrastructure/http"
+	"socket_inference/internal/infrastructure/interfaces"
+	"socket_inference/internal/infrastructure/registry"
+	"socket_inference/internal/infrastructure/resilience"
+	"socket_inference/internal/infrastructure/rollout"
+	wsbackend "socket_inference/internal/infrastructure/websocket"
+	"socket_inference/internal/model"
+	grpchandler "socket_inference/internal/view/handlers/grpc"
+	"socket_inference/internal/view/handlers/rest"
 	"socket_inference/internal/view/handlers/websocket"
 	"socket_inference/internal/view/server"
 	"socket_inference/internal/viewmodel/coordinator"
+	"socket_inference/internal/viewmodel/inference"
+	"socket_inference/internal/viewmodel/recognition"
 )
 
 func main() {
+	cfg := config.LoadServerConfig()
+
 	// Infrastructure層の実装を作成
-	grpcClient := grpc.NewInferenceClient("localhost:50051", 30*time.Second)
+	grpcTLS := grpc.TLSOptions{
+		Enabled:    cfg.GRPCTLSEnabled,
+		CAFile:     cfg.GRPCTLSCAFile,
+		CertFile:   cfg.GRPCTLSCertFile,
+		KeyFile:    cfg.GRPCTLSKeyFile,
+		ServerName: cfg.GRPCTLSServerName,
+	}
+	endpoints := cfg.InferenceEndpoints()
+	if cfg.GRPCServerFile != "" {
+		loaded, err := resilience.LoadEndpointsFile(cfg.GRPCServerFile)
+		if err != nil {
+			log.Fatalf("推論サーバー一覧の読み込み失敗: %v", err)
+		}
+		endpoints = loaded
+	}
+
+	specs := []model.ModelSpec{cfg.DefaultModelSpec()}
+	if cfg.ModelRegistryFile != "" {
+		loaded, err := config.LoadModelRegistry(cfg.ModelRegistryFile)
+		if err != nil {
+			log.Fatalf("推論モデル一覧の読み込み失敗: %v", err)
+		}
+		specs = loaded
+	}
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+
+	// 推論モデル毎に推論サーバーのクライアントを作成
+	modelClients := make(map[string]interfaces.InferenceClient, len(specs))
+	var hedgingClients resilience.HedgingGroup
+	for _, spec := range specs {
+		inferenceClient, hedgingClient, err := newModelClient(watchCtx, cfg, spec, endpoints, grpcTLS)
+		if err != nil {
+			log.Fatalf("推論モデル %s の設定失敗: %v", spec.ID(), err)
+		}
+		modelClients[spec.ID()] = inferenceClient
+		if hedgingClient != nil {
+			hedgingClients = append(hedgingClients, hedgingClient)
+		}
+	}
+
+	// シャドー設定のあるモデルはバッチの一部を別のバージョンに複製して対応表に登録
+	modelRegistry := registry.NewModelRegistry()
+	var shadowClients rollout.ShadowGroup
+	for _, spec := range specs {
+		inferenceClient := modelClients[spec.ID()]
+		if spec.Shadow != nil {
+			shadowClient, err := newShadowClient(spec, inferenceClient, modelClients)
+			if err != nil {
+				log.Fatalf("推論モデル %s のシャドーの設定失敗: %v", spec.ID(), err)
+			}
+			shadowClients = append(shadowClients, shadowClient)
+			inferenceClient = shadowClient
+		}
+		if err := modelRegistry.Register(spec.ModelInfo, inferenceClient); err != nil {
+			log.Fatalf("推論モデルの登録失敗: %v", err)
+		}
+	}
+	if err := modelRegistry.Connect(context.Background()); err != nil {
+		log.Fatalf("推論サーバー接続失敗: %v", err)
+	}
+	defer modelRegistry.Disconnect()
+
+	// 既定の推論モデルの推論クライアント（モデルを宣言しないセッションが使用）
+	_, inferenceClient, err := modelRegistry.Resolve(model.ModelSelection{})
+	if err != nil {
+		log.Fatalf("既定の推論モデルの解決失敗: %v", err)
+	}
 
 	// ViewModelを作成（Infrastructure実装を注入）
-	audioViewModel := coordinator.NewAudioViewModel(grpcClient)
+	audioViewModel := coordinator.NewAudioViewModel(inferenceClient, inference.ManagerOptions{
+		Workers:     cfg.InferenceWorkers,
+		MaxInFlight: cfg.InferenceMaxInFlight,
+		QueueSize:   cfg.InferenceQueueSize,
+
+		RequestTimeout: cfg.GRPCTimeout,
+	}, recognition.JobOptions{
+		Workers:   cfg.RecognizeJobWorkers,
+		QueueSize: cfg.RecognizeJobQueueSize,
+		Timeout:   cfg.RecognizeJobTimeout,
+		Retention: cfg.RecognizeJobRetention,
+	})
 	defer audioViewModel.Shutdown()
+	audioViewModel.SetModelRegistry(modelRegistry)
+
+	preprocessing := model.DefaultPreprocessingConfig()
+	if cfg.PreprocessingConfigFile != "" {
+		loaded, err := config.LoadPreprocessingConfig(cfg.PreprocessingConfigFile)
+		if err != nil {
+			log.Fatalf("前処理設定の読み込み失敗: %v", err)
+		}
+		if err := audioViewModel.SetPreprocessingConfig(loaded); err != nil {
+			log.Fatalf("前処理設定の適用失敗: %v", err)
+		}
+		preprocessing = loaded
+	}
+	if cfg.VADBatching {
+		if err := audioViewModel.EnableVADBatching(preprocessing.VAD, cfg.VADMaxBatchChunks); err != nil {
+			log.Fatalf("発話単位のバッチ化の設定失敗: %v", err)
+		}
+	}
 
 	// Viewを作成
 	audioHandler := websocket.NewAudioStreamHandler(audioViewModel)
-	httpServer := server.NewServer(audioHandler)
+	httpServer := server.NewServer(audioHandler, cfg)
+
+	recognizeHandler := rest.NewRecognizeHandler(audioViewModel, rest.RecognizeOptions{
+		ChunkBytes: cfg.RecognizeChunkBytes,
+		MaxBytes:   cfg.RecognizeMaxBytes,
+		Timeout:    cfg.RecognizeTimeout,
+	})
+	httpServer.HandleFunc("POST /v1/recognize", recognizeHandler.HandleRecognize)
+	httpServer.HandleFunc("GET /v1/recognize/jobs/{id}", recognizeHandler.HandleGetJob)
+
+	eventsHandler := rest.NewEventsHandler(audioViewModel)
+	httpServer.HandleFunc("GET /v1/sessions/{id}/events", eventsHandler.HandleEvents)
+
+	statsHandler := rest.NewStatsHandler(audioViewModel)
+	if len(hedgingClients) > 0 {
+		statsHandler.SetHedgingStats(hedgingClients)
+	}
+	if len(shadowClients) > 0 {
+		statsHandler.SetShadowStats(shadowClients)
+	}
+	httpServer.HandleFunc("GET /v1/inference/stats", statsHandler.HandleStats)
+
+	modelsHandler := rest.NewModelsHandler(audioViewModel)
+	httpServer.HandleFunc("GET /v1/models", modelsHandler.HandleModels)
+
+	var grpcServer *server.GRPCServer
+	if cfg.GRPCIngressPort != "" {
+		grpcServer = server.NewGRPCServer(grpchandler.NewAudioStreamHandler(audioViewModel), cfg)
+		go func() {
+			if err := grpcServer.Start(":" + cfg.GRPCIngressPort); err != nil {
+				log.Fatalf("gRPCサーバー起動失敗: %v", err)
+			}
+		}()
+	}
 
 	// 正常なシャットダウンのためのシグナルハンドリング
 	stop := make(chan os.Signal, 1)
@@ -31,7 +177,7 @@ func main() {
 
 	// サーバーを別のgoroutineで開始
 	go func() {
-		if err := httpServer.Start(":8080"); err != nil {
+		if err := httpServer.Start(":" + cfg.Port); err != nil {
 			log.Fatalf("サーバー起動失敗: %v", err)
 		}
 	}()
@@ -39,4 +185,110 @@ func main() {
 	// シャットダウンシグナルを待機
 	<-stop
 	log.Println("サーバーを停止中...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("HTTPサーバー停止エラー: %v", err)
+	}
+	if grpcServer != nil {
+		if err := grpcServer.Shutdown(ctx); err != nil {
+			log.Printf("gRPCサーバー停止エラー: %v", err)
+		}
+	}
+}
+
+// newModelClient 推論モデルの推論サーバー毎のクライアント（gRPC / HTTP / WebSocket）を負荷分散・ヘッジ・サーキットブレーカー・リトライでラップ
+// モデルの推論サーバーが指定されていない場合は共通の推論サーバーを使用し、アドレス一覧ファイルの変更を監視する
+// ヘッジが無効の場合、HedgingClientはnil
+func newModelClient(ctx context.Context, cfg *config.ServerConfig, spec model.ModelSpec, endpoints []string, grpcTLS grpc.TLSOptions) (interfaces.InferenceClient, *resilience.HedgingClient, error) {
+	watchFile := len(spec.Endpoints) == 0 && cfg.GRPCServerFile != ""
+	if len(spec.Endpoints) > 0 {
+		endpoints = spec.Endpoints
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, nil, err
+	}
+	factory, err := newBackendFactory(cfg, spec, grpcTLS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 推論サーバーのレプリカ毎にクライアントを作成して負荷分散
+	balancingClient, err := resilience.NewBalancingInferenceClient(endpoints, factory, cfg.LoadBalancingConfig())
+	if err != nil {
+		return nil, nil, fmt.Errorf("負荷分散の設定失敗: %w", err)
+	}
+	if watchFile {
+		balancingClient.WatchEndpointsFile(ctx, cfg.GRPCServerFile, cfg.GRPCServerFileInterval)
+	}
+
+	// 応答が遅いリクエストは複製を別のレプリカに送信
+	var backendClient interfaces.InferenceClient = balancingClient
+	var hedgingClient *resilience.HedgingClient
+	if hedging := cfg.HedgingConfig(); hedging.Enabled {
+		hedgingClient, err = resilience.NewHedgingClient(balancingClient, hedging)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ヘッジの設定失敗: %w", err)
+		}
+		backendClient = hedgingClient
+	}
+
+	// 推論サーバーの障害時は送信を打ち切り、失敗した推論リクエストはリトライポリシーに従って再送
+	breakerClient, err := resilience.NewCircuitBreakerClient(backendClient, cfg.CircuitBreakerConfig())
+	if err != nil {
+		return nil, nil, fmt.Errorf("サーキットブレーカーの設定失敗: %w", err)
+	}
+	inferenceClient, err := resilience.NewRetryingClient(breakerClient, cfg.GetRetryPolicy())
+	if err != nil {
+		return nil, nil, fmt.Errorf("リトライポリシーの設定失敗: %w", err)
+	}
+	return inferenceClient, hedgingClient, nil
+}
+
+// newBackendFactory 推論モデルの通信方式に応じて推論サーバー毎のクライアントを作成する関数を取得
+// HTTPの場合、レスポンスのテンプレートはモデル毎に一度だけ解析して全ての推論サーバーで共有する
+func newBackendFactory(cfg *config.ServerConfig, spec model.ModelSpec, grpcTLS grpc.TLSOptions) (resilience.BackendFactory, error) {
+	switch spec.Backend {
+	case model.BackendHTTP:
+		httpConfig := spec.HTTPConfig()
+		mapping, err := httpbackend.LoadResponseMapping(httpConfig)
+		if err != nil {
+			return nil, fmt.Errorf("推論モデル %s のレスポンスの対応付けの設定失敗: %w", spec.ID(), err)
+		}
+		return func(endpoint string) interfaces.InferenceClient {
+			return httpbackend.NewInferenceClient(endpoint, cfg.GRPCTimeout, httpConfig, mapping)
+		}, nil
+	case model.BackendWebSocket:
+		// 推論サーバー毎に接続プールを持ち、接続を複数のリクエストで共有
+		wsConfig := spec.WebSocketConfig()
+		return func(endpoint string) interfaces.InferenceClient {
+			return wsbackend.NewInferenceClient(endpoint, cfg.GRPCTimeout, wsConfig)
+		}, nil
+	default:
+		return func(address string) interfaces.InferenceClient {
+			return grpc.NewSecureInferenceClient(address, cfg.GRPCTimeout, grpcTLS)
+		}, nil
+	}
+}
+
+// newShadowClient 推論モデルのバッチの一部を、シャドー設定のバージョンの推論クライアントに複製するようラップ
+// 出力の記録ファイルが指定されている場合は追記で開く（プロセス終了まで開いたまま）
+func newShadowClient(spec model.ModelSpec, inferenceClient interfaces.InferenceClient, modelClients map[string]interfaces.InferenceClient) (*rollout.ShadowClient, error) {
+	shadowModel := model.ModelInfo{Name: spec.Name, Version: spec.Shadow.Version}
+	shadowClient, ok := modelClients[shadowModel.ID()]
+	if !ok {
+		return nil, fmt.Errorf("%w: シャドーの推論モデル %s が登録されていません", model.ErrInvalidModelSpec, shadowModel.ID())
+	}
+
+	var writer io.Writer
+	if spec.Shadow.LogFile != "" {
+		file, err := os.OpenFile(spec.Shadow.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("シャドー推論の記録ファイルを開けません: %w", err)
+		}
+		writer = file
+	}
+	return rollout.NewShadowClient(inferenceClient, shadowClient, spec.ID(), shadowModel, *spec.Shadow, writer)
 }